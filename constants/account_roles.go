@@ -0,0 +1,7 @@
+package constants
+
+// Account roles. Account admins manage the workspaces grouped under an account
+// and see consolidated data for all of them.
+const (
+	AccountRoleAdmin = "admin"
+)
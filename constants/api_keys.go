@@ -0,0 +1,59 @@
+package constants
+
+import "strings"
+
+// APIKeyPrefix starts every API key so clients and the auth middleware can tell them apart from JWTs.
+const APIKeyPrefix = "jvk_"
+
+// API key scope access levels. Write covers create, update and delete.
+const (
+	APIKeyAccessRead  = "read"
+	APIKeyAccessWrite = "write"
+)
+
+// apiKeyResources lists the workspace resources an API key can be scoped to.
+// Members and API keys themselves are managed by people only.
+var apiKeyResources = map[string]bool{
+	WorkspaceResourceWorkspace:            true,
+	WorkspaceResourceSettings:             true,
+	WorkspaceResourceRecipes:              true,
+	WorkspaceResourceIngredients:          true,
+	WorkspaceResourceWorkspaceIngredients: true,
+	WorkspaceResourcePrices:               true,
+	WorkspaceResourceProducts:             true,
+	WorkspaceResourcePackages:             true,
+	WorkspaceResourceClients:              true,
+	WorkspaceResourceOrders:               true,
+	WorkspaceResourceDashboard:            true,
+	WorkspaceResourceData:                 true,
+	WorkspaceResourceActivity:             true,
+}
+
+// IsValidAPIKeyScope reports whether scope has the form "resource:read" or "resource:write"
+// for a resource API keys can access.
+func IsValidAPIKeyScope(scope string) bool {
+	resource, access, found := strings.Cut(scope, ":")
+	if !found || !apiKeyResources[resource] {
+		return false
+	}
+	return access == APIKeyAccessRead || access == APIKeyAccessWrite
+}
+
+// APIKeyScope returns the scope an API key needs to perform action on resource.
+func APIKeyScope(resource string, action string) string {
+	if action == WorkspaceActionRead {
+		return resource + ":" + APIKeyAccessRead
+	}
+	return resource + ":" + APIKeyAccessWrite
+}
+
+// APIKeyScopesAllow reports whether scopes include the scope needed to perform action on resource.
+func APIKeyScopesAllow(scopes []string, resource string, action string) bool {
+	required := APIKeyScope(resource, action)
+	for _, scope := range scopes {
+		if scope == required {
+			return true
+		}
+	}
+	return false
+}
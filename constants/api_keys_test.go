@@ -0,0 +1,39 @@
+package constants
+
+import "testing"
+
+func TestIsValidAPIKeyScope(t *testing.T) {
+	for _, scope := range []string{"orders:read", "orders:write", "prices:write", "workspace_ingredients:read"} {
+		if !IsValidAPIKeyScope(scope) {
+			t.Fatalf("expected scope %q to be valid", scope)
+		}
+	}
+
+	for _, scope := range []string{"", "orders", "orders:delete", "members:read", "api_keys:write", "unknown:read"} {
+		if IsValidAPIKeyScope(scope) {
+			t.Fatalf("expected scope %q to be invalid", scope)
+		}
+	}
+}
+
+func TestAPIKeyScopesAllow(t *testing.T) {
+	scopes := []string{"orders:read", "prices:write"}
+
+	tests := []struct {
+		resource string
+		action   string
+		want     bool
+	}{
+		{WorkspaceResourceOrders, WorkspaceActionRead, true},
+		{WorkspaceResourceOrders, WorkspaceActionCreate, false},
+		{WorkspaceResourcePrices, WorkspaceActionCreate, true},
+		{WorkspaceResourcePrices, WorkspaceActionDelete, true},
+		{WorkspaceResourcePrices, WorkspaceActionRead, false},
+		{WorkspaceResourceRecipes, WorkspaceActionRead, false},
+	}
+	for _, tt := range tests {
+		if got := APIKeyScopesAllow(scopes, tt.resource, tt.action); got != tt.want {
+			t.Fatalf("APIKeyScopesAllow(%v, %q, %q) = %t, want %t", scopes, tt.resource, tt.action, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package constants
+
+// Entity types recorded in the workspace activity feed.
+const (
+	AuditEntityOrder               = "order"
+	AuditEntityProduct             = "product"
+	AuditEntityRecipe              = "recipe"
+	AuditEntityRecipeIngredient    = "recipe_ingredient"
+	AuditEntityRecipeSubRecipe     = "recipe_sub_recipe"
+	AuditEntityPrice               = "price"
+	AuditEntityClient              = "client"
+	AuditEntityPackage             = "package"
+	AuditEntityWorkspaceIngredient = "workspace_ingredient"
+)
+
+// Audit actions.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// IsValidAuditAction reports whether action is one of the recorded audit actions.
+func IsValidAuditAction(action string) bool {
+	switch action {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete:
+		return true
+	default:
+		return false
+	}
+}
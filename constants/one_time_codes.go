@@ -0,0 +1,8 @@
+package constants
+
+// One-time code purposes.
+const (
+	OneTimeCodePasswordReset     = "password_reset"
+	OneTimeCodeEmailVerification = "email_verification"
+	OneTimeCodeLoginChallenge    = "login_challenge"
+)
@@ -0,0 +1,20 @@
+package constants
+
+// Cost statuses of recipe lines. A line with any status other than RecipeCostStatusOK is not, or
+// not fully, included in the recipe cost.
+const (
+	RecipeCostStatusOK                = "ok"
+	RecipeCostStatusNoPrice           = "no_price"
+	RecipeCostStatusIncompatibleUnits = "incompatible_units"
+	RecipeCostStatusInvalidQuantity   = "invalid_quantity"
+	// RecipeCostStatusInvalidPrice marks an ingredient line whose price is quoted for a quantity
+	// that is not positive.
+	RecipeCostStatusInvalidPrice = "invalid_price"
+	// RecipeCostStatusNoYield marks a sub-recipe line whose sub-recipe has no usable yield.
+	RecipeCostStatusNoYield = "no_yield"
+	// RecipeCostStatusIncompleteSubRecipe marks a sub-recipe line costed from a sub-recipe that is
+	// itself missing costs.
+	RecipeCostStatusIncompleteSubRecipe = "incomplete_sub_recipe"
+	// RecipeCostStatusUnavailable marks a sub-recipe line whose sub-recipe cannot be loaded.
+	RecipeCostStatusUnavailable = "unavailable"
+)
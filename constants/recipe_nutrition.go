@@ -0,0 +1,13 @@
+package constants
+
+// Reasons a recipe line is missing from, or incomplete in, recipe nutrition.
+const (
+	// NutritionWarningMissingValues marks an ingredient without some of its nutrition values.
+	NutritionWarningMissingValues = "missing_values"
+	// NutritionWarningNotByWeight marks a line in a unit that cannot be converted to grams.
+	NutritionWarningNotByWeight          = "not_by_weight"
+	NutritionWarningInvalidQuantity      = "invalid_quantity"
+	NutritionWarningNoFinishedWeight     = "no_finished_weight"
+	NutritionWarningIncompleteSubRecipe  = "incomplete_sub_recipe"
+	NutritionWarningUnavailableSubRecipe = "unavailable"
+)
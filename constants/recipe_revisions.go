@@ -0,0 +1,16 @@
+package constants
+
+// Changes recorded as recipe revisions.
+const (
+	// RecipeRevisionBaseline captures a recipe that existed before its first recorded change.
+	RecipeRevisionBaseline          = "baseline"
+	RecipeRevisionCreated           = "create"
+	RecipeRevisionUpdated           = "update"
+	RecipeRevisionIngredientAdded   = "ingredient_added"
+	RecipeRevisionIngredientUpdated = "ingredient_updated"
+	RecipeRevisionIngredientRemoved = "ingredient_removed"
+	RecipeRevisionSubRecipeAdded    = "sub_recipe_added"
+	RecipeRevisionSubRecipeRemoved  = "sub_recipe_removed"
+	RecipeRevisionStepsUpdated      = "steps_updated"
+	RecipeRevisionRestored          = "restore"
+)
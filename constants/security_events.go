@@ -0,0 +1,28 @@
+package constants
+
+// Security event types recorded for user accounts.
+const (
+	SecurityEventLoginSucceeded    = "login_succeeded"
+	SecurityEventLoginFailed       = "login_failed"
+	SecurityEventAccountLocked     = "account_locked"
+	SecurityEventPasswordChanged   = "password_changed"
+	SecurityEventPasswordReset     = "password_reset"
+	SecurityEventEmailVerified     = "email_verified"
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+	SecurityEventTwoFactorEnabled  = "two_factor_enabled"
+	SecurityEventTwoFactorDisabled = "two_factor_disabled"
+	SecurityEventRecoveryCodeUsed  = "recovery_code_used"
+	SecurityEventSessionRevoked    = "session_revoked"
+)
+
+// IsValidSecurityEventType reports whether eventType is a recorded security event type.
+func IsValidSecurityEventType(eventType string) bool {
+	switch eventType {
+	case SecurityEventLoginSucceeded, SecurityEventLoginFailed, SecurityEventAccountLocked,
+		SecurityEventPasswordChanged, SecurityEventPasswordReset, SecurityEventEmailVerified, SecurityEventRefreshTokenReuse,
+		SecurityEventTwoFactorEnabled, SecurityEventTwoFactorDisabled, SecurityEventRecoveryCodeUsed, SecurityEventSessionRevoked:
+		return true
+	default:
+		return false
+	}
+}
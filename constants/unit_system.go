@@ -0,0 +1,17 @@
+package constants
+
+// Unit systems used to present recipe and price quantities.
+const (
+	UnitSystemMetric   = "metric"
+	UnitSystemImperial = "imperial"
+)
+
+// IsValidUnitSystem reports whether system is one of the supported unit systems.
+func IsValidUnitSystem(system string) bool {
+	switch system {
+	case UnitSystemMetric, UnitSystemImperial:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,15 @@
+package constants
+
+import "testing"
+
+func TestIsValidUnitSystem(t *testing.T) {
+	for _, system := range []string{UnitSystemMetric, UnitSystemImperial} {
+		if !IsValidUnitSystem(system) {
+			t.Fatalf("expected unit system %q to be valid", system)
+		}
+	}
+
+	if IsValidUnitSystem("nautical") {
+		t.Fatal("unexpected valid unit system")
+	}
+}
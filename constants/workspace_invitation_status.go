@@ -0,0 +1,10 @@
+package constants
+
+// Workspace invitation statuses.
+const (
+	WorkspaceInvitationPending  = "pending"
+	WorkspaceInvitationAccepted = "accepted"
+	WorkspaceInvitationDeclined = "declined"
+	WorkspaceInvitationRevoked  = "revoked"
+	WorkspaceInvitationExpired  = "expired"
+)
@@ -0,0 +1,124 @@
+package constants
+
+// Workspace resources guarded by role permissions.
+const (
+	WorkspaceResourceWorkspace            = "workspace"
+	WorkspaceResourceMembers              = "members"
+	WorkspaceResourceSettings             = "settings"
+	WorkspaceResourceRecipes              = "recipes"
+	WorkspaceResourceIngredients          = "ingredients"
+	WorkspaceResourceWorkspaceIngredients = "workspace_ingredients"
+	WorkspaceResourcePrices               = "prices"
+	WorkspaceResourceProducts             = "products"
+	WorkspaceResourcePackages             = "packages"
+	WorkspaceResourceClients              = "clients"
+	WorkspaceResourceOrders               = "orders"
+	WorkspaceResourceDashboard            = "dashboard"
+	WorkspaceResourceData                 = "data"
+	WorkspaceResourceActivity             = "activity"
+	WorkspaceResourceAPIKeys              = "api_keys"
+)
+
+// Workspace actions.
+const (
+	WorkspaceActionRead   = "read"
+	WorkspaceActionCreate = "create"
+	WorkspaceActionUpdate = "update"
+	WorkspaceActionDelete = "delete"
+)
+
+var (
+	allWorkspaceActions      = []string{WorkspaceActionRead, WorkspaceActionCreate, WorkspaceActionUpdate, WorkspaceActionDelete}
+	readWorkspaceActions     = []string{WorkspaceActionRead}
+	writeWorkspaceActions    = []string{WorkspaceActionRead, WorkspaceActionCreate, WorkspaceActionUpdate}
+	transferWorkspaceActions = []string{WorkspaceActionRead, WorkspaceActionCreate}
+)
+
+// workspacePermissions maps role -> resource -> allowed actions.
+// Owners may do everything; managers run the workspace but cannot archive it;
+// operators handle day-to-day sales and purchasing; viewers are read-only.
+// Bulk export (data:read) and import (data:create) are limited to owners and managers,
+// as is creating and revoking API keys.
+var workspacePermissions = map[string]map[string][]string{
+	WorkspaceRoleOwner: {
+		WorkspaceResourceWorkspace:            allWorkspaceActions,
+		WorkspaceResourceMembers:              allWorkspaceActions,
+		WorkspaceResourceSettings:             writeWorkspaceActions,
+		WorkspaceResourceRecipes:              allWorkspaceActions,
+		WorkspaceResourceIngredients:          allWorkspaceActions,
+		WorkspaceResourceWorkspaceIngredients: allWorkspaceActions,
+		WorkspaceResourcePrices:               allWorkspaceActions,
+		WorkspaceResourceProducts:             allWorkspaceActions,
+		WorkspaceResourcePackages:             allWorkspaceActions,
+		WorkspaceResourceClients:              allWorkspaceActions,
+		WorkspaceResourceOrders:               allWorkspaceActions,
+		WorkspaceResourceDashboard:            readWorkspaceActions,
+		WorkspaceResourceData:                 transferWorkspaceActions,
+		WorkspaceResourceActivity:             readWorkspaceActions,
+		WorkspaceResourceAPIKeys:              allWorkspaceActions,
+	},
+	WorkspaceRoleManager: {
+		WorkspaceResourceWorkspace:            writeWorkspaceActions,
+		WorkspaceResourceMembers:              allWorkspaceActions,
+		WorkspaceResourceSettings:             writeWorkspaceActions,
+		WorkspaceResourceRecipes:              allWorkspaceActions,
+		WorkspaceResourceIngredients:          allWorkspaceActions,
+		WorkspaceResourceWorkspaceIngredients: allWorkspaceActions,
+		WorkspaceResourcePrices:               allWorkspaceActions,
+		WorkspaceResourceProducts:             allWorkspaceActions,
+		WorkspaceResourcePackages:             allWorkspaceActions,
+		WorkspaceResourceClients:              allWorkspaceActions,
+		WorkspaceResourceOrders:               allWorkspaceActions,
+		WorkspaceResourceDashboard:            readWorkspaceActions,
+		WorkspaceResourceData:                 transferWorkspaceActions,
+		WorkspaceResourceActivity:             readWorkspaceActions,
+		WorkspaceResourceAPIKeys:              allWorkspaceActions,
+	},
+	WorkspaceRoleOperator: {
+		WorkspaceResourceWorkspace:            readWorkspaceActions,
+		WorkspaceResourceMembers:              readWorkspaceActions,
+		WorkspaceResourceSettings:             readWorkspaceActions,
+		WorkspaceResourceRecipes:              readWorkspaceActions,
+		WorkspaceResourceIngredients:          readWorkspaceActions,
+		WorkspaceResourceWorkspaceIngredients: readWorkspaceActions,
+		WorkspaceResourcePrices:               writeWorkspaceActions,
+		WorkspaceResourceProducts:             readWorkspaceActions,
+		WorkspaceResourcePackages:             readWorkspaceActions,
+		WorkspaceResourceClients:              writeWorkspaceActions,
+		WorkspaceResourceOrders:               writeWorkspaceActions,
+		WorkspaceResourceDashboard:            readWorkspaceActions,
+		WorkspaceResourceActivity:             readWorkspaceActions,
+		WorkspaceResourceAPIKeys:              readWorkspaceActions,
+	},
+	WorkspaceRoleViewer: {
+		WorkspaceResourceWorkspace:            readWorkspaceActions,
+		WorkspaceResourceMembers:              readWorkspaceActions,
+		WorkspaceResourceSettings:             readWorkspaceActions,
+		WorkspaceResourceRecipes:              readWorkspaceActions,
+		WorkspaceResourceIngredients:          readWorkspaceActions,
+		WorkspaceResourceWorkspaceIngredients: readWorkspaceActions,
+		WorkspaceResourcePrices:               readWorkspaceActions,
+		WorkspaceResourceProducts:             readWorkspaceActions,
+		WorkspaceResourcePackages:             readWorkspaceActions,
+		WorkspaceResourceClients:              readWorkspaceActions,
+		WorkspaceResourceOrders:               readWorkspaceActions,
+		WorkspaceResourceDashboard:            readWorkspaceActions,
+		WorkspaceResourceActivity:             readWorkspaceActions,
+		WorkspaceResourceAPIKeys:              readWorkspaceActions,
+	},
+}
+
+// WorkspaceRoleAllows reports whether role may perform action on resource.
+func WorkspaceRoleAllows(role string, resource string, action string) bool {
+	for _, allowed := range workspacePermissions[role][resource] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkspacePermission returns the "resource:action" name of a permission.
+func WorkspacePermission(resource string, action string) string {
+	return resource + ":" + action
+}
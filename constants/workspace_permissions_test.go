@@ -0,0 +1,48 @@
+package constants
+
+import "testing"
+
+func TestWorkspaceRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     string
+		resource string
+		action   string
+		want     bool
+	}{
+		{WorkspaceRoleOwner, WorkspaceResourceWorkspace, WorkspaceActionDelete, true},
+		{WorkspaceRoleManager, WorkspaceResourceWorkspace, WorkspaceActionUpdate, true},
+		{WorkspaceRoleManager, WorkspaceResourceWorkspace, WorkspaceActionDelete, false},
+		{WorkspaceRoleManager, WorkspaceResourceProducts, WorkspaceActionDelete, true},
+		{WorkspaceRoleOperator, WorkspaceResourceOrders, WorkspaceActionUpdate, true},
+		{WorkspaceRoleOperator, WorkspaceResourceOrders, WorkspaceActionDelete, false},
+		{WorkspaceRoleOperator, WorkspaceResourceRecipes, WorkspaceActionCreate, false},
+		{WorkspaceRoleOperator, WorkspaceResourceMembers, WorkspaceActionCreate, false},
+		{WorkspaceRoleViewer, WorkspaceResourceDashboard, WorkspaceActionRead, true},
+		{WorkspaceRoleViewer, WorkspaceResourcePrices, WorkspaceActionCreate, false},
+		{WorkspaceRoleManager, WorkspaceResourceData, WorkspaceActionCreate, true},
+		{WorkspaceRoleOperator, WorkspaceResourceData, WorkspaceActionRead, false},
+		{WorkspaceRoleViewer, WorkspaceResourceData, WorkspaceActionRead, false},
+		{"admin", WorkspaceResourceRecipes, WorkspaceActionRead, false},
+		{WorkspaceRoleOwner, "unknown", WorkspaceActionRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := WorkspaceRoleAllows(tt.role, tt.resource, tt.action); got != tt.want {
+			t.Fatalf("WorkspaceRoleAllows(%q, %q, %q) = %t, want %t", tt.role, tt.resource, tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestEveryWorkspaceRoleCanReadAllResources(t *testing.T) {
+	for _, role := range []string{WorkspaceRoleOwner, WorkspaceRoleManager, WorkspaceRoleOperator, WorkspaceRoleViewer} {
+		for resource := range workspacePermissions[WorkspaceRoleOwner] {
+			if resource == WorkspaceResourceData {
+				// Bulk export is intentionally restricted to owners and managers.
+				continue
+			}
+			if !WorkspaceRoleAllows(role, resource, WorkspaceActionRead) {
+				t.Fatalf("role %q cannot read %q", role, resource)
+			}
+		}
+	}
+}
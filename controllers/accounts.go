@@ -0,0 +1,593 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AccountResponse represents an account available to the authenticated user.
+type AccountResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// AccountWorkspaceResponse represents a workspace grouped under an account.
+type AccountWorkspaceResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	MemberCount int64  `json:"member_count"`
+}
+
+// AccountMemberWorkspace represents a user's role in one account workspace.
+type AccountMemberWorkspace struct {
+	WorkspaceID   uint   `json:"workspace_id"`
+	WorkspaceName string `json:"workspace_name"`
+	Role          string `json:"role"`
+}
+
+// AccountMemberResponse represents a user with access to an account or any of its workspaces.
+type AccountMemberResponse struct {
+	UserID      uint                     `json:"user_id"`
+	Username    string                   `json:"username"`
+	AccountRole string                   `json:"account_role,omitempty"`
+	Workspaces  []AccountMemberWorkspace `json:"workspaces"`
+}
+
+// AccountWorkspaceProfit is the profit of one workspace in the consolidated dashboard.
+type AccountWorkspaceProfit struct {
+	WorkspaceID   uint   `json:"workspace_id"`
+	WorkspaceName string `json:"workspace_name"`
+	ProfitData
+}
+
+// AccountProfitTotals sums the profit of the account workspaces that report in one currency.
+type AccountProfitTotals struct {
+	Currency      string   `json:"currency"`
+	TotalRevenue  float64  `json:"total_revenue"`
+	TotalCosts    float64  `json:"total_costs"`
+	TotalProfit   float64  `json:"total_profit"`
+	OrderCount    int64    `json:"order_count"`
+	MarginPercent *float64 `json:"margin_percent"`
+}
+
+// AccountDashboardResponse aggregates profit across all workspaces of an account, with one total
+// per workspace currency.
+type AccountDashboardResponse struct {
+	AccountID  uint                     `json:"account_id"`
+	Totals     []AccountProfitTotals    `json:"totals"`
+	Workspaces []AccountWorkspaceProfit `json:"workspaces"`
+}
+
+// GetAccounts returns accounts administered by the authenticated user.
+// @Summary Get accounts
+// @Description Get accounts where the authenticated user is an account admin.
+// @Tags Accounts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} AccountResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts [get]
+func GetAccounts(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var memberships []models.AccountMember
+	if err := database.DB.
+		Joins("JOIN accounts ON accounts.id = account_members.account_id AND accounts.deleted_at IS NULL").
+		Preload("Account").
+		Where("account_members.user_id = ?", userID).
+		Order("account_members.account_id ASC").
+		Find(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch accounts"})
+		return
+	}
+
+	response := make([]AccountResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		response = append(response, accountResponse(membership))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAccount returns a single account.
+// @Summary Get account
+// @Description Get an account administered by the authenticated user.
+// @Tags Accounts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} AccountResponse
+// @Failure 400 {object} map[string]string "Invalid account ID"
+// @Failure 403 {object} map[string]string "Account access denied"
+// @Router /api/accounts/{id} [get]
+func GetAccount(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, accountResponse(member))
+}
+
+// CreateAccount creates an account.
+// @Summary Create account
+// @Description Create an account that groups workspaces of one legal business. The authenticated user becomes its admin.
+// @Tags Accounts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param account body models.AccountCreateDTO true "Account data"
+// @Success 201 {object} AccountResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts [post]
+func CreateAccount(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var requestData models.AccountCreateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(requestData.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account name is required"})
+		return
+	}
+
+	account, err := database.CreateAccount(database.DB, userID, requestData.Name)
+	if err != nil {
+		log.Printf("Failed to create account for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AccountResponse{ID: account.ID, Name: account.Name, Role: constants.AccountRoleAdmin})
+}
+
+// GetAccountWorkspaces lists workspaces grouped under an account.
+// @Summary Get account workspaces
+// @Description List workspaces grouped under an account with their member counts. Account admins see every workspace, including ones they are not a member of.
+// @Tags Accounts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {array} AccountWorkspaceResponse
+// @Failure 400 {object} map[string]string "Invalid account ID"
+// @Failure 403 {object} map[string]string "Account access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts/{id}/workspaces [get]
+func GetAccountWorkspaces(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+
+	var workspaces []AccountWorkspaceResponse
+	if err := database.DB.Model(&models.Workspace{}).
+		Select(`workspaces.id, workspaces.name, workspaces.slug,
+			(SELECT COUNT(*) FROM workspace_members WHERE workspace_members.workspace_id = workspaces.id AND workspace_members.deleted_at IS NULL) as member_count`).
+		Where("workspaces.account_id = ?", member.AccountID).
+		Order("workspaces.id ASC").
+		Scan(&workspaces).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch account workspaces"})
+		return
+	}
+	if workspaces == nil {
+		workspaces = []AccountWorkspaceResponse{}
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// AddAccountWorkspace groups a workspace under an account.
+// @Summary Add workspace to account
+// @Description Group a shared workspace under an account. Requires account admin access and the owner role in the workspace. Personal workspaces cannot be grouped.
+// @Tags Accounts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param workspace body models.AccountWorkspaceDTO true "Workspace to add"
+// @Success 200 {object} AccountWorkspaceResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Account or workspace access denied"
+// @Failure 409 {object} map[string]string "Workspace already belongs to another account"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts/{id}/workspaces [post]
+func AddAccountWorkspace(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+
+	var requestData models.AccountWorkspaceDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspaceMember, found, err := database.FindWorkspaceMember(database.DB, member.UserID, requestData.WorkspaceID)
+	if err != nil {
+		log.Printf("Failed to resolve workspace %d for user %d: %v", requestData.WorkspaceID, member.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve workspace"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Workspace access denied"})
+		return
+	}
+	if workspaceMember.Role != constants.WorkspaceRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":  "Only workspace owners can add a workspace to an account",
+			"reason": middleware.PermissionReasonOwnerRole,
+			"role":   workspaceMember.Role,
+		})
+		return
+	}
+
+	workspace, err := database.AttachWorkspaceToAccount(database.DB, member.AccountID, requestData.WorkspaceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrPersonalWorkspaceAccount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Personal workspaces cannot belong to an account"})
+		case errors.Is(err, database.ErrWorkspaceInOtherAccount):
+			c.JSON(http.StatusConflict, gin.H{"error": "Workspace already belongs to another account"})
+		default:
+			log.Printf("Failed to add workspace %d to account %d: %v", requestData.WorkspaceID, member.AccountID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add workspace to account"})
+		}
+		return
+	}
+
+	var memberCount int64
+	if err := database.DB.Model(&models.WorkspaceMember{}).Where("workspace_id = ?", workspace.ID).Count(&memberCount).Error; err != nil {
+		log.Printf("Failed to count members of workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add workspace to account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountWorkspaceResponse{ID: workspace.ID, Name: workspace.Name, Slug: workspace.Slug, MemberCount: memberCount})
+}
+
+// DeleteAccountWorkspace removes a workspace from an account.
+// @Summary Remove workspace from account
+// @Description Remove a workspace from an account. Workspace data and members are not changed. Requires account admin access.
+// @Tags Accounts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param workspace_id path int true "Workspace ID"
+// @Success 200 {object} map[string]string "Workspace removed from account"
+// @Failure 400 {object} map[string]string "Invalid ID"
+// @Failure 403 {object} map[string]string "Account access denied"
+// @Failure 404 {object} map[string]string "Workspace does not belong to the account"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts/{id}/workspaces/{workspace_id} [delete]
+func DeleteAccountWorkspace(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+	workspaceID, err := strconv.ParseUint(c.Param("workspace_id"), 10, 32)
+	if err != nil || workspaceID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	if err := database.DetachWorkspaceFromAccount(database.DB, member.AccountID, uint(workspaceID)); err != nil {
+		if errors.Is(err, database.ErrWorkspaceNotInAccount) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace does not belong to the account"})
+			return
+		}
+		log.Printf("Failed to remove workspace %d from account %d: %v", workspaceID, member.AccountID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove workspace from account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace removed from account"})
+}
+
+// GetAccountMembers lists account admins and members of all account workspaces.
+// @Summary Get account members
+// @Description List account admins and every member of the account workspaces with their workspace roles.
+// @Tags Accounts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {array} AccountMemberResponse
+// @Failure 400 {object} map[string]string "Invalid account ID"
+// @Failure 403 {object} map[string]string "Account access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts/{id}/members [get]
+func GetAccountMembers(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+
+	var admins []models.AccountMember
+	if err := database.DB.Preload("User").
+		Where("account_id = ?", member.AccountID).
+		Order("created_at ASC, id ASC").
+		Find(&admins).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch account members"})
+		return
+	}
+
+	var workspaceMembers []models.WorkspaceMember
+	if err := database.DB.
+		Joins("JOIN workspaces ON workspaces.id = workspace_members.workspace_id AND workspaces.deleted_at IS NULL").
+		Preload("User").
+		Preload("Workspace").
+		Where("workspaces.account_id = ?", member.AccountID).
+		Order("workspace_members.user_id ASC, workspace_members.workspace_id ASC").
+		Find(&workspaceMembers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch account members"})
+		return
+	}
+
+	response := make([]AccountMemberResponse, 0, len(admins)+len(workspaceMembers))
+	positions := make(map[uint]int)
+	entry := func(user models.User) *AccountMemberResponse {
+		if position, exists := positions[user.ID]; exists {
+			return &response[position]
+		}
+		positions[user.ID] = len(response)
+		response = append(response, AccountMemberResponse{UserID: user.ID, Username: user.Username, Workspaces: []AccountMemberWorkspace{}})
+		return &response[len(response)-1]
+	}
+	for _, admin := range admins {
+		entry(admin.User).AccountRole = admin.Role
+	}
+	for _, workspaceMember := range workspaceMembers {
+		memberEntry := entry(workspaceMember.User)
+		memberEntry.Workspaces = append(memberEntry.Workspaces, AccountMemberWorkspace{
+			WorkspaceID:   workspaceMember.WorkspaceID,
+			WorkspaceName: workspaceMember.Workspace.Name,
+			Role:          workspaceMember.Role,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddAccountAdmin grants account admin access to a user.
+// @Summary Add account admin
+// @Description Grant account admin access to an existing user by username. Requires account admin access.
+// @Tags Accounts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param admin body models.AccountAdminDTO true "User to grant access to"
+// @Success 201 {object} AccountMemberResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Account access denied"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 409 {object} map[string]string "User is already an account admin"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts/{id}/admins [post]
+func AddAccountAdmin(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+
+	var requestData models.AccountAdminDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Where("username = ?", strings.TrimSpace(requestData.Username)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+		return
+	}
+
+	admin, err := database.AddAccountAdmin(database.DB, member.AccountID, user.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrAccountMemberExists) || isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already an account admin"})
+			return
+		}
+		log.Printf("Failed to add admin %d to account %d: %v", user.ID, member.AccountID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add account admin"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AccountMemberResponse{
+		UserID:      user.ID,
+		Username:    user.Username,
+		AccountRole: admin.Role,
+		Workspaces:  []AccountMemberWorkspace{},
+	})
+}
+
+// DeleteAccountAdmin revokes account admin access.
+// @Summary Remove account admin
+// @Description Revoke account admin access. Admins may remove themselves; an account must keep at least one admin.
+// @Tags Accounts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]string "Account admin removed"
+// @Failure 400 {object} map[string]string "Invalid ID"
+// @Failure 403 {object} map[string]string "Account access denied"
+// @Failure 404 {object} map[string]string "Account admin not found"
+// @Failure 409 {object} map[string]string "Account must keep at least one admin"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts/{id}/admins/{user_id} [delete]
+func DeleteAccountAdmin(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := database.RemoveAccountAdmin(database.DB, member.AccountID, uint(userID)); err != nil {
+		switch {
+		case errors.Is(err, database.ErrAccountMemberNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account admin not found"})
+		case errors.Is(err, database.ErrLastAccountAdmin):
+			c.JSON(http.StatusConflict, gin.H{"error": "Account must keep at least one admin"})
+		default:
+			log.Printf("Failed to remove admin %d from account %d: %v", userID, member.AccountID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove account admin"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account admin removed"})
+}
+
+// GetAccountDashboard returns profit aggregated across all workspaces of an account.
+// @Summary Get consolidated account dashboard
+// @Description Aggregate revenue, costs and profit of finished orders across all workspaces of an account, with a per-workspace breakdown. Uses the same calculation as /api/dashboard/profit. Workspaces are only summed with workspaces of the same default currency, so totals holds one entry per currency. Workspaces that require two-factor authentication are left out until the caller enables it.
+// @Tags Accounts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} AccountDashboardResponse
+// @Failure 400 {object} map[string]string "Invalid account ID"
+// @Failure 403 {object} map[string]string "Account access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/accounts/{id}/dashboard [get]
+func GetAccountDashboard(c *gin.Context) {
+	member, ok := accountMemberFromParam(c)
+	if !ok {
+		return
+	}
+
+	var workspaces []models.Workspace
+	if err := database.DB.Where("account_id = ?", member.AccountID).Order("id ASC").Find(&workspaces).Error; err != nil {
+		handleError(c, "Failed to fetch account workspaces", err)
+		return
+	}
+	workspaces, err := accountWorkspacesMeetingTwoFactor(member.UserID, workspaces)
+	if err != nil {
+		handleError(c, "Failed to fetch account workspaces", err)
+		return
+	}
+
+	workspaceIDs := make([]uint, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		workspaceIDs = append(workspaceIDs, workspace.ID)
+	}
+	summaries, err := loadProfitSummaries(workspaceIDs)
+	if err != nil {
+		handleError(c, "Failed to fetch profit data", err)
+		return
+	}
+
+	response := AccountDashboardResponse{
+		AccountID:  member.AccountID,
+		Totals:     make([]AccountProfitTotals, 0),
+		Workspaces: make([]AccountWorkspaceProfit, 0, len(workspaces)),
+	}
+	totalsByCurrency := make(map[string]int)
+	for _, workspace := range workspaces {
+		summary := summaries[workspace.ID]
+		index, ok := totalsByCurrency[summary.Currency]
+		if !ok {
+			index = len(response.Totals)
+			totalsByCurrency[summary.Currency] = index
+			response.Totals = append(response.Totals, AccountProfitTotals{Currency: summary.Currency})
+		}
+		response.Totals[index].TotalRevenue += summary.TotalRevenue
+		response.Totals[index].TotalCosts += summary.TotalCosts
+		response.Totals[index].OrderCount += summary.OrderCount
+		response.Workspaces = append(response.Workspaces, AccountWorkspaceProfit{
+			WorkspaceID:   workspace.ID,
+			WorkspaceName: workspace.Name,
+			ProfitData:    summary,
+		})
+	}
+	for i := range response.Totals {
+		totals := &response.Totals[i]
+		totals.TotalProfit = totals.TotalRevenue - totals.TotalCosts
+		totals.MarginPercent = profitMarginPercent(totals.TotalRevenue, totals.TotalProfit)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// accountWorkspacesMeetingTwoFactor leaves out the workspaces that require two-factor
+// authentication when userID has not enabled it, as WorkspaceMiddleware would deny their data.
+func accountWorkspacesMeetingTwoFactor(userID uint, workspaces []models.Workspace) ([]models.Workspace, error) {
+	enabled, err := database.UserHasTwoFactor(database.DB, userID)
+	if err != nil || enabled {
+		return workspaces, err
+	}
+
+	workspaceIDs := make([]uint, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		workspaceIDs = append(workspaceIDs, workspace.ID)
+	}
+	settingsByWorkspace, err := database.GetWorkspaceSettingsByWorkspace(database.DB, workspaceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]models.Workspace, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		if !settingsByWorkspace[workspace.ID].RequireTwoFactor {
+			allowed = append(allowed, workspace)
+		}
+	}
+	return allowed, nil
+}
+
+// accountMemberFromParam resolves the caller's account admin membership for the :id path parameter.
+func accountMemberFromParam(c *gin.Context) (models.AccountMember, bool) {
+	userID := c.MustGet("userID").(uint)
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || accountID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return models.AccountMember{}, false
+	}
+
+	member, found, err := database.FindAccountMember(database.DB, userID, uint(accountID))
+	if err != nil {
+		log.Printf("Failed to resolve account %d for user %d: %v", accountID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve account"})
+		return models.AccountMember{}, false
+	}
+	if !found || member.Role != constants.AccountRoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account access denied"})
+		return models.AccountMember{}, false
+	}
+
+	return member, true
+}
+
+func accountResponse(member models.AccountMember) AccountResponse {
+	return AccountResponse{
+		ID:   member.Account.ID,
+		Name: member.Account.Name,
+		Role: member.Role,
+	}
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+func TestAccountGroupsWorkspacesAndConsolidatesProfit(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+	db := database.DB
+
+	response := runWorkspaceJSONRequest(fixture.User.ID, 0, CreateAccount, http.MethodPost, "/accounts", "/accounts", map[string]any{"name": "Jerky Ltd"})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create account status = %d body = %s", response.Code, response.Body.String())
+	}
+	var account AccountResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &account); err != nil {
+		t.Fatalf("decode account: %v", err)
+	}
+	if account.Role != constants.AccountRoleAdmin {
+		t.Fatalf("account role = %q, want admin", account.Role)
+	}
+	accountPath := "/accounts/" + uintToString(account.ID)
+
+	response = runWorkspaceJSONRequest(fixture.User.ID, 0, AddAccountWorkspace, http.MethodPost, "/accounts/:id/workspaces", accountPath+"/workspaces", map[string]any{"workspace_id": fixture.PersonalWorkspace.ID})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("attach personal workspace status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	thirdMember, err := database.CreateBusinessWorkspace(db, fixture.User.ID, "Third kitchen", "")
+	if err != nil {
+		t.Fatalf("create third workspace: %v", err)
+	}
+	thirdOrder := models.Order{ClientID: fixture.SecondClient.ID, Date: time.Now(), Status: constants.OrderStatusFinished, UserID: fixture.User.ID, WorkspaceID: &thirdMember.WorkspaceID}
+	if err := db.Create(&thirdOrder).Error; err != nil {
+		t.Fatalf("create third order: %v", err)
+	}
+	thirdItem := models.OrderItem{OrderID: thirdOrder.ID, ProductID: fixture.SecondProduct.ID, Quantity: 1, Price: 10, Cost_price: 4}
+	if err := db.Create(&thirdItem).Error; err != nil {
+		t.Fatalf("create third order item: %v", err)
+	}
+	if err := db.Model(&fixture.SecondOrder).Update("status", constants.OrderStatusFinished).Error; err != nil {
+		t.Fatalf("finish second order: %v", err)
+	}
+
+	for _, workspaceID := range []uint{fixture.SecondWorkspace.ID, thirdMember.WorkspaceID} {
+		response = runWorkspaceJSONRequest(fixture.User.ID, 0, AddAccountWorkspace, http.MethodPost, "/accounts/:id/workspaces", accountPath+"/workspaces", map[string]any{"workspace_id": workspaceID})
+		if response.Code != http.StatusOK {
+			t.Fatalf("attach workspace %d status = %d body = %s", workspaceID, response.Code, response.Body.String())
+		}
+	}
+
+	operator := models.User{Username: "account-operator", Password: "hashed"}
+	if err := db.Create(&operator).Error; err != nil {
+		t.Fatalf("create operator: %v", err)
+	}
+	operatorMember := models.WorkspaceMember{WorkspaceID: fixture.SecondWorkspace.ID, UserID: operator.ID, Role: constants.WorkspaceRoleOperator}
+	if err := db.Create(&operatorMember).Error; err != nil {
+		t.Fatalf("create operator membership: %v", err)
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, 0, GetAccountDashboard, http.MethodGet, "/accounts/:id/dashboard", accountPath+"/dashboard")
+	if response.Code != http.StatusOK {
+		t.Fatalf("dashboard status = %d body = %s", response.Code, response.Body.String())
+	}
+	var dashboard AccountDashboardResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &dashboard); err != nil {
+		t.Fatalf("decode dashboard: %v", err)
+	}
+	if len(dashboard.Workspaces) != 2 || len(dashboard.Totals) != 1 {
+		t.Fatalf("dashboard = %+v, want two workspaces with one currency", dashboard)
+	}
+	if totals := dashboard.Totals[0]; totals.Currency != database.DefaultWorkspaceCurrency || totals.TotalRevenue != 73 || totals.TotalCosts != 28 || totals.TotalProfit != 45 || totals.OrderCount != 2 {
+		t.Fatalf("totals = %+v, want revenue 73, costs 28 and two orders across two workspaces", totals)
+	}
+
+	thirdSettings := database.DefaultWorkspaceSettings(thirdMember.WorkspaceID)
+	thirdSettings.DefaultCurrency = "RSD"
+	thirdSettings.LowMarginThresholdPercent = 70
+	if err := database.SaveWorkspaceSettings(db, &thirdSettings); err != nil {
+		t.Fatalf("save third workspace settings: %v", err)
+	}
+	response = runWorkspaceRequest(fixture.User.ID, 0, GetAccountDashboard, http.MethodGet, "/accounts/:id/dashboard", accountPath+"/dashboard")
+	if err := json.Unmarshal(response.Body.Bytes(), &dashboard); err != nil {
+		t.Fatalf("decode dashboard: %v", err)
+	}
+	if len(dashboard.Totals) != 2 || dashboard.Totals[0].TotalRevenue+dashboard.Totals[1].TotalRevenue != 73 || dashboard.Totals[0].Currency == dashboard.Totals[1].Currency {
+		t.Fatalf("totals = %+v, want one total per currency", dashboard.Totals)
+	}
+	for _, workspace := range dashboard.Workspaces {
+		third := workspace.WorkspaceID == thirdMember.WorkspaceID
+		if third != (workspace.Currency == "RSD") || third != workspace.LowMargin || workspace.MarginPercent == nil {
+			t.Fatalf("workspace profit = %+v, want the third workspace in RSD below its 70%% margin threshold", workspace)
+		}
+	}
+
+	thirdSettings.RequireTwoFactor = true
+	if err := database.SaveWorkspaceSettings(db, &thirdSettings); err != nil {
+		t.Fatalf("require two-factor: %v", err)
+	}
+	response = runWorkspaceRequest(fixture.User.ID, 0, GetAccountDashboard, http.MethodGet, "/accounts/:id/dashboard", accountPath+"/dashboard")
+	dashboard = AccountDashboardResponse{}
+	if err := json.Unmarshal(response.Body.Bytes(), &dashboard); err != nil {
+		t.Fatalf("decode dashboard: %v", err)
+	}
+	if len(dashboard.Workspaces) != 1 || dashboard.Workspaces[0].WorkspaceID != fixture.SecondWorkspace.ID || len(dashboard.Totals) != 1 {
+		t.Fatalf("dashboard = %+v, want the workspace requiring two-factor left out", dashboard)
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, 0, GetAccountMembers, http.MethodGet, "/accounts/:id/members", accountPath+"/members")
+	if response.Code != http.StatusOK {
+		t.Fatalf("members status = %d body = %s", response.Code, response.Body.String())
+	}
+	var members []AccountMemberResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &members); err != nil {
+		t.Fatalf("decode members: %v", err)
+	}
+	if len(members) != 2 || members[0].UserID != fixture.User.ID || members[0].AccountRole != constants.AccountRoleAdmin || len(members[0].Workspaces) != 2 {
+		t.Fatalf("members = %+v, want the admin in two workspaces and the operator", members)
+	}
+	if members[1].UserID != operator.ID || members[1].AccountRole != "" || len(members[1].Workspaces) != 1 || members[1].Workspaces[0].Role != constants.WorkspaceRoleOperator {
+		t.Fatalf("operator entry = %+v", members[1])
+	}
+
+	response = runWorkspaceRequest(operator.ID, 0, GetAccountDashboard, http.MethodGet, "/accounts/:id/dashboard", accountPath+"/dashboard")
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("operator dashboard status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, 0, DeleteAccountAdmin, http.MethodDelete, "/accounts/:id/admins/:user_id", accountPath+"/admins/"+uintToString(fixture.User.ID))
+	if response.Code != http.StatusConflict {
+		t.Fatalf("remove last admin status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, 0, DeleteAccountWorkspace, http.MethodDelete, "/accounts/:id/workspaces/:workspace_id", accountPath+"/workspaces/"+uintToString(thirdMember.WorkspaceID))
+	if response.Code != http.StatusOK {
+		t.Fatalf("detach status = %d body = %s", response.Code, response.Body.String())
+	}
+	var third models.Workspace
+	if err := db.First(&third, thirdMember.WorkspaceID).Error; err != nil || third.AccountID != nil {
+		t.Fatalf("detached workspace = %+v err = %v, want no account", third, err)
+	}
+}
+
+func TestAddAccountWorkspaceRequiresWorkspaceOwner(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+	db := database.DB
+
+	manager := models.User{Username: "account-manager", Password: "hashed"}
+	if err := db.Create(&manager).Error; err != nil {
+		t.Fatalf("create manager: %v", err)
+	}
+	membership := models.WorkspaceMember{WorkspaceID: fixture.SecondWorkspace.ID, UserID: manager.ID, Role: constants.WorkspaceRoleManager}
+	if err := db.Create(&membership).Error; err != nil {
+		t.Fatalf("create manager membership: %v", err)
+	}
+	account, err := database.CreateAccount(db, manager.ID, "Manager Ltd")
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+
+	response := runWorkspaceJSONRequest(manager.ID, 0, AddAccountWorkspace, http.MethodPost, "/accounts/:id/workspaces", "/accounts/"+uintToString(account.ID)+"/workspaces", map[string]any{"workspace_id": fixture.SecondWorkspace.ID})
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("manager attach status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	other, err := database.CreateAccount(db, fixture.User.ID, "Owner Ltd")
+	if err != nil {
+		t.Fatalf("create other account: %v", err)
+	}
+	if _, err := database.AttachWorkspaceToAccount(db, other.ID, fixture.SecondWorkspace.ID); err != nil {
+		t.Fatalf("attach to other account: %v", err)
+	}
+	if _, err := database.AddAccountAdmin(db, account.ID, fixture.User.ID); err != nil {
+		t.Fatalf("add admin: %v", err)
+	}
+	response = runWorkspaceJSONRequest(fixture.User.ID, 0, AddAccountWorkspace, http.MethodPost, "/accounts/:id/workspaces", "/accounts/"+uintToString(account.ID)+"/workspaces", map[string]any{"workspace_id": fixture.SecondWorkspace.ID})
+	if response.Code != http.StatusConflict {
+		t.Fatalf("attach grouped workspace status = %d body = %s", response.Code, response.Body.String())
+	}
+}
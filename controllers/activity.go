@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultActivityPageSize = 50
+	maxActivityPageSize     = 200
+)
+
+// ActivityResponse represents one entry of the workspace activity feed.
+type ActivityResponse struct {
+	ID         uint                `json:"id"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UserID     uint                `json:"user_id"`
+	Username   string              `json:"username"`
+	EntityType string              `json:"entity_type"`
+	EntityID   uint                `json:"entity_id"`
+	Action     string              `json:"action"`
+	Changes    models.AuditChanges `json:"changes"`
+}
+
+// ActivityPageResponse represents one page of the workspace activity feed.
+type ActivityPageResponse struct {
+	Items    []ActivityResponse `json:"items"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	Total    int64              `json:"total"`
+}
+
+// GetWorkspaceActivity returns the audit log of the current workspace.
+// @Summary Get workspace activity
+// @Description List who created, changed or deleted orders, products, recipes, prices, clients, packages and workspace ingredients, newest first. Each entry carries the before/after values of the changed fields.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param entity_type query string false "Entity type, e.g. order or product"
+// @Param entity_id query int false "Entity ID"
+// @Param action query string false "create, update or delete"
+// @Param user_id query int false "Acting user ID"
+// @Param from query string false "Start date (YYYY-MM-DD in the workspace time zone or RFC 3339), inclusive"
+// @Param to query string false "End date (YYYY-MM-DD in the workspace time zone or RFC 3339); a plain date includes the whole day"
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Entries per page (default 50, max 200)"
+// @Success 200 {object} ActivityPageResponse
+// @Failure 400 {object} map[string]string "Invalid parameters"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Workspace access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/activity [get]
+func GetWorkspaceActivity(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	filter := database.AuditLogFilter{
+		EntityType: c.Query("entity_type"),
+		Action:     c.Query("action"),
+		Page:       1,
+		PageSize:   defaultActivityPageSize,
+	}
+	if filter.Action != "" && !constants.IsValidAuditAction(filter.Action) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be create, update or delete"})
+		return
+	}
+
+	var ok bool
+	if filter.EntityID, ok = parseOptionalIDQuery(c, "entity_id"); !ok {
+		return
+	}
+	if filter.UserID, ok = parseOptionalIDQuery(c, "user_id"); !ok {
+		return
+	}
+	if filter.Page, ok = parsePositiveIntQuery(c, "page", 1); !ok {
+		return
+	}
+	if filter.PageSize, ok = parsePositiveIntQuery(c, "page_size", defaultActivityPageSize); !ok {
+		return
+	}
+	if filter.PageSize > maxActivityPageSize {
+		filter.PageSize = maxActivityPageSize
+	}
+
+	if c.Query("from") != "" || c.Query("to") != "" {
+		location, ok := loadWorkspaceLocation(c, workspaceID)
+		if !ok {
+			return
+		}
+		if filter.From, ok = parseActivityTimeQuery(c, "from", false, location); !ok {
+			return
+		}
+		if filter.To, ok = parseActivityTimeQuery(c, "to", true, location); !ok {
+			return
+		}
+	}
+
+	entries, total, err := database.ListAuditLogs(database.DB, workspaceID, filter)
+	if err != nil {
+		log.Printf("Failed to fetch activity for workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace activity"})
+		return
+	}
+
+	items := make([]ActivityResponse, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, ActivityResponse{
+			ID:         entry.ID,
+			CreatedAt:  entry.CreatedAt,
+			UserID:     entry.UserID,
+			Username:   entry.User.Username,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Action:     entry.Action,
+			Changes:    entry.Changes,
+		})
+	}
+
+	c.JSON(http.StatusOK, ActivityPageResponse{
+		Items:    items,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Total:    total,
+	})
+}
+
+// recordActivity stores an audit entry for a change made in the current workspace.
+func recordActivity(c *gin.Context, entityType string, entityID uint, action string, before map[string]interface{}, after map[string]interface{}) {
+	recordWorkspaceActivity(c, c.MustGet("workspaceID").(uint), entityType, entityID, action, before, after)
+}
+
+// recordWorkspaceActivity stores an audit entry for a change made by the current user in workspaceID.
+// Changes without any differing field are skipped. The change itself has already been saved,
+// so failures are logged instead of failing the request.
+func recordWorkspaceActivity(c *gin.Context, workspaceID uint, entityType string, entityID uint, action string, before map[string]interface{}, after map[string]interface{}) {
+	changes := database.AuditDiff(before, after)
+	if len(changes) == 0 {
+		return
+	}
+
+	entry := models.AuditLog{
+		WorkspaceID: workspaceID,
+		UserID:      c.MustGet("userID").(uint),
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Action:      action,
+		Changes:     changes,
+	}
+	if err := database.RecordAudit(database.DB, &entry); err != nil {
+		log.Printf("Failed to record %s %s %d in workspace %d: %v", action, entityType, entityID, workspaceID, err)
+	}
+}
+
+// activitySnapshot flattens an entity for recordActivity.
+func activitySnapshot(entity interface{}) map[string]interface{} {
+	snapshot, err := database.AuditSnapshot(entity)
+	if err != nil {
+		log.Printf("Failed to snapshot %T for activity: %v", entity, err)
+		return map[string]interface{}{}
+	}
+	return snapshot
+}
+
+// orderActivitySnapshot flattens an order together with its items.
+func orderActivitySnapshot(order models.Order, items []models.OrderItem) map[string]interface{} {
+	snapshot := activitySnapshot(order)
+	lines := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, map[string]interface{}{
+			"product_id": item.ProductID,
+			"quantity":   item.Quantity,
+			"price":      item.Price,
+			"cost_price": item.Cost_price,
+		})
+	}
+	snapshot["items"] = lines
+	return snapshot
+}
+
+// productActivitySnapshot flattens a product together with the recipes of its options.
+func productActivitySnapshot(product models.Product) map[string]interface{} {
+	snapshot := activitySnapshot(product)
+	recipeIDs := make([]uint, 0, len(product.Options))
+	for _, option := range product.Options {
+		recipeIDs = append(recipeIDs, option.RecipeID)
+	}
+	snapshot["recipe_ids"] = recipeIDs
+	return snapshot
+}
+
+// recipeContentActivitySnapshot loads a recipe and flattens it together with its ingredient and
+// sub-recipe lines and its method steps.
+func recipeContentActivitySnapshot(recipeID uint) map[string]interface{} {
+	var recipe models.Recipe
+	if err := database.DB.Preload("RecipeIngredients").Preload("SubRecipes").Preload("Steps", orderRecipeSteps).
+		First(&recipe, recipeID).Error; err != nil {
+		log.Printf("Failed to load recipe %d for activity: %v", recipeID, err)
+		return map[string]interface{}{}
+	}
+
+	snapshot := activitySnapshot(recipe)
+	ingredients := make([]map[string]interface{}, 0, len(recipe.RecipeIngredients))
+	for _, line := range recipe.RecipeIngredients {
+		ingredients = append(ingredients, map[string]interface{}{
+			"ingredient_id": line.IngredientID,
+			"quantity":      line.Quantity,
+			"unit":          line.Unit,
+		})
+	}
+	subRecipes := make([]map[string]interface{}, 0, len(recipe.SubRecipes))
+	for _, line := range recipe.SubRecipes {
+		subRecipes = append(subRecipes, map[string]interface{}{
+			"sub_recipe_id": line.SubRecipeID,
+			"quantity":      line.Quantity,
+			"unit":          line.Unit,
+		})
+	}
+	steps := make([]models.RecipeStepDetails, 0, len(recipe.Steps))
+	for _, step := range recipe.Steps {
+		steps = append(steps, step.RecipeStepDetails)
+	}
+	snapshot["ingredients"] = ingredients
+	snapshot["sub_recipes"] = subRecipes
+	snapshot["steps"] = steps
+	return snapshot
+}
+
+// workspaceIngredientActivitySnapshot flattens a workspace ingredient together with its nutrition overrides.
+func workspaceIngredientActivitySnapshot(workspaceIngredient models.WorkspaceIngredient) map[string]interface{} {
+	snapshot := activitySnapshot(workspaceIngredient)
+	snapshot["nutrition_override"] = workspaceIngredient.NutritionOverride
+	return snapshot
+}
+
+func parseOptionalIDQuery(c *gin.Context, name string) (uint, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func parsePositiveIntQuery(c *gin.Context, name string, fallback int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a positive integer"})
+		return 0, false
+	}
+	return number, true
+}
+
+// parseActivityTimeQuery reads an optional time query parameter. Plain dates are read in location;
+// with nextDay set they stand for the start of the following day, so that the whole day is included.
+func parseActivityTimeQuery(c *gin.Context, name string, nextDay bool, location *time.Location) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	parsed, dateOnly, err := parseActivityTime(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " date"})
+		return nil, false
+	}
+	if dateOnly {
+		parsed = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, location)
+		if nextDay {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+	}
+	parsed = parsed.UTC()
+	return &parsed, true
+}
+
+// parseActivityTime accepts RFC 3339 timestamps and plain YYYY-MM-DD dates.
+func parseActivityTime(value string) (time.Time, bool, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, false, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	return parsed, true, err
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+func TestWorkspaceActivityRecordsActorAndDiff(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+	orderPath := "/orders/" + uintToString(fixture.PersonalOrder.ID)
+
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateOrderStatus, http.MethodPut, "/orders/:id/status", orderPath+"/status", map[string]any{"status": constants.OrderStatusCanceled})
+	if response.Code != http.StatusOK {
+		t.Fatalf("update status = %d body = %s", response.Code, response.Body.String())
+	}
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, DeleteProduct, http.MethodDelete, "/products/:id", "/products/"+uintToString(fixture.PersonalProduct.ID))
+	if response.Code != http.StatusOK {
+		t.Fatalf("delete product status = %d body = %s", response.Code, response.Body.String())
+	}
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, AddClient, http.MethodPost, "/clients", "/clients", map[string]any{"name": "Other", "surname": "Workspace"})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("add client status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	page := getWorkspaceActivity(t, fixture.User.ID, fixture.PersonalWorkspace.ID, "/workspaces/current/activity")
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("activity = %+v, want two personal workspace entries", page)
+	}
+	latest := page.Items[0]
+	if latest.EntityType != constants.AuditEntityProduct || latest.Action != constants.AuditActionDelete || latest.Username != fixture.User.Username {
+		t.Fatalf("latest entry = %+v, want product deletion by %s", latest, fixture.User.Username)
+	}
+	if change := latest.Changes["name"]; change.Before != "Personal product" || change.After != nil {
+		t.Fatalf("product name change = %+v", change)
+	}
+
+	page = getWorkspaceActivity(t, fixture.User.ID, fixture.PersonalWorkspace.ID, "/workspaces/current/activity?entity_type=order&entity_id="+uintToString(fixture.PersonalOrder.ID))
+	if page.Total != 1 {
+		t.Fatalf("order activity = %+v, want one entry", page)
+	}
+	statusEntry := page.Items[0]
+	if statusEntry.Action != constants.AuditActionUpdate || statusEntry.UserID != fixture.User.ID || len(statusEntry.Changes) != 1 {
+		t.Fatalf("status entry = %+v, want a single-field update", statusEntry)
+	}
+	if change := statusEntry.Changes["status"]; change.Before != constants.OrderStatusFinished || change.After != constants.OrderStatusCanceled {
+		t.Fatalf("status change = %+v", change)
+	}
+
+	page = getWorkspaceActivity(t, fixture.User.ID, fixture.PersonalWorkspace.ID, "/workspaces/current/activity?page=2&page_size=1")
+	if page.Total != 2 || len(page.Items) != 1 || page.Items[0].EntityType != constants.AuditEntityOrder {
+		t.Fatalf("second page = %+v, want the order entry", page)
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetWorkspaceActivity, http.MethodGet, "/workspaces/current/activity", "/workspaces/current/activity?action=rename")
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("invalid action status = %d body = %s", response.Code, response.Body.String())
+	}
+}
+
+func TestWorkspaceActivityDatesAreDaysOfWorkspaceTimezone(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+
+	settings := database.DefaultWorkspaceSettings(fixture.PersonalWorkspace.ID)
+	settings.Timezone = "Asia/Tokyo"
+	if err := database.SaveWorkspaceSettings(database.DB, &settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	// 23:00 on March 1 and 01:00 on March 2 in Tokyo, both on March 1 in UTC.
+	for i, createdAt := range []time.Time{
+		time.Date(2026, time.March, 1, 14, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 1, 16, 0, 0, 0, time.UTC),
+	} {
+		entry := models.AuditLog{
+			CreatedAt:   createdAt,
+			WorkspaceID: fixture.PersonalWorkspace.ID,
+			UserID:      fixture.User.ID,
+			EntityType:  constants.AuditEntityClient,
+			EntityID:    uint(i + 1),
+			Action:      constants.AuditActionUpdate,
+		}
+		if err := database.DB.Create(&entry).Error; err != nil {
+			t.Fatalf("create audit log: %v", err)
+		}
+	}
+
+	page := getWorkspaceActivity(t, fixture.User.ID, fixture.PersonalWorkspace.ID, "/workspaces/current/activity?to=2026-03-01")
+	if page.Total != 1 || page.Items[0].EntityID != 1 {
+		t.Fatalf("activity up to March 1 = %+v, want the 23:00 entry", page)
+	}
+	page = getWorkspaceActivity(t, fixture.User.ID, fixture.PersonalWorkspace.ID, "/workspaces/current/activity?from=2026-03-02&to=2026-03-02")
+	if page.Total != 1 || page.Items[0].EntityID != 2 {
+		t.Fatalf("activity on March 2 = %+v, want the 01:00 entry", page)
+	}
+	page = getWorkspaceActivity(t, fixture.User.ID, fixture.PersonalWorkspace.ID, "/workspaces/current/activity?from=2026-03-01T15:00:00Z")
+	if page.Total != 1 || page.Items[0].EntityID != 2 {
+		t.Fatalf("activity from 15:00 UTC = %+v, want the 16:00 UTC entry", page)
+	}
+}
+
+func getWorkspaceActivity(t *testing.T, userID uint, workspaceID uint, target string) ActivityPageResponse {
+	t.Helper()
+
+	response := runWorkspaceRequest(userID, workspaceID, GetWorkspaceActivity, http.MethodGet, "/workspaces/current/activity", target)
+	if response.Code != http.StatusOK {
+		t.Fatalf("activity status = %d body = %s", response.Code, response.Body.String())
+	}
+	var page ActivityPageResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode activity: %v", err)
+	}
+	return page
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyByteSize      = 32
+	apiKeyDisplayLength = len(constants.APIKeyPrefix) + 8
+)
+
+// CreateAPIKey creates an API key for the current workspace.
+// @Summary Create API key
+// @Description Create a workspace API key for an integration. Scopes have the form resource:read or resource:write, e.g. orders:read, orders:write, prices:write; write covers create, update and delete. The key acts with the creator's workspace role, limited to its scopes. The key is returned only once. Requires owner or manager role and cannot be done with an API key.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param key body models.APIKeyCreateDTO true "API key data"
+// @Success 201 {object} models.APIKeyCreatedResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/api-keys [post]
+func CreateAPIKey(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	var requestData models.APIKeyCreateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(requestData.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	scopes := make(models.APIKeyScopes, 0, len(requestData.Scopes))
+	seen := make(map[string]bool, len(requestData.Scopes))
+	for _, scope := range requestData.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !constants.IsValidAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope: " + scope})
+			return
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one scope is required"})
+		return
+	}
+
+	if requestData.ExpiresAt != nil && !requestData.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	secret, err := utils.GenerateSecureToken(apiKeyByteSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+	rawKey := constants.APIKeyPrefix + secret
+
+	key := models.APIKey{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Name:        name,
+		Prefix:      rawKey[:apiKeyDisplayLength],
+		KeyHash:     utils.HashToken(rawKey),
+		Scopes:      scopes,
+		ExpiresAt:   requestData.ExpiresAt,
+	}
+	if err := database.DB.Create(&key).Error; err != nil {
+		log.Printf("Failed to create API key in workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIKeyCreatedResponse{APIKey: key, Key: rawKey})
+}
+
+// GetAPIKeys lists API keys of the current workspace.
+// @Summary Get API keys
+// @Description List API keys of the current workspace, newest first, including revoked ones. Keys themselves are never returned; use the prefix to identify them.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Workspace access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/api-keys [get]
+func GetAPIKeys(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	var keys []models.APIKey
+	if err := database.DB.Where("workspace_id = ?", workspaceID).Order("created_at DESC, id DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes an API key of the current workspace.
+// @Summary Revoke API key
+// @Description Revoke an API key. It stops working immediately. Requires owner or manager role and cannot be done with an API key.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "API key ID"
+// @Success 200 {object} map[string]string "API key revoked"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "API key not found"
+// @Failure 409 {object} map[string]string "API key is already revoked"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/api-keys/{id} [delete]
+func RevokeAPIKey(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	keyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	var key models.APIKey
+	if err := database.DB.Where("id = ? AND workspace_id = ?", keyID, workspaceID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API key"})
+		return
+	}
+
+	result := database.DB.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", key.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "API key is already revoked"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -3,13 +3,14 @@ package controllers
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"log"
+	"math"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
 	"mobile-backend-go/models"
 	"mobile-backend-go/utils"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
@@ -17,11 +18,10 @@ import (
 	"gorm.io/gorm"
 )
 
-// Definition of Claims structure for JWT tokens
-type Claims struct {
-	UserID uint `json:"userID"` // Changed to UserID
-	jwt.RegisteredClaims
-}
+const (
+	refreshTokenByteSize      = 32
+	maxSessionUserAgentLength = 512
+)
 
 // Definition of structures for registration and login requests
 type RegisterPayload struct {
@@ -91,13 +91,14 @@ func isUniqueViolation(err error) bool {
 
 // Login authenticates a user and returns a JWT
 // @Summary Login a user
-// @Description Authenticate a user and return a JWT token
+// @Description Authenticate a user and return a short-lived access token and a refresh token. Users with two-factor authentication get a TwoFactorChallengeResponse instead, to be completed at /api/auth/2fa/verify. Repeated failures lock the username temporarily, with the lockout doubling on every further failure.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
 // @Param user body LoginPayload true "User credentials"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} TokenResponse
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]interface{} "Too many failed attempts; retry_after seconds"
 // @Router /api/auth/login [post]
 func Login(c *gin.Context) {
 	var payload LoginPayload
@@ -106,38 +107,188 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
+	lockedUntil, err := database.LoginLockedUntil(database.DB, payload.Username, now)
+	if err != nil {
+		log.Printf("Failed to check login lockout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	if lockedUntil != nil {
+		respondLoginLocked(c, *lockedUntil, now)
+		return
+	}
+
 	var user models.User
-	if err := database.DB.Where("username = ?", payload.Username).First(&user).Error; err != nil {
+	userFound := database.DB.Where("username = ?", payload.Username).First(&user).Error == nil
+	if !userFound || !utils.CheckPassword(user.Password, payload.Password) {
+		// Unknown usernames are throttled too, so responses do not reveal which users exist
+		lockedUntil, err := database.RecordFailedLogin(database.DB, payload.Username, now)
+		if err != nil {
+			log.Printf("Failed to record failed login: %v", err)
+		}
+		if userFound {
+			recordSecurityEvent(c, user.ID, constants.SecurityEventLoginFailed)
+			if lockedUntil != nil {
+				recordSecurityEvent(c, user.ID, constants.SecurityEventAccountLocked)
+			}
+		}
 		// Do not reveal information about user existence
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	if !utils.CheckPassword(user.Password, payload.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	twoFactorEnabled, err := database.UserHasTwoFactor(database.DB, user.ID)
+	if err != nil {
+		log.Printf("Failed to check two-factor status of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	if twoFactorEnabled {
+		respondTwoFactorChallenge(c, user.ID)
 		return
 	}
 
-	// Token is valid for 24 hours (instead of 72)
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		UserID: user.ID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   "user:" + strconv.FormatUint(uint64(user.ID), 10),
-		},
+	completeLogin(c, user)
+}
+
+// completeLogin starts a session for a user who passed every login step and responds with its tokens.
+func completeLogin(c *gin.Context, user models.User) {
+	if err := database.ResetFailedLogins(database.DB, user.Username); err != nil {
+		log.Printf("Failed to reset failed logins of user %d: %v", user.ID, err)
 	}
+	recordSecurityEvent(c, user.ID, constants.SecurityEventLoginSucceeded)
+
+	refreshToken, err := utils.GenerateSecureToken(refreshTokenByteSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create refresh token"})
+		return
+	}
+
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > maxSessionUserAgentLength {
+		userAgent = userAgent[:maxSessionUserAgentLength]
+	}
+	session, refreshExpiresAt, err := database.CreateAuthSession(database.DB, models.AuthSession{
+		UserID:     user.ID,
+		DeviceName: utils.DeviceNameFromUserAgent(userAgent),
+		UserAgent:  userAgent,
+		IPAddress:  c.ClientIP(),
+	}, utils.HashToken(refreshToken))
+	if err != nil {
+		log.Printf("Failed to create session for user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+
+	respondWithTokens(c, user.ID, session.ID, user.TokenGeneration, refreshToken, refreshExpiresAt)
+}
+
+// RefreshToken exchanges a refresh token for a new access and refresh token pair
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and a new refresh token. Every refresh token can be used once; presenting a used one revokes the whole session.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param token body models.RefreshTokenDTO true "Refresh token"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/auth/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var payload models.RefreshTokenDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refreshToken, err := utils.GenerateSecureToken(refreshTokenByteSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create refresh token"})
+		return
+	}
+
+	session, refreshExpiresAt, err := database.RotateRefreshToken(database.DB, utils.HashToken(payload.RefreshToken), utils.HashToken(refreshToken))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRefreshTokenReused):
+			log.Printf("Refresh token reuse detected, revoked session %d of user %d", session.ID, session.UserID)
+			recordSecurityEvent(c, session.UserID, constants.SecurityEventRefreshTokenReuse)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used; session has been revoked"})
+		case errors.Is(err, database.ErrRefreshTokenInvalid):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		default:
+			log.Printf("Failed to refresh token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
+		return
+	}
+
+	generation, err := database.UserTokenGeneration(database.DB, session.UserID)
+	if err != nil {
+		log.Printf("Failed to load token generation of user %d: %v", session.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	respondWithTokens(c, session.UserID, session.ID, generation, refreshToken, refreshExpiresAt)
+}
+
+// Logout revokes the session a refresh token belongs to
+// @Summary Logout
+// @Description Revoke the session of the given refresh token. Its access tokens stop working immediately.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param token body models.RefreshTokenDTO true "Refresh token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /api/auth/logout [post]
+func Logout(c *gin.Context) {
+	var payload models.RefreshTokenDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.RevokeSessionByRefreshToken(database.DB, utils.HashToken(payload.RefreshToken), database.SessionRevokedLogout); err != nil {
+		log.Printf("Failed to revoke session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// respondLoginLocked rejects a login attempt for a locked username.
+func respondLoginLocked(c *gin.Context, lockedUntil time.Time, now time.Time) {
+	retryAfter := int(math.Ceil(lockedUntil.Sub(now).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts, try again later",
+		"retry_after": retryAfter,
+	})
+}
+
+// TokenResponse is returned by login and refresh.
+type TokenResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+func respondWithTokens(c *gin.Context, userID uint, sessionID uint, generation uint, refreshToken string, refreshExpiresAt time.Time) {
+	tokenString, expiresAt, err := middleware.IssueAccessToken(userID, sessionID, generation)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token":      tokenString,
-		"expires_at": expirationTime,
+	c.JSON(http.StatusOK, TokenResponse{
+		Token:            tokenString,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
 	})
 }
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+)
+
+const authTestPassword = "correct-horse-battery"
+
+func setupAuthTest(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "auth-test-secret-with-enough-length")
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.AuthSession{},
+		&models.RefreshToken{},
+		&models.LoginThrottle{},
+		&models.SecurityEvent{},
+		&models.OneTimeCode{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
+	); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	database.DB = db
+
+	hashedPassword, err := utils.HashPassword(authTestPassword)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	if err := db.Create(&models.User{Username: "auth-user", Password: hashedPassword}).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/auth/login", Login)
+	router.POST("/auth/refresh", RefreshToken)
+	router.POST("/auth/logout", Logout)
+	router.POST("/auth/password-reset/request", RequestPasswordReset)
+	router.POST("/auth/password-reset/confirm", ConfirmPasswordReset)
+	router.POST("/auth/2fa/verify", VerifyTwoFactorLogin)
+	router.PUT("/profile/email", middleware.JWTMiddleware(), UpdateEmail)
+	router.POST("/profile/email/verify", middleware.JWTMiddleware(), VerifyEmail)
+	router.POST("/profile/change-password", middleware.JWTMiddleware(), ChangePassword)
+	router.GET("/profile/security-events", middleware.JWTMiddleware(), GetSecurityEvents)
+	router.GET("/profile/sessions", middleware.JWTMiddleware(), GetSessions)
+	router.DELETE("/profile/sessions", middleware.JWTMiddleware(), RevokeOtherSessions)
+	router.DELETE("/profile/sessions/:id", middleware.JWTMiddleware(), RevokeSession)
+	router.GET("/profile/2fa", middleware.JWTMiddleware(), GetTwoFactorStatus)
+	router.POST("/profile/2fa/enroll", middleware.JWTMiddleware(), EnrollTwoFactor)
+	router.POST("/profile/2fa/confirm", middleware.JWTMiddleware(), ConfirmTwoFactor)
+	router.POST("/profile/2fa/disable", middleware.JWTMiddleware(), DisableTwoFactor)
+	router.GET("/me", middleware.JWTMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.MustGet("userID")})
+	})
+	return router
+}
+
+func TestRefreshTokenRotationDetectsReuse(t *testing.T) {
+	router := setupAuthTest(t)
+
+	login := postAuthJSON(t, router, "/auth/login", map[string]any{"username": "auth-user", "password": authTestPassword}, http.StatusOK)
+	if login.Token == "" || login.RefreshToken == "" || !login.RefreshExpiresAt.After(login.ExpiresAt) {
+		t.Fatalf("login response = %+v", login)
+	}
+	if status := getWithToken(router, login.Token); status != http.StatusOK {
+		t.Fatalf("access token status = %d", status)
+	}
+
+	refreshed := postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": login.RefreshToken}, http.StatusOK)
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == login.RefreshToken {
+		t.Fatalf("refresh did not rotate the refresh token: %+v", refreshed)
+	}
+
+	postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": login.RefreshToken}, http.StatusUnauthorized)
+	postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": refreshed.RefreshToken}, http.StatusUnauthorized)
+	if status := getWithToken(router, refreshed.Token); status != http.StatusUnauthorized {
+		t.Fatalf("access token after reuse status = %d, want 401", status)
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	router := setupAuthTest(t)
+
+	login := postAuthJSON(t, router, "/auth/login", map[string]any{"username": "auth-user", "password": authTestPassword}, http.StatusOK)
+	postAuthJSON(t, router, "/auth/logout", map[string]any{"refresh_token": login.RefreshToken}, http.StatusOK)
+
+	if status := getWithToken(router, login.Token); status != http.StatusUnauthorized {
+		t.Fatalf("access token after logout status = %d, want 401", status)
+	}
+	postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": login.RefreshToken}, http.StatusUnauthorized)
+	postAuthJSON(t, router, "/auth/logout", map[string]any{"refresh_token": "unknown"}, http.StatusOK)
+}
+
+func TestChangePasswordInvalidatesOtherSessions(t *testing.T) {
+	router := setupAuthTest(t)
+	credentials := map[string]any{"username": "auth-user", "password": authTestPassword}
+
+	current := postAuthJSON(t, router, "/auth/login", credentials, http.StatusOK)
+	other := postAuthJSON(t, router, "/auth/login", credentials, http.StatusOK)
+
+	response := sendAuthJSON(router, http.MethodPost, "/profile/change-password", current.Token, map[string]any{
+		"currentPassword":    authTestPassword,
+		"newPassword":        "new-password-value",
+		"keepCurrentSession": true,
+	})
+	if response.Code != http.StatusOK {
+		t.Fatalf("change password status = %d body = %s", response.Code, response.Body.String())
+	}
+	var changed TokenResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &changed); err != nil || changed.Token == "" {
+		t.Fatalf("change password response = %s", response.Body.String())
+	}
+
+	if status := getWithToken(router, current.Token); status != http.StatusUnauthorized {
+		t.Fatalf("old access token status = %d, want 401", status)
+	}
+	if status := getWithToken(router, other.Token); status != http.StatusUnauthorized {
+		t.Fatalf("other session access token status = %d, want 401", status)
+	}
+	postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": other.RefreshToken}, http.StatusUnauthorized)
+
+	if status := getWithToken(router, changed.Token); status != http.StatusOK {
+		t.Fatalf("replacement access token status = %d, want 200", status)
+	}
+	refreshed := postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": current.RefreshToken}, http.StatusOK)
+	if status := getWithToken(router, refreshed.Token); status != http.StatusOK {
+		t.Fatalf("refreshed access token status = %d, want 200", status)
+	}
+
+	response = sendAuthJSON(router, http.MethodPost, "/profile/change-password", refreshed.Token, map[string]any{
+		"currentPassword": "new-password-value",
+		"newPassword":     authTestPassword,
+	})
+	if response.Code != http.StatusOK {
+		t.Fatalf("second change password status = %d body = %s", response.Code, response.Body.String())
+	}
+	if status := getWithToken(router, refreshed.Token); status != http.StatusUnauthorized {
+		t.Fatalf("access token after change without keep status = %d, want 401", status)
+	}
+	postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": refreshed.RefreshToken}, http.StatusUnauthorized)
+}
+
+func TestLoginLocksUsernameAfterRepeatedFailures(t *testing.T) {
+	router := setupAuthTest(t)
+	wrong := map[string]any{"username": "auth-user", "password": "wrong-password"}
+	correct := map[string]any{"username": "auth-user", "password": authTestPassword}
+
+	for attempt := 1; attempt <= database.LoginFailuresBeforeLockout; attempt++ {
+		postAuthJSON(t, router, "/auth/login", wrong, http.StatusUnauthorized)
+	}
+	postAuthJSON(t, router, "/auth/login", correct, http.StatusTooManyRequests)
+
+	var throttle models.LoginThrottle
+	if err := database.DB.Where("username = ?", "auth-user").First(&throttle).Error; err != nil {
+		t.Fatalf("load throttle: %v", err)
+	}
+	if throttle.FailedCount != database.LoginFailuresBeforeLockout || throttle.LockedUntil == nil {
+		t.Fatalf("throttle = %+v, want locked after %d failures", throttle, database.LoginFailuresBeforeLockout)
+	}
+
+	// Let the lockout expire; the next failure locks again for twice as long.
+	expired := time.Now().Add(-time.Second)
+	database.DB.Model(&models.LoginThrottle{}).Where("id = ?", throttle.ID).Update("locked_until", expired)
+	postAuthJSON(t, router, "/auth/login", wrong, http.StatusUnauthorized)
+	if err := database.DB.First(&throttle, throttle.ID).Error; err != nil {
+		t.Fatalf("reload throttle: %v", err)
+	}
+	if lockout := time.Until(*throttle.LockedUntil); lockout <= database.LoginLockoutBase || lockout > 2*database.LoginLockoutBase {
+		t.Fatalf("second lockout = %v, want about %v", lockout, 2*database.LoginLockoutBase)
+	}
+
+	database.DB.Model(&models.LoginThrottle{}).Where("id = ?", throttle.ID).Update("locked_until", expired)
+	login := postAuthJSON(t, router, "/auth/login", correct, http.StatusOK)
+	var remaining int64
+	database.DB.Model(&models.LoginThrottle{}).Count(&remaining)
+	if remaining != 0 {
+		t.Fatalf("throttle rows after successful login = %d, want 0", remaining)
+	}
+
+	postAuthJSON(t, router, "/auth/login", map[string]any{"username": "nobody", "password": "whatever"}, http.StatusUnauthorized)
+
+	request := httptest.NewRequest(http.MethodGet, "/profile/security-events?type=account_locked", nil)
+	request.Header.Set("Authorization", "Bearer "+login.Token)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	var events []models.SecurityEvent
+	if err := json.Unmarshal(response.Body.Bytes(), &events); err != nil || len(events) != 2 {
+		t.Fatalf("lockout events = %s, want two", response.Body.String())
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/profile/security-events", nil)
+	request.Header.Set("Authorization", "Bearer "+login.Token)
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	if err := json.Unmarshal(response.Body.Bytes(), &events); err != nil || len(events) != 9 {
+		t.Fatalf("security events = %s, want nine", response.Body.String())
+	}
+	if events[0].Type != constants.SecurityEventLoginSucceeded {
+		t.Fatalf("latest event = %+v, want successful login", events[0])
+	}
+}
+
+func postAuthJSON(t *testing.T, router *gin.Engine, target string, body any, wantStatus int) TokenResponse {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	request := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	if response.Code != wantStatus {
+		t.Fatalf("POST %s status = %d, want %d, body = %s", target, response.Code, wantStatus, response.Body.String())
+	}
+	var tokens TokenResponse
+	if wantStatus == http.StatusOK {
+		_ = json.Unmarshal(response.Body.Bytes(), &tokens)
+	}
+	return tokens
+}
+
+func getWithToken(router *gin.Engine, token string) int {
+	request := httptest.NewRequest(http.MethodGet, "/me", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response.Code
+}
+
+func TestFailedLoginPrunesForgottenThrottles(t *testing.T) {
+	router := setupAuthTest(t)
+
+	stale := models.LoginThrottle{Username: "long-gone", FailedCount: 3, LastFailedAt: time.Now().Add(-database.LoginFailureResetAfter - time.Hour)}
+	recent := models.LoginThrottle{Username: "recently-failed", FailedCount: 1, LastFailedAt: time.Now().Add(-time.Hour)}
+	for _, throttle := range []*models.LoginThrottle{&stale, &recent} {
+		if err := database.DB.Create(throttle).Error; err != nil {
+			t.Fatalf("create throttle: %v", err)
+		}
+	}
+
+	postAuthJSON(t, router, "/auth/login", map[string]any{"username": "nobody", "password": "whatever"}, http.StatusUnauthorized)
+
+	var usernames []string
+	database.DB.Model(&models.LoginThrottle{}).Order("username ASC").Pluck("username", &usernames)
+	if len(usernames) != 2 || usernames[0] != "nobody" || usernames[1] != "recently-failed" {
+		t.Fatalf("throttled usernames = %v, want the forgotten failures pruned", usernames)
+	}
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"github.com/gin-gonic/gin"
 	"log"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
 	"mobile-backend-go/models"
 	"net/http"
@@ -104,6 +105,7 @@ func AddClient(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityClient, newClient.ID, constants.AuditActionCreate, nil, activitySnapshot(newClient))
 	c.JSON(http.StatusCreated, newClient)
 }
 
@@ -142,6 +144,8 @@ func UpdateClient(c *gin.Context) {
 		return
 	}
 
+	before := activitySnapshot(client)
+
 	// Update client fields from DTO
 	client.Name = requestData.Name
 	client.Surname = requestData.Surname
@@ -156,6 +160,8 @@ func UpdateClient(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityClient, client.ID, constants.AuditActionUpdate, before, activitySnapshot(client))
+
 	c.JSON(http.StatusOK, gin.H{"message": "Client updated successfully"})
 }
 
@@ -190,5 +196,7 @@ func DeleteClient(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityClient, client.ID, constants.AuditActionDelete, activitySnapshot(client), nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Client deleted successfully"})
 }
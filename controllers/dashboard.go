@@ -27,6 +27,7 @@ type OrderTypeDistribution struct {
 
 // Structure for dashboard data
 type DashboardData struct {
+	Currency              string                  `json:"currency"`
 	TotalRecipes          int64                   `json:"total_recipes"`
 	TotalProducts         int64                   `json:"total_products"`
 	TotalOrders           int64                   `json:"total_orders"`
@@ -43,7 +44,7 @@ func handleError(c *gin.Context, message string, err error) {
 
 // GetDashboardData returns dashboard data
 // @Summary Get dashboard data
-// @Description Fetch statistics for the dashboard
+// @Description Fetch statistics for the dashboard. Order totals are in the default currency of the workspace.
 // @Tags Dashboard
 // @Security BearerAuth
 // @Produce  json
@@ -55,6 +56,13 @@ func GetDashboardData(c *gin.Context) {
 	workspaceID := c.MustGet("workspaceID").(uint)
 	var dashboard DashboardData
 
+	settings, err := database.GetWorkspaceSettings(database.DB, workspaceID)
+	if err != nil {
+		handleError(c, "Failed to load workspace settings", err)
+		return
+	}
+	dashboard.Currency = settings.DefaultCurrency
+
 	// Get total recipes count
 	if err := database.DB.Model(&models.Recipe{}).Where("workspace_id = ?", workspaceID).Count(&dashboard.TotalRecipes).Error; err != nil {
 		handleError(c, "Failed to fetch total recipes", err)
@@ -130,17 +138,22 @@ func GetDashboardData(c *gin.Context) {
 	c.JSON(http.StatusOK, dashboard)
 }
 
-// Structure for profit data
+// Structure for profit data. Amounts are in the default currency of the workspace; MarginPercent
+// is the profit share of revenue, empty without revenue, and LowMargin reports a margin below the
+// low-margin threshold of the workspace.
 type ProfitData struct {
-	TotalRevenue float64 `json:"total_revenue"`
-	TotalCosts   float64 `json:"total_costs"`
-	TotalProfit  float64 `json:"total_profit"`
-	OrderCount   int64   `json:"order_count"`
+	Currency      string   `json:"currency"`
+	TotalRevenue  float64  `json:"total_revenue"`
+	TotalCosts    float64  `json:"total_costs"`
+	TotalProfit   float64  `json:"total_profit"`
+	OrderCount    int64    `json:"order_count"`
+	MarginPercent *float64 `json:"margin_percent"`
+	LowMargin     bool     `json:"low_margin"`
 }
 
 // GetProfitData returns profit data
 // @Summary Get profit data
-// @Description Fetch profit statistics for completed orders
+// @Description Fetch profit statistics for completed orders in the default currency of the workspace, with the profit margin flagged when it is below the workspace low-margin threshold
 // @Tags Dashboard
 // @Security BearerAuth
 // @Produce  json
@@ -150,33 +163,77 @@ type ProfitData struct {
 // @Router /api/dashboard/profit [get]
 func GetProfitData(c *gin.Context) {
 	workspaceID := c.MustGet("workspaceID").(uint)
-	var profitData ProfitData
 
+	summaries, err := loadProfitSummaries([]uint{workspaceID})
+	if err != nil {
+		handleError(c, "Failed to fetch profit data", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summaries[workspaceID])
+}
+
+// loadProfitSummaries aggregates finished-order revenue and costs per workspace and reports them
+// with the currency and margin threshold from the workspace settings.
+func loadProfitSummaries(workspaceIDs []uint) (map[uint]ProfitData, error) {
 	type ProfitSummary struct {
+		WorkspaceID  uint    `json:"workspace_id"`
 		TotalRevenue float64 `json:"total_revenue"`
 		TotalCosts   float64 `json:"total_costs"`
 		OrderCount   int64   `json:"order_count"`
 	}
 
-	var summary ProfitSummary
+	profitData := make(map[uint]ProfitData, len(workspaceIDs))
+	if len(workspaceIDs) == 0 {
+		return profitData, nil
+	}
+
+	settingsByWorkspace, err := database.GetWorkspaceSettingsByWorkspace(database.DB, workspaceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []ProfitSummary
 	if err := database.DB.Table("order_items").
 		Select(`
+			orders.workspace_id as workspace_id,
 			COALESCE(SUM(order_items.price * order_items.quantity), 0) as total_revenue,
 			COALESCE(SUM(order_items.cost_price * order_items.quantity), 0) as total_costs,
 			COUNT(DISTINCT orders.id) as order_count
 		`).
 		Joins("JOIN orders ON order_items.order_id = orders.id").
-		Where("orders.workspace_id = ? AND orders.status = ? AND orders.deleted_at IS NULL AND order_items.deleted_at IS NULL",
-			workspaceID, constants.OrderStatusFinished).
-		Scan(&summary).Error; err != nil {
-		handleError(c, "Failed to fetch profit data", err)
-		return
+		Where("orders.workspace_id IN ? AND orders.status = ? AND orders.deleted_at IS NULL AND order_items.deleted_at IS NULL",
+			workspaceIDs, constants.OrderStatusFinished).
+		Group("orders.workspace_id").
+		Scan(&summaries).Error; err != nil {
+		return nil, err
 	}
 
-	profitData.TotalRevenue = summary.TotalRevenue
-	profitData.TotalCosts = summary.TotalCosts
-	profitData.TotalProfit = summary.TotalRevenue - summary.TotalCosts
-	profitData.OrderCount = summary.OrderCount
+	for _, summary := range summaries {
+		profitData[summary.WorkspaceID] = ProfitData{
+			TotalRevenue: summary.TotalRevenue,
+			TotalCosts:   summary.TotalCosts,
+			TotalProfit:  summary.TotalRevenue - summary.TotalCosts,
+			OrderCount:   summary.OrderCount,
+		}
+	}
+	for _, workspaceID := range workspaceIDs {
+		settings := settingsByWorkspace[workspaceID]
+		data := profitData[workspaceID]
+		data.Currency = settings.DefaultCurrency
+		data.MarginPercent = profitMarginPercent(data.TotalRevenue, data.TotalProfit)
+		data.LowMargin = data.MarginPercent != nil && *data.MarginPercent < settings.LowMarginThresholdPercent
+		profitData[workspaceID] = data
+	}
 
-	c.JSON(http.StatusOK, profitData)
+	return profitData, nil
+}
+
+// profitMarginPercent returns profit as a percentage of revenue, or nil without revenue.
+func profitMarginPercent(revenue, profit float64) *float64 {
+	if revenue <= 0 {
+		return nil
+	}
+	margin := profit / revenue * 100
+	return &margin
 }
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"errors"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
 	"mobile-backend-go/models"
 	"net/http"
@@ -15,7 +16,7 @@ import (
 
 // CreateIngredient creates a new ingredient
 // @Summary Create a new ingredient
-// @Description Create a new ingredient with type and name
+// @Description Create a new ingredient with type and name, optionally with nutrition per 100 g: energy_kcal, and protein, fat, saturated_fat, carbohydrates, sugars and salt in grams. Global ingredients are shared by every workspace, so nutrition is stored as the nutrition override of the ingredient in the current workspace, also when the ingredient already exists.
 // @Tags Ingredients
 // @Security BearerAuth
 // @Accept  json
@@ -49,10 +50,17 @@ func CreateIngredient(c *gin.Context) {
 		return
 	}
 
+	// Nutrition only overrides the values of this workspace
+	nutrition := newIngredient.Nutrition
+	newIngredient.Nutrition = models.Nutrition{}
+
 	// Check name uniqueness
 	var existingIngredient models.Ingredient
 	if err := database.DB.Where("name = ?", newIngredient.Name).First(&existingIngredient).Error; err == nil {
 		workspaceIngredient, ensureErr := database.EnsureWorkspaceIngredient(database.DB, workspaceID, existingIngredient.ID)
+		if ensureErr == nil {
+			ensureErr = setWorkspaceNutritionOverride(database.DB, workspaceIngredient, nutrition)
+		}
 		if ensureErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link ingredient to workspace"})
 			return
@@ -64,6 +72,7 @@ func CreateIngredient(c *gin.Context) {
 			"existing_id":             existingIngredient.ID,
 			"workspace_ingredient_id": workspaceIngredient.ID,
 			"workspace_linked":        true,
+			"nutrition_override":      workspaceIngredient.NutritionOverride,
 		})
 		return
 	}
@@ -80,6 +89,9 @@ func CreateIngredient(c *gin.Context) {
 		if strings.Contains(err.Error(), "unique") || strings.Contains(err.Error(), "duplicate") {
 			if err := database.DB.Where("name = ?", newIngredient.Name).First(&existingIngredient).Error; err == nil {
 				workspaceIngredient, ensureErr := database.EnsureWorkspaceIngredient(database.DB, workspaceID, existingIngredient.ID)
+				if ensureErr == nil {
+					ensureErr = setWorkspaceNutritionOverride(database.DB, workspaceIngredient, nutrition)
+				}
 				if ensureErr != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link ingredient to workspace"})
 					return
@@ -91,6 +103,7 @@ func CreateIngredient(c *gin.Context) {
 					"existing_id":             existingIngredient.ID,
 					"workspace_ingredient_id": workspaceIngredient.ID,
 					"workspace_linked":        true,
+					"nutrition_override":      workspaceIngredient.NutritionOverride,
 				})
 				return
 			}
@@ -106,6 +119,9 @@ func CreateIngredient(c *gin.Context) {
 	}
 
 	workspaceIngredient, err := database.EnsureWorkspaceIngredient(tx, workspaceID, newIngredient.ID)
+	if err == nil {
+		err = setWorkspaceNutritionOverride(tx, workspaceIngredient, nutrition)
+	}
 	if err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link ingredient to workspace"})
@@ -122,11 +138,25 @@ func CreateIngredient(c *gin.Context) {
 		"updated_at":              newIngredient.UpdatedAt,
 		"type":                    newIngredient.Type,
 		"name":                    newIngredient.Name,
+		"nutrition_override":      workspaceIngredient.NutritionOverride,
 		"workspace_ingredient_id": workspaceIngredient.ID,
 		"workspace_linked":        true,
 	})
 }
 
+// setWorkspaceNutritionOverride replaces the nutrition override of a workspace ingredient with
+// nutrition, unless no nutrition value is set.
+func setWorkspaceNutritionOverride(db *gorm.DB, workspaceIngredient *models.WorkspaceIngredient, nutrition models.Nutrition) error {
+	if nutrition.IsEmpty() {
+		return nil
+	}
+	if err := db.Model(workspaceIngredient).Updates(database.NutritionOverrideUpdates(nutrition)).Error; err != nil {
+		return err
+	}
+	workspaceIngredient.NutritionOverride = nutrition
+	return nil
+}
+
 // CheckIngredientExists checks if ingredient exists by name
 // @Summary Check if ingredient exists
 // @Description Check if an ingredient with the given name already exists
@@ -271,6 +301,12 @@ func AddWorkspaceIngredient(c *gin.Context) {
 		return
 	}
 
+	var before map[string]interface{}
+	var previous models.WorkspaceIngredient
+	if err := database.DB.Where("workspace_id = ? AND ingredient_id = ?", workspaceID, requestData.IngredientID).First(&previous).Error; err == nil {
+		before = activitySnapshot(previous)
+	}
+
 	workspaceIngredient, err := database.EnsureWorkspaceIngredient(database.DB, workspaceID, requestData.IngredientID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -281,12 +317,18 @@ func AddWorkspaceIngredient(c *gin.Context) {
 		return
 	}
 
+	action := constants.AuditActionCreate
+	if before != nil {
+		action = constants.AuditActionUpdate
+	}
+	recordActivity(c, constants.AuditEntityWorkspaceIngredient, workspaceIngredient.ID, action, before, activitySnapshot(workspaceIngredient))
+
 	c.JSON(http.StatusCreated, workspaceIngredient)
 }
 
 // UpdateWorkspaceIngredient updates workspace ingredient metadata.
 // @Summary Update workspace ingredient
-// @Description Update workspace ingredient metadata in the current workspace
+// @Description Update workspace ingredient metadata in the current workspace. nutrition_override replaces all per 100 g nutrition overrides of the ingredient in this workspace; values left out fall back to the global ingredient.
 // @Tags Workspace Ingredients
 // @Security BearerAuth
 // @Accept  json
@@ -319,6 +361,8 @@ func UpdateWorkspaceIngredient(c *gin.Context) {
 		return
 	}
 
+	before := workspaceIngredientActivitySnapshot(workspaceIngredient)
+
 	updates := map[string]interface{}{}
 	if requestData.Active != nil {
 		updates["active"] = *requestData.Active
@@ -329,6 +373,11 @@ func UpdateWorkspaceIngredient(c *gin.Context) {
 	if requestData.Category != nil {
 		updates["category"] = strings.TrimSpace(*requestData.Category)
 	}
+	if requestData.NutritionOverride != nil {
+		for column, value := range database.NutritionOverrideUpdates(*requestData.NutritionOverride) {
+			updates[column] = value
+		}
+	}
 
 	if len(updates) > 0 {
 		if err := database.DB.Model(&workspaceIngredient).Updates(updates).Error; err != nil {
@@ -341,6 +390,7 @@ func UpdateWorkspaceIngredient(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityWorkspaceIngredient, workspaceIngredient.ID, constants.AuditActionUpdate, before, workspaceIngredientActivitySnapshot(workspaceIngredient))
 	c.JSON(http.StatusOK, workspaceIngredient)
 }
 
@@ -364,17 +414,20 @@ func DeleteWorkspaceIngredient(c *gin.Context) {
 		return
 	}
 
-	result := database.DB.Model(&models.WorkspaceIngredient{}).
-		Where("id = ? AND workspace_id = ?", workspaceIngredientID, workspaceID).
-		Update("active", false)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate workspace ingredient"})
+	var workspaceIngredient models.WorkspaceIngredient
+	if err := database.DB.Where("id = ? AND workspace_id = ?", workspaceIngredientID, workspaceID).First(&workspaceIngredient).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace ingredient not found"})
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace ingredient not found"})
+	before := activitySnapshot(workspaceIngredient)
+
+	if err := database.DB.Model(&workspaceIngredient).Update("active", false).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate workspace ingredient"})
 		return
 	}
+	workspaceIngredient.Active = false
+
+	recordActivity(c, constants.AuditEntityWorkspaceIngredient, workspaceIngredient.ID, constants.AuditActionDelete, before, activitySnapshot(workspaceIngredient))
 
 	c.JSON(http.StatusOK, gin.H{"message": "Workspace ingredient deactivated"})
 }
@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"mobile-backend-go/middleware"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jwksCacheControl lets clients cache the key set for 5 minutes. A new signing key must be
+// published at least this long before it starts signing.
+const jwksCacheControl = "public, max-age=300"
+
+// JWKSResponse is the JSON Web Key Set document.
+type JWKSResponse struct {
+	Keys []middleware.JWK `json:"keys"`
+}
+
+// GetJWKS returns the public keys that verify access tokens.
+// @Summary Get token verification keys
+// @Description Public keys (JWKS) other services use to verify access tokens. Tokens name their key in the kid header. HS256 tokens issued during the migration have no published key.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} JWKSResponse
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", jwksCacheControl)
+	c.JSON(http.StatusOK, JWKSResponse{Keys: middleware.PublicJWKS()})
+}
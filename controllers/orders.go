@@ -81,7 +81,7 @@ func GetOrders(c *gin.Context) {
 
 // AddOrder adds a new order
 // @Summary Add a new order
-// @Description Create a new order for the authenticated user
+// @Description Create a new order for the authenticated user. date accepts YYYY-MM-DD, the start of that day in the workspace time zone, or RFC 3339; it defaults to the current time.
 // @Tags Orders
 // @Security BearerAuth
 // @Accept  json
@@ -97,10 +97,10 @@ func AddOrder(c *gin.Context) {
 	workspaceID := c.MustGet("workspaceID").(uint)
 
 	var requestData struct {
-		ClientID uint      `json:"client_id" binding:"required"`
-		Date     time.Time `json:"date"`
-		Status   string    `json:"status"`
-		Comment  string    `json:"comment"`
+		ClientID uint   `json:"client_id" binding:"required"`
+		Date     string `json:"date"`
+		Status   string `json:"status"`
+		Comment  string `json:"comment"`
 		Items    []struct {
 			ProductID uint     `json:"product_id" binding:"required"`
 			Quantity  int      `json:"quantity" binding:"required,min=1"`
@@ -141,8 +141,14 @@ func AddOrder(c *gin.Context) {
 		}
 	}
 
+	settings, err := database.GetWorkspaceSettings(database.DB, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workspace settings"})
+		return
+	}
+
 	if requestData.Status == "" {
-		requestData.Status = constants.OrderStatusNew // Set default status
+		requestData.Status = settings.DefaultOrderStatus // Set workspace default status
 	}
 	if !constants.IsValidOrderStatus(requestData.Status) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order status"})
@@ -156,9 +162,10 @@ func AddOrder(c *gin.Context) {
 		return
 	}
 
-	orderDate := requestData.Date
-	if orderDate.IsZero() {
-		orderDate = time.Now()
+	orderDate, err := parseOrderDate(requestData.Date, workspaceLocation(settings))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order date"})
+		return
 	}
 
 	// Create order
@@ -235,12 +242,13 @@ func AddOrder(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityOrder, createdOrder.ID, constants.AuditActionCreate, nil, orderActivitySnapshot(createdOrder, createdOrder.Items))
 	c.JSON(http.StatusCreated, createdOrder)
 }
 
 // UpdateOrder updates an order
 // @Summary Update an order
-// @Description Update an order's details
+// @Description Update an order's details. date is read as in POST /api/orders; an omitted date keeps the order date.
 // @Tags Orders
 // @Security BearerAuth
 // @Accept  json
@@ -268,10 +276,10 @@ func UpdateOrder(c *gin.Context) {
 	}
 
 	var requestData struct {
-		ClientID uint      `json:"client_id" binding:"required"`
-		Date     time.Time `json:"date"`
-		Status   string    `json:"status"`
-		Comment  string    `json:"comment"`
+		ClientID uint   `json:"client_id" binding:"required"`
+		Date     string `json:"date"`
+		Status   string `json:"status"`
+		Comment  string `json:"comment"`
 		Items    []struct {
 			ProductID uint     `json:"product_id" binding:"required"`
 			Quantity  int      `json:"quantity" binding:"required,min=1"`
@@ -322,10 +330,23 @@ func UpdateOrder(c *gin.Context) {
 		return
 	}
 
+	settings, err := database.GetWorkspaceSettings(database.DB, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workspace settings"})
+		return
+	}
+
+	before := orderActivitySnapshot(existingOrder, existingOrder.Items)
+
 	// Update order fields
 	existingOrder.ClientID = requestData.ClientID
-	if !requestData.Date.IsZero() {
-		existingOrder.Date = requestData.Date
+	if requestData.Date != "" {
+		orderDate, err := parseOrderDate(requestData.Date, workspaceLocation(settings))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order date"})
+			return
+		}
+		existingOrder.Date = orderDate
 	} else if existingOrder.Date.IsZero() {
 		existingOrder.Date = existingOrder.CreatedAt
 	}
@@ -390,6 +411,8 @@ func UpdateOrder(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityOrder, existingOrder.ID, constants.AuditActionUpdate, before, orderActivitySnapshot(existingOrder, newOrderItems))
+
 	// Return success response
 	c.JSON(http.StatusOK, gin.H{"message": "Order updated successfully"})
 }
@@ -435,16 +458,20 @@ func UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	result := database.DB.Model(&models.Order{}).Where("id = ? AND workspace_id = ?", orderID, workspaceID).Update("status", requestBody.Status)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
+	var order models.Order
+	if err := database.DB.Where("id = ? AND workspace_id = ?", orderID, workspaceID).First(&order).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	before := activitySnapshot(order)
+
+	if err := database.DB.Model(&order).Update("status", requestBody.Status).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
 		return
 	}
+	order.Status = requestBody.Status
 
+	recordActivity(c, constants.AuditEntityOrder, order.ID, constants.AuditActionUpdate, before, activitySnapshot(order))
 	c.JSON(http.StatusOK, gin.H{"message": "Order status updated successfully"})
 }
 
@@ -469,7 +496,7 @@ func DeleteOrder(c *gin.Context) {
 	}
 
 	var order models.Order
-	if err := database.DB.Where("id = ? AND workspace_id = ?", orderID, workspaceID).First(&order).Error; err != nil {
+	if err := database.DB.Where("id = ? AND workspace_id = ?", orderID, workspaceID).Preload("Items").First(&order).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
@@ -479,5 +506,23 @@ func DeleteOrder(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityOrder, order.ID, constants.AuditActionDelete, orderActivitySnapshot(order, order.Items), nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Order deleted successfully"})
 }
+
+// parseOrderDate reads the date of an order. An empty date is the current time in location and a
+// plain YYYY-MM-DD date is the start of that day in location.
+func parseOrderDate(value string, location *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Now().In(location), nil
+	}
+	parsed, dateOnly, err := parseActivityTime(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if dateOnly {
+		return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, location), nil
+	}
+	return parsed, nil
+}
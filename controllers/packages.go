@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"github.com/gin-gonic/gin"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
 	"mobile-backend-go/models"
 	"net/http"
@@ -64,5 +65,7 @@ func AddPackage(c *gin.Context) {
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityPackage, newPackage.ID, constants.AuditActionCreate, nil, activitySnapshot(newPackage))
+
 	c.JSON(http.StatusCreated, newPackage)
 }
@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/notifications"
+	"mobile-backend-go/utils"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const oneTimeCodeDigits = 8
+
+// UpdateEmail sets the email address of the authenticated user and sends a verification code to it.
+// @Summary Set email address
+// @Description Set or replace the email address of the authenticated user. The address stays unverified, and cannot be used for password reset, until the code sent to it is confirmed.
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.EmailUpdateDTO true "Email address"
+// @Success 200 {object} map[string]string "Verification code sent"
+// @Failure 400 {object} map[string]string "Invalid email"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Email already in use"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/email [put]
+func UpdateEmail(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var payload models.EmailUpdateDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	email, ok := normalizeEmail(payload.Email)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.Email != nil && *user.Email == email && user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Email already verified", "email": email})
+		return
+	}
+
+	if !emailAvailable(email, user.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+		return
+	}
+
+	err := database.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": nil,
+	}).Error
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+			return
+		}
+		log.Printf("Failed to update email of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update email"})
+		return
+	}
+
+	if err := sendOneTimeCode(user.ID, email, constants.OneTimeCodeEmailVerification, database.EmailVerificationCodeTTL); err != nil {
+		log.Printf("Failed to send email verification code to user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent", "email": email})
+}
+
+// VerifyEmail confirms the email address of the authenticated user.
+// @Summary Verify email address
+// @Description Confirm the email address of the authenticated user with the code sent to it.
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.EmailVerifyDTO true "Verification code"
+// @Success 200 {object} map[string]string "Email verified"
+// @Failure 400 {object} map[string]string "Invalid or expired code"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/email/verify [post]
+func VerifyEmail(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var payload models.EmailVerifyDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.Email == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No email address to verify"})
+		return
+	}
+
+	code, err := database.ConsumeOneTimeCode(database.DB, user.ID, constants.OneTimeCodeEmailVerification, utils.HashToken(strings.TrimSpace(payload.Code)))
+	if err == nil && code.Email != *user.Email {
+		err = database.ErrOneTimeCodeInvalid
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrOneTimeCodeInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired code"})
+			return
+		}
+		log.Printf("Failed to verify email of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("email_verified_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+	recordSecurityEvent(c, user.ID, constants.SecurityEventEmailVerified)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified", "email": *user.Email})
+}
+
+// RequestPasswordReset sends a password reset code to a verified email address.
+// @Summary Request password reset
+// @Description Send a single-use password reset code to the given address if it is the verified email of a user. The response is the same whether or not the address is registered.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.PasswordResetRequestDTO true "Email address"
+// @Success 202 {object} map[string]string "Reset code sent if the email is registered"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Router /api/auth/password-reset/request [post]
+func RequestPasswordReset(c *gin.Context) {
+	var payload models.PasswordResetRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accepted := gin.H{"message": "If the email is registered, a reset code has been sent"}
+	email, ok := normalizeEmail(payload.Email)
+	if !ok {
+		c.JSON(http.StatusAccepted, accepted)
+		return
+	}
+
+	user, found, err := findUserByVerifiedEmail(email)
+	if err != nil {
+		log.Printf("Failed to look up password reset email: %v", err)
+	}
+	if found {
+		if err := sendOneTimeCode(user.ID, email, constants.OneTimeCodePasswordReset, database.PasswordResetCodeTTL); err != nil {
+			log.Printf("Failed to send password reset code to user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusAccepted, accepted)
+}
+
+// ConfirmPasswordReset sets a new password with a password reset code.
+// @Summary Confirm password reset
+// @Description Set a new password with the code sent by the reset request. The code can be used once; after 5 wrong codes a new one must be requested. All sessions of the user are logged out.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.PasswordResetConfirmDTO true "Reset code and new password"
+// @Success 200 {object} map[string]string "Password reset"
+// @Failure 400 {object} map[string]string "Invalid or expired code"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/auth/password-reset/confirm [post]
+func ConfirmPasswordReset(c *gin.Context) {
+	var payload models.PasswordResetConfirmDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invalidCode := gin.H{"error": "Invalid or expired code"}
+	email, ok := normalizeEmail(payload.Email)
+	if !ok {
+		c.JSON(http.StatusBadRequest, invalidCode)
+		return
+	}
+	user, found, err := findUserByVerifiedEmail(email)
+	if err != nil {
+		log.Printf("Failed to look up password reset email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusBadRequest, invalidCode)
+		return
+	}
+
+	code, err := database.ConsumeOneTimeCode(database.DB, user.ID, constants.OneTimeCodePasswordReset, utils.HashToken(strings.TrimSpace(payload.Code)))
+	if err == nil && code.Email != email {
+		err = database.ErrOneTimeCodeInvalid
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrOneTimeCodeInvalid) {
+			c.JSON(http.StatusBadRequest, invalidCode)
+			return
+		}
+		log.Printf("Failed to check password reset code of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(payload.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		_, err := database.InvalidateUserTokens(tx, user.ID, 0, database.SessionRevokedPasswordReset)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to reset password of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if err := database.ResetFailedLogins(database.DB, user.Username); err != nil {
+		log.Printf("Failed to reset failed logins of user %d: %v", user.ID, err)
+	}
+	recordSecurityEvent(c, user.ID, constants.SecurityEventPasswordReset)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+// sendOneTimeCode issues a new code for purpose and sends it to email.
+func sendOneTimeCode(userID uint, email string, purpose string, ttl time.Duration) error {
+	code, err := utils.GenerateNumericCode(oneTimeCodeDigits)
+	if err != nil {
+		return err
+	}
+	if err := database.IssueOneTimeCode(database.DB, userID, purpose, email, utils.HashToken(code), ttl); err != nil {
+		return err
+	}
+
+	message := notifications.Message{To: email}
+	switch purpose {
+	case constants.OneTimeCodePasswordReset:
+		message.Subject = "Your BatchVault password reset code"
+		message.Body = fmt.Sprintf("Your password reset code is %s.\nIt expires in %d minutes. If you did not ask to reset your password, ignore this email.", code, int(ttl.Minutes()))
+	default:
+		message.Subject = "Confirm your BatchVault email"
+		message.Body = fmt.Sprintf("Your email confirmation code is %s.\nIt expires in %d hours.", code, int(ttl.Hours()))
+	}
+	return notifications.Default.Send(message)
+}
+
+func findUserByVerifiedEmail(email string) (models.User, bool, error) {
+	var user models.User
+	err := database.DB.Where("email = ? AND email_verified_at IS NOT NULL", email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, false, nil
+	}
+	return user, err == nil, err
+}
+
+func emailAvailable(email string, userID uint) bool {
+	var count int64
+	database.DB.Unscoped().Model(&models.User{}).Where("email = ? AND id <> ?", email, userID).Count(&count)
+	return count == 0
+}
+
+// normalizeEmail trims and lowercases an email address and reports whether it is valid.
+func normalizeEmail(value string) (string, bool) {
+	email := strings.ToLower(strings.TrimSpace(value))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", false
+	}
+	return email, true
+}
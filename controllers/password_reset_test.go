@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mobile-backend-go/notifications"
+)
+
+type recordingNotifier struct {
+	messages []notifications.Message
+}
+
+func (notifier *recordingNotifier) Send(message notifications.Message) error {
+	notifier.messages = append(notifier.messages, message)
+	return nil
+}
+
+var oneTimeCodePattern = regexp.MustCompile(`\b\d{8}\b`)
+
+func (notifier *recordingNotifier) lastCode(t *testing.T, to string) string {
+	t.Helper()
+
+	if len(notifier.messages) == 0 {
+		t.Fatal("no message was sent")
+	}
+	message := notifier.messages[len(notifier.messages)-1]
+	if message.To != to {
+		t.Fatalf("last message to %q, want %q", message.To, to)
+	}
+	code := oneTimeCodePattern.FindString(message.Body)
+	if code == "" {
+		t.Fatalf("no code in message %q", message.Body)
+	}
+	return code
+}
+
+func useRecordingNotifier(t *testing.T) *recordingNotifier {
+	t.Helper()
+
+	notifier := &recordingNotifier{}
+	previous := notifications.Default
+	notifications.Default = notifier
+	t.Cleanup(func() { notifications.Default = previous })
+	return notifier
+}
+
+func TestPasswordResetWithVerifiedEmail(t *testing.T) {
+	router := setupAuthTest(t)
+	notifier := useRecordingNotifier(t)
+	login := postAuthJSON(t, router, "/auth/login", map[string]any{"username": "auth-user", "password": authTestPassword}, http.StatusOK)
+
+	// Unverified addresses cannot be used for a reset.
+	if response := sendAuthJSON(router, http.MethodPut, "/profile/email", login.Token, map[string]any{"email": " Baker@Example.com "}); response.Code != http.StatusOK {
+		t.Fatalf("set email status = %d body = %s", response.Code, response.Body.String())
+	}
+	verificationCode := notifier.lastCode(t, "baker@example.com")
+	sendAuthJSON(router, http.MethodPost, "/auth/password-reset/request", "", map[string]any{"email": "baker@example.com"})
+	if len(notifier.messages) != 1 {
+		t.Fatalf("reset for unverified email sent %d messages, want none", len(notifier.messages)-1)
+	}
+
+	if response := sendAuthJSON(router, http.MethodPost, "/profile/email/verify", login.Token, map[string]any{"code": "00000000"}); response.Code != http.StatusBadRequest {
+		t.Fatalf("wrong verification code status = %d", response.Code)
+	}
+	if response := sendAuthJSON(router, http.MethodPost, "/profile/email/verify", login.Token, map[string]any{"code": verificationCode}); response.Code != http.StatusOK {
+		t.Fatalf("verify email status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	if response := sendAuthJSON(router, http.MethodPost, "/auth/password-reset/request", "", map[string]any{"email": "nobody@example.com"}); response.Code != http.StatusAccepted {
+		t.Fatalf("unknown email reset status = %d, want 202", response.Code)
+	}
+	if response := sendAuthJSON(router, http.MethodPost, "/auth/password-reset/request", "", map[string]any{"email": "BAKER@example.com"}); response.Code != http.StatusAccepted {
+		t.Fatalf("reset request status = %d, want 202", response.Code)
+	}
+	resetCode := notifier.lastCode(t, "baker@example.com")
+
+	confirm := map[string]any{"email": "baker@example.com", "code": "12345678", "new_password": "brand-new-password"}
+	if response := sendAuthJSON(router, http.MethodPost, "/auth/password-reset/confirm", "", confirm); response.Code != http.StatusBadRequest {
+		t.Fatalf("wrong reset code status = %d", response.Code)
+	}
+	confirm["code"] = resetCode
+	if response := sendAuthJSON(router, http.MethodPost, "/auth/password-reset/confirm", "", confirm); response.Code != http.StatusOK {
+		t.Fatalf("confirm reset status = %d body = %s", response.Code, response.Body.String())
+	}
+	if response := sendAuthJSON(router, http.MethodPost, "/auth/password-reset/confirm", "", confirm); response.Code != http.StatusBadRequest {
+		t.Fatalf("reused reset code status = %d, want 400", response.Code)
+	}
+
+	if status := getWithToken(router, login.Token); status != http.StatusUnauthorized {
+		t.Fatalf("access token after reset status = %d, want 401", status)
+	}
+	postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": login.RefreshToken}, http.StatusUnauthorized)
+	postAuthJSON(t, router, "/auth/login", map[string]any{"username": "auth-user", "password": authTestPassword}, http.StatusUnauthorized)
+	postAuthJSON(t, router, "/auth/login", map[string]any{"username": "auth-user", "password": "brand-new-password"}, http.StatusOK)
+}
+
+func TestPasswordResetCodeIsBurntAfterTooManyAttempts(t *testing.T) {
+	router := setupAuthTest(t)
+	notifier := useRecordingNotifier(t)
+	login := postAuthJSON(t, router, "/auth/login", map[string]any{"username": "auth-user", "password": authTestPassword}, http.StatusOK)
+
+	sendAuthJSON(router, http.MethodPut, "/profile/email", login.Token, map[string]any{"email": "baker@example.com"})
+	sendAuthJSON(router, http.MethodPost, "/profile/email/verify", login.Token, map[string]any{"code": notifier.lastCode(t, "baker@example.com")})
+	sendAuthJSON(router, http.MethodPost, "/auth/password-reset/request", "", map[string]any{"email": "baker@example.com"})
+	resetCode := notifier.lastCode(t, "baker@example.com")
+
+	confirm := map[string]any{"email": "baker@example.com", "code": "wrong", "new_password": "brand-new-password"}
+	for attempt := 0; attempt < 5; attempt++ {
+		sendAuthJSON(router, http.MethodPost, "/auth/password-reset/confirm", "", confirm)
+	}
+	confirm["code"] = resetCode
+	if response := sendAuthJSON(router, http.MethodPost, "/auth/password-reset/confirm", "", confirm); response.Code != http.StatusBadRequest {
+		t.Fatalf("burnt code status = %d, want 400", response.Code)
+	}
+}
+
+func sendAuthJSON(router *gin.Engine, method string, target string, token string, body any) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	request := httptest.NewRequest(method, target, bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response
+}
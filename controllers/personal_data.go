@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	personalDataFormatJSON = "json"
+	personalDataFormatZIP  = "zip"
+)
+
+// ExportPersonalData returns a copy of the data stored about the authenticated user.
+// @Summary Export personal data
+// @Description Export the profile, workspace and account memberships, sessions, security events and the personal workspace bundle of the authenticated user. With format=zip every section is a separate JSON file in a ZIP archive.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Produce application/zip
+// @Param format query string false "Export format: json (default) or zip"
+// @Success 200 {object} models.PersonalDataExport
+// @Failure 400 {object} map[string]string "Unsupported format"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/export [get]
+func ExportPersonalData(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	format := c.DefaultQuery("format", personalDataFormatJSON)
+	if format != personalDataFormatJSON && format != personalDataFormatZIP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+
+	export, err := database.ExportPersonalData(database.DB, userID)
+	if err != nil {
+		log.Printf("Failed to export personal data of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export personal data"})
+		return
+	}
+
+	filename := fmt.Sprintf("personal-data-%d", userID)
+	if format == personalDataFormatJSON {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	archive, err := personalDataArchive(export)
+	if err != nil {
+		log.Printf("Failed to build personal data archive of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export personal data"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// DeleteAccount deletes the authenticated user's account.
+// @Summary Delete account
+// @Description Permanently delete the authenticated user's account. Requires the password and, with two-factor authentication enabled, a TOTP or recovery code. The personal workspace is closed and its clients anonymized; shared workspaces where the user is the last owner pass to the member with the highest role who joined first, or are closed when no other member is left. Sessions, second factors and security events are deleted and every token stops working.
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.AccountDeleteDTO true "Password and second factor"
+// @Success 200 {object} models.AccountDeletionReport
+// @Failure 400 {object} map[string]string "Bad request or invalid code"
+// @Failure 401 {object} map[string]string "Invalid password"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile [delete]
+func DeleteAccount(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var payload models.AccountDeleteDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !utils.CheckPassword(user.Password, payload.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	twoFactorEnabled, err := database.UserHasTwoFactor(database.DB, userID)
+	if err != nil {
+		log.Printf("Failed to load two-factor status of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	if twoFactorEnabled {
+		valid, _, err := checkSecondFactor(userID, payload.Code)
+		if err != nil {
+			log.Printf("Failed to check second factor of user %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+			return
+		}
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+			return
+		}
+	}
+
+	// The password of a deleted account is a hash of a discarded random value, so nobody can log in
+	unusablePassword, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		log.Printf("Failed to generate password for deleted user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	unusableHash, err := utils.HashPassword(unusablePassword)
+	if err != nil {
+		log.Printf("Failed to hash password for deleted user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	report, err := database.DeleteUserAccount(database.DB, userID, unusableHash)
+	if err != nil {
+		log.Printf("Failed to delete account of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	log.Printf("Deleted account of user %d: %d workspaces transferred, %d closed, %d left",
+		userID, len(report.TransferredWorkspaces), len(report.ClosedWorkspaces), len(report.LeftWorkspaces))
+
+	c.JSON(http.StatusOK, report)
+}
+
+// personalDataArchive writes every section of export to its own JSON file in a ZIP archive.
+func personalDataArchive(export models.PersonalDataExport) ([]byte, error) {
+	type archiveFile struct {
+		name    string
+		content interface{}
+	}
+	files := []archiveFile{
+		{"export.json", gin.H{"format_version": export.FormatVersion, "exported_at": export.ExportedAt}},
+		{"profile.json", gin.H{"profile": export.Profile, "two_factor_enabled": export.TwoFactorEnabled}},
+		{"workspace_memberships.json", export.WorkspaceMemberships},
+		{"account_memberships.json", export.AccountMemberships},
+		{"sessions.json", export.Sessions},
+		{"security_events.json", export.SecurityEvents},
+	}
+	if export.PersonalWorkspace != nil {
+		files = append(files, archiveFile{"personal_workspace.json", export.PersonalWorkspace})
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+)
+
+type accountDeletionFixture struct {
+	User              models.User
+	Viewer            models.User
+	Manager           models.User
+	PersonalWorkspace models.Workspace
+	// TeamWorkspace is owned by User alone, with a viewer who joined before the manager.
+	TeamWorkspace models.Workspace
+	// SoloWorkspace has no other members.
+	SoloWorkspace models.Workspace
+	// GuestWorkspace is owned by Manager; User is an operator.
+	GuestWorkspace models.Workspace
+	Account        models.Account
+	Client         models.Client
+}
+
+func setupAccountDeletionTest(t *testing.T) accountDeletionFixture {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Account{},
+		&models.AccountMember{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.WorkspaceInvitation{},
+		&models.WorkspaceSettings{},
+		&models.APIKey{},
+		&models.Ingredient{},
+		&models.WorkspaceIngredient{},
+		&models.Price{},
+		&models.Recipe{},
+		&models.RecipeIngredient{},
+		&models.Package{},
+		&models.Product{},
+		&models.ProductOption{},
+		&models.Client{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.AuthSession{},
+		&models.RefreshToken{},
+		&models.LoginThrottle{},
+		&models.SecurityEvent{},
+		&models.OneTimeCode{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
+		&models.AuditLog{},
+	); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	database.DB = db
+
+	hashedPassword, err := utils.HashPassword(authTestPassword)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	email := "leaving@example.com"
+	user := models.User{Username: "leaving-user", Password: hashedPassword, Email: &email}
+	viewer := models.User{Username: "team-viewer", Password: "hashed"}
+	manager := models.User{Username: "team-manager", Password: "hashed"}
+	for _, created := range []*models.User{&user, &viewer, &manager} {
+		if err := db.Create(created).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	personalMember, err := database.EnsurePersonalWorkspaceForUser(db, user.ID)
+	if err != nil {
+		t.Fatalf("create personal workspace: %v", err)
+	}
+	teamMember, err := database.CreateBusinessWorkspace(db, user.ID, "Team kitchen", "")
+	if err != nil {
+		t.Fatalf("create team workspace: %v", err)
+	}
+	soloMember, err := database.CreateBusinessWorkspace(db, user.ID, "Solo kitchen", "")
+	if err != nil {
+		t.Fatalf("create solo workspace: %v", err)
+	}
+	guestMember, err := database.CreateBusinessWorkspace(db, manager.ID, "Guest kitchen", "")
+	if err != nil {
+		t.Fatalf("create guest workspace: %v", err)
+	}
+	members := []models.WorkspaceMember{
+		{WorkspaceID: teamMember.WorkspaceID, UserID: viewer.ID, Role: constants.WorkspaceRoleViewer},
+		{WorkspaceID: teamMember.WorkspaceID, UserID: manager.ID, Role: constants.WorkspaceRoleManager},
+		{WorkspaceID: guestMember.WorkspaceID, UserID: user.ID, Role: constants.WorkspaceRoleOperator},
+	}
+	if err := db.Create(&members).Error; err != nil {
+		t.Fatalf("create memberships: %v", err)
+	}
+
+	account, err := database.CreateAccount(db, user.ID, "Leaving Ltd")
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if _, err := database.AttachWorkspaceToAccount(db, account.ID, soloMember.WorkspaceID); err != nil {
+		t.Fatalf("attach workspace: %v", err)
+	}
+
+	client := models.Client{Name: "Ana", Surname: "Client", Phone: "+381600000000", UserID: user.ID, WorkspaceID: &personalMember.WorkspaceID}
+	if err := db.Create(&client).Error; err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	if err := db.Create(&models.SecurityEvent{UserID: user.ID, Type: constants.SecurityEventLoginSucceeded}).Error; err != nil {
+		t.Fatalf("create security event: %v", err)
+	}
+	auditLogs := []models.AuditLog{
+		{WorkspaceID: personalMember.WorkspaceID, UserID: user.ID, EntityType: constants.AuditEntityClient, EntityID: client.ID, Action: constants.AuditActionCreate,
+			Changes: models.AuditChanges{"phone": {After: client.Phone}}},
+		{WorkspaceID: teamMember.WorkspaceID, UserID: user.ID, EntityType: constants.AuditEntityClient, EntityID: client.ID, Action: constants.AuditActionDelete},
+	}
+	if err := db.Create(&auditLogs).Error; err != nil {
+		t.Fatalf("create audit logs: %v", err)
+	}
+
+	return accountDeletionFixture{
+		User:              user,
+		Viewer:            viewer,
+		Manager:           manager,
+		PersonalWorkspace: personalMember.Workspace,
+		TeamWorkspace:     teamMember.Workspace,
+		SoloWorkspace:     soloMember.Workspace,
+		GuestWorkspace:    guestMember.Workspace,
+		Account:           account,
+		Client:            client,
+	}
+}
+
+func runProfileRequest(userID uint, handler gin.HandlerFunc, method string, target string, body any) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(method, "/profile/*path", func(c *gin.Context) {
+		c.Set("userID", userID)
+		handler(c)
+	})
+
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	request := httptest.NewRequest(method, target, bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestExportPersonalDataAsJSONAndZIP(t *testing.T) {
+	fixture := setupAccountDeletionTest(t)
+
+	response := runProfileRequest(fixture.User.ID, ExportPersonalData, http.MethodGet, "/profile/export", nil)
+	if response.Code != http.StatusOK {
+		t.Fatalf("export status = %d body = %s", response.Code, response.Body.String())
+	}
+	var export models.PersonalDataExport
+	if err := json.Unmarshal(response.Body.Bytes(), &export); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if export.Profile.Username != "leaving-user" || export.Profile.Email == nil || *export.Profile.Email != "leaving@example.com" {
+		t.Fatalf("profile = %+v", export.Profile)
+	}
+	if len(export.WorkspaceMemberships) != 4 || len(export.AccountMemberships) != 1 || len(export.SecurityEvents) != 1 {
+		t.Fatalf("export = %d workspaces, %d accounts, %d events", len(export.WorkspaceMemberships), len(export.AccountMemberships), len(export.SecurityEvents))
+	}
+	if export.PersonalWorkspace == nil || len(export.PersonalWorkspace.Clients) != 1 || export.PersonalWorkspace.Clients[0].Phone != "+381600000000" {
+		t.Fatalf("personal workspace = %+v", export.PersonalWorkspace)
+	}
+
+	response = runProfileRequest(fixture.User.ID, ExportPersonalData, http.MethodGet, "/profile/export?format=zip", nil)
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("zip export status = %d content type = %q", response.Code, response.Header().Get("Content-Type"))
+	}
+	archive, err := zip.NewReader(bytes.NewReader(response.Body.Bytes()), int64(response.Body.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	names := make([]string, 0, len(archive.File))
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	want := []string{"account_memberships.json", "export.json", "personal_workspace.json", "profile.json", "security_events.json", "sessions.json", "workspace_memberships.json"}
+	if len(names) != len(want) {
+		t.Fatalf("zip files = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("zip files = %v, want %v", names, want)
+		}
+	}
+
+	response = runProfileRequest(fixture.User.ID, ExportPersonalData, http.MethodGet, "/profile/export?format=xml", nil)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("unsupported format status = %d, want 400", response.Code)
+	}
+}
+
+func TestDeleteAccountTransfersOrClosesWorkspaces(t *testing.T) {
+	fixture := setupAccountDeletionTest(t)
+	db := database.DB
+
+	deletedClient := models.Client{Name: "Bora", Surname: "Client", Phone: "+381600000001", Address: "Main street 1", UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID}
+	if err := db.Create(&deletedClient).Error; err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	if err := db.Delete(&deletedClient).Error; err != nil {
+		t.Fatalf("delete client: %v", err)
+	}
+	soloClient := models.Client{Name: "Cana", Surname: "Client", Phone: "+381600000002", UserID: fixture.User.ID, WorkspaceID: &fixture.SoloWorkspace.ID}
+	if err := db.Create(&soloClient).Error; err != nil {
+		t.Fatalf("create solo client: %v", err)
+	}
+	soloLog := models.AuditLog{WorkspaceID: fixture.SoloWorkspace.ID, UserID: fixture.User.ID, EntityType: constants.AuditEntityClient, EntityID: soloClient.ID, Action: constants.AuditActionCreate}
+	if err := db.Create(&soloLog).Error; err != nil {
+		t.Fatalf("create solo audit log: %v", err)
+	}
+
+	response := runProfileRequest(fixture.User.ID, DeleteAccount, http.MethodDelete, "/profile/", map[string]any{"password": "wrong-password"})
+	if response.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password status = %d, want 401", response.Code)
+	}
+
+	response = runProfileRequest(fixture.User.ID, DeleteAccount, http.MethodDelete, "/profile/", map[string]any{"password": authTestPassword})
+	if response.Code != http.StatusOK {
+		t.Fatalf("delete status = %d body = %s", response.Code, response.Body.String())
+	}
+	var report models.AccountDeletionReport
+	if err := json.Unmarshal(response.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if len(report.TransferredWorkspaces) != 1 || report.TransferredWorkspaces[0].WorkspaceID != fixture.TeamWorkspace.ID ||
+		report.TransferredWorkspaces[0].NewOwnerUserID == nil || *report.TransferredWorkspaces[0].NewOwnerUserID != fixture.Manager.ID {
+		t.Fatalf("transferred = %+v, want team workspace to the manager", report.TransferredWorkspaces)
+	}
+	if len(report.ClosedWorkspaces) != 2 || len(report.LeftWorkspaces) != 1 || report.LeftWorkspaces[0].WorkspaceID != fixture.GuestWorkspace.ID {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(report.ClosedAccountIDs) != 1 || report.ClosedAccountIDs[0] != fixture.Account.ID {
+		t.Fatalf("closed accounts = %v", report.ClosedAccountIDs)
+	}
+
+	var deleted models.User
+	if err := db.Unscoped().First(&deleted, fixture.User.ID).Error; err != nil {
+		t.Fatalf("load deleted user: %v", err)
+	}
+	if !deleted.DeletedAt.Valid || deleted.Email != nil || deleted.Username == "leaving-user" || utils.CheckPassword(deleted.Password, authTestPassword) {
+		t.Fatalf("deleted user = %+v, want anonymized", deleted)
+	}
+
+	var managerMember models.WorkspaceMember
+	if err := db.Where("workspace_id = ? AND user_id = ?", fixture.TeamWorkspace.ID, fixture.Manager.ID).First(&managerMember).Error; err != nil || managerMember.Role != constants.WorkspaceRoleOwner {
+		t.Fatalf("manager membership = %+v err = %v, want owner", managerMember, err)
+	}
+	for _, workspaceID := range []uint{fixture.PersonalWorkspace.ID, fixture.SoloWorkspace.ID} {
+		if err := db.First(&models.Workspace{}, workspaceID).Error; err == nil {
+			t.Fatalf("workspace %d is still open", workspaceID)
+		}
+	}
+	var solo models.Workspace
+	if err := db.Unscoped().First(&solo, fixture.SoloWorkspace.ID).Error; err != nil || solo.AccountID != nil {
+		t.Fatalf("solo workspace = %+v err = %v, want detached from closed account", solo, err)
+	}
+
+	var client models.Client
+	if err := db.Unscoped().First(&client, fixture.Client.ID).Error; err != nil {
+		t.Fatalf("load client: %v", err)
+	}
+	if client.Phone != "" || client.Name == "Ana" || !client.DeletedAt.Valid {
+		t.Fatalf("client = %+v, want anonymized", client)
+	}
+	var earlierClient models.Client
+	if err := db.Unscoped().First(&earlierClient, deletedClient.ID).Error; err != nil {
+		t.Fatalf("load deleted client: %v", err)
+	}
+	if earlierClient.Phone != "" || earlierClient.Address != "" || earlierClient.Name == "Bora" {
+		t.Fatalf("client deleted before = %+v, want anonymized", earlierClient)
+	}
+	var closedSoloClient models.Client
+	if err := db.Unscoped().First(&closedSoloClient, soloClient.ID).Error; err != nil || closedSoloClient.Phone != "" || closedSoloClient.Name == "Cana" {
+		t.Fatalf("solo workspace client = %+v err = %v, want anonymized", closedSoloClient, err)
+	}
+
+	var remaining int64
+	db.Model(&models.WorkspaceMember{}).Where("user_id = ?", fixture.User.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Fatalf("memberships after deletion = %d, want 0", remaining)
+	}
+	db.Model(&models.SecurityEvent{}).Where("user_id = ?", fixture.User.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Fatalf("security events after deletion = %d, want 0", remaining)
+	}
+	db.Model(&models.AuditLog{}).Where("workspace_id IN ?", []uint{fixture.PersonalWorkspace.ID, fixture.SoloWorkspace.ID}).Count(&remaining)
+	if remaining != 0 {
+		t.Fatalf("closed workspace audit logs after deletion = %d, want 0", remaining)
+	}
+	db.Model(&models.AuditLog{}).Where("workspace_id = ?", fixture.TeamWorkspace.ID).Count(&remaining)
+	if remaining != 1 {
+		t.Fatalf("team workspace audit logs after deletion = %d, want 1", remaining)
+	}
+}
+
+func TestDeleteAccountRequiresSecondFactor(t *testing.T) {
+	fixture := setupAccountDeletionTest(t)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+	now := time.Now()
+	if err := database.StartTwoFactorEnrollment(database.DB, fixture.User.ID, secret); err != nil {
+		t.Fatalf("start enrollment: %v", err)
+	}
+	if err := database.EnableTwoFactor(database.DB, fixture.User.ID, utils.TOTPStep(now)-1, nil); err != nil {
+		t.Fatalf("enable two-factor: %v", err)
+	}
+
+	response := runProfileRequest(fixture.User.ID, DeleteAccount, http.MethodDelete, "/profile/", map[string]any{"password": authTestPassword})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("delete without code status = %d, want 400", response.Code)
+	}
+
+	code, err := utils.TOTPCode(secret, now)
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	response = runProfileRequest(fixture.User.ID, DeleteAccount, http.MethodDelete, "/profile/", map[string]any{"password": authTestPassword, "code": code})
+	if response.Code != http.StatusOK {
+		t.Fatalf("delete with code status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	var remaining int64
+	database.DB.Model(&models.TwoFactor{}).Where("user_id = ?", fixture.User.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Fatalf("two-factor rows after deletion = %d, want 0", remaining)
+	}
+}
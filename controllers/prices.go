@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"log"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
 	"mobile-backend-go/models"
 	"net/http"
@@ -86,6 +87,7 @@ func AddPrice(c *gin.Context) {
 	if err := database.DB.Preload("Ingredient").First(&newPrice, newPrice.ID).Error; err != nil {
 		log.Printf("Failed to load price with ingredient: %v", err)
 	}
+	recordActivity(c, constants.AuditEntityPrice, newPrice.ID, constants.AuditActionCreate, nil, activitySnapshot(newPrice))
 
 	c.JSON(http.StatusCreated, newPrice)
 }
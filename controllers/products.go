@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"fmt"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
 	"mobile-backend-go/models"
 	"net/http"
@@ -43,7 +44,7 @@ func validateRecipeWorkspace(recipeIDs []uint, workspaceID uint) error {
 
 // GetProducts returns a list of products
 // @Summary Get list of products
-// @Description Get all products available
+// @Description Get all products available; every product option carries the nutrition of its recipe
 // @Tags Products
 // @Security BearerAuth
 // @Produce  json
@@ -62,13 +63,14 @@ func GetProducts(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
 		return
 	}
+	attachProductNutrition(workspaceID, products)
 
 	c.JSON(http.StatusOK, products)
 }
 
 // GetProductByID returns a product by ID
 // @Summary Get product by ID
-// @Description Get a specific product by its ID
+// @Description Get a specific product by its ID; every product option carries the nutrition of its recipe
 // @Tags Products
 // @Security BearerAuth
 // @Produce  json
@@ -99,6 +101,9 @@ func GetProductByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
+	products := []models.Product{product}
+	attachProductNutrition(workspaceID, products)
+	product = products[0]
 
 	c.JSON(http.StatusOK, product)
 }
@@ -226,6 +231,7 @@ func CreateProduct(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load created product"})
 		return
 	}
+	recordActivity(c, constants.AuditEntityProduct, createdProduct.ID, constants.AuditActionCreate, nil, productActivitySnapshot(createdProduct))
 
 	// Return created product with full information
 	c.JSON(http.StatusCreated, createdProduct)
@@ -311,6 +317,14 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	var previousOptions []models.ProductOption
+	if err := database.DB.Where("product_id = ?", existingProduct.ID).Find(&previousOptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product options"})
+		return
+	}
+	previousProduct := existingProduct
+	previousProduct.Options = previousOptions
+
 	// Update product fields
 	existingProduct.Name = requestData.Name
 	existingProduct.Description = requestData.Description
@@ -374,6 +388,7 @@ func UpdateProduct(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load updated product"})
 		return
 	}
+	recordActivity(c, constants.AuditEntityProduct, updatedProduct.ID, constants.AuditActionUpdate, productActivitySnapshot(previousProduct), productActivitySnapshot(updatedProduct))
 
 	// Return updated product with full information
 	c.JSON(http.StatusOK, updatedProduct)
@@ -403,7 +418,7 @@ func DeleteProduct(c *gin.Context) {
 
 	// Check product existence and user ownership
 	var product models.Product
-	if err := database.DB.Where("id = ? AND workspace_id = ?", productID, workspaceID).First(&product).Error; err != nil {
+	if err := database.DB.Where("id = ? AND workspace_id = ?", productID, workspaceID).Preload("Options").First(&product).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
@@ -430,6 +445,7 @@ func DeleteProduct(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
+	recordActivity(c, constants.AuditEntityProduct, product.ID, constants.AuditActionDelete, productActivitySnapshot(product), nil)
 
 	// Return successful response
 	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
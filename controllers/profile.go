@@ -1,23 +1,30 @@
 package controllers
 
 import (
+	"log"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
 	"mobile-backend-go/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // ChangePasswordRequest represents password change request structure
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"currentPassword"`
 	NewPassword     string `json:"newPassword"`
+	// KeepCurrentSession keeps the calling device signed in; all other sessions are logged out.
+	KeepCurrentSession bool `json:"keepCurrentSession"`
 }
 
 // ChangePassword changes user password
 // @Summary Change user password
-// @Description Change the password for the authenticated user
+// @Description Change the password for the authenticated user. All existing tokens are invalidated, so every device is logged out.
+// @Description With keepCurrentSession the calling session stays signed in and the response carries a replacement access token.
 // @Tags Profile
 // @Security BearerAuth
 // @Accept  json
@@ -62,12 +69,45 @@ func ChangePassword(c *gin.Context) {
 		return
 	}
 
-	// Update user password in database
-	user.Password = string(hashedPassword)
-	if err := database.DB.Save(&user).Error; err != nil {
+	keepSessionID := uint(0)
+	if payload.KeepCurrentSession {
+		keepSessionID = c.GetUint("sessionID")
+	}
+
+	// Update user password in database and log out the other sessions
+	var generation uint
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		user.Password = string(hashedPassword)
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+
+		var err error
+		generation, err = database.InvalidateUserTokens(tx, user.ID, keepSessionID, database.SessionRevokedPasswordChange)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to change password of user %d: %v", user.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+	recordSecurityEvent(c, user.ID, constants.SecurityEventPasswordChanged)
+
+	if !payload.KeepCurrentSession {
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+		return
+	}
+
+	tokenString, expiresAt, err := middleware.IssueAccessToken(user.ID, keepSessionID, generation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password changed, but failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Password changed successfully",
+		"token":      tokenString,
+		"expires_at": expiresAt,
+	})
 }
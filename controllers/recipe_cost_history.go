@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"log"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recipeCostHistoryMaxPoints caps the points of a cost history; longer histories keep the latest.
+const recipeCostHistoryMaxPoints = 365
+
+// GetRecipeCostHistory returns the batch cost of a recipe over the price history of its ingredients
+// @Summary Recipe cost over time
+// @Description Cost one batch of a recipe at every date a price of one of its ingredients, or of an ingredient of its sub-recipes, took effect, with the cost of each ingredient and sub-recipe. With from the series starts with the cost at from; from and to accept YYYY-MM-DD in the workspace time zone or RFC 3339, and a plain to date includes the whole day. At most 365 points are returned; longer series keep the latest points and are marked truncated.
+// @Tags Recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param from query string false "Start of the series"
+// @Param to query string false "End of the series"
+// @Success 200 {object} models.RecipeCostHistory
+// @Failure 400 {object} map[string]string "Invalid recipe ID or dates"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 500 {object} map[string]string "Failed to fetch recipe cost history"
+// @Router /api/recipes/{id}/cost-history [get]
+func GetRecipeCostHistory(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	location, ok := loadWorkspaceLocation(c, workspaceID)
+	if !ok {
+		return
+	}
+	from, ok := parseRecipeCostTimeQuery(c, "from", false, location)
+	if !ok {
+		return
+	}
+	to, ok := parseRecipeCostTimeQuery(c, "to", true, location)
+	if !ok {
+		return
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	var recipe models.Recipe
+	if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, workspaceID).
+		Preload("RecipeIngredients.Ingredient").
+		Preload("SubRecipes").
+		First(&recipe).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+
+	// Costing at the latest prices loads every sub-recipe the recipe depends on
+	calculator := newRecipeCostCalculator(workspaceID)
+	calculator.apply(&recipe)
+	ingredientIDs := recipeCostIngredientIDs(recipe)
+	for _, subRecipe := range calculator.subRecipes {
+		if subRecipe != nil {
+			ingredientIDs = append(ingredientIDs, recipeCostIngredientIDs(*subRecipe)...)
+		}
+	}
+
+	var dates []time.Time
+	if len(ingredientIDs) > 0 {
+		query := database.DB.Model(&models.Price{}).
+			Where("workspace_id = ? AND ingredient_id IN ?", workspaceID, ingredientIDs)
+		if from != nil {
+			query = query.Where("date > ?", *from)
+		}
+		if to != nil {
+			query = query.Where("date <= ?", *to)
+		}
+		if err := query.Distinct("date").Order("date DESC").Limit(recipeCostHistoryMaxPoints+1).Pluck("date", &dates).Error; err != nil {
+			log.Printf("Failed to fetch price dates of recipe %d: %v", recipe.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe cost history"})
+			return
+		}
+	}
+
+	// The point at from counts towards the limit
+	points := len(dates)
+	if from != nil {
+		points++
+	}
+	history := models.RecipeCostHistory{
+		RecipeID:   recipe.ID,
+		RecipeName: recipe.Name,
+		Truncated:  points > recipeCostHistoryMaxPoints,
+	}
+	if history.Truncated {
+		dates = dates[:recipeCostHistoryMaxPoints]
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	if from != nil && !history.Truncated {
+		dates = append([]time.Time{*from}, dates...)
+	}
+
+	history.Points = make([]models.RecipeCostPoint, 0, len(dates))
+	for i := range dates {
+		calculator.setAsOf(&dates[i])
+		calculator.apply(&recipe)
+		history.Points = append(history.Points, recipeCostPoint(recipe, dates[i]))
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// parseRecipeCostAsOf reads the optional as_of query parameter used to cost recipes at the prices
+// effective on a past date. A plain date includes the prices of the whole day in the workspace time
+// zone.
+func parseRecipeCostAsOf(c *gin.Context) (*time.Time, bool) {
+	if c.Query("as_of") == "" {
+		return nil, true
+	}
+	location, ok := loadWorkspaceLocation(c, c.MustGet("workspaceID").(uint))
+	if !ok {
+		return nil, false
+	}
+	return parseRecipeCostTimeQuery(c, "as_of", true, location)
+}
+
+// parseRecipeCostTimeQuery parses an optional YYYY-MM-DD or RFC 3339 query parameter into UTC, the
+// zone price dates are compared in. A plain date starts at midnight in location; with endOfDay it is
+// moved to the last microsecond of the day, so that it includes the whole day.
+func parseRecipeCostTimeQuery(c *gin.Context, name string, endOfDay bool, location *time.Location) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	parsed, dateOnly, err := parseActivityTime(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " date"})
+		return nil, false
+	}
+	if dateOnly {
+		parsed = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, location)
+		if endOfDay {
+			parsed = parsed.AddDate(0, 0, 1).Add(-time.Microsecond)
+		}
+	}
+	parsed = parsed.UTC()
+	return &parsed, true
+}
+
+func recipeCostIngredientIDs(recipe models.Recipe) []uint {
+	ids := make([]uint, 0, len(recipe.RecipeIngredients))
+	for _, line := range recipe.RecipeIngredients {
+		ids = append(ids, line.IngredientID)
+	}
+	return ids
+}
+
+// recipeCostPoint sums the costed lines of recipe per ingredient and per sub-recipe, in the order
+// they first appear in the recipe.
+func recipeCostPoint(recipe models.Recipe, date time.Time) models.RecipeCostPoint {
+	point := models.RecipeCostPoint{
+		Date:         date,
+		TotalCost:    recipe.TotalCost,
+		CostComplete: recipe.CostComplete,
+		CostPerKg:    recipe.CostPerKg,
+		CostPerPiece: recipe.CostPerPiece,
+		Lines:        make([]models.RecipeCostLine, 0, len(recipe.RecipeIngredients)+len(recipe.SubRecipes)),
+	}
+
+	ingredientLines := make(map[uint]int)
+	for _, line := range recipe.RecipeIngredients {
+		index, ok := ingredientLines[line.IngredientID]
+		if !ok {
+			index = len(point.Lines)
+			ingredientLines[line.IngredientID] = index
+			costLine := models.RecipeCostLine{IngredientID: line.IngredientID, Name: line.Ingredient.Name}
+			if len(line.Ingredient.Prices) > 0 {
+				price := line.Ingredient.Prices[0]
+				costLine.PriceID = &price.ID
+				costLine.PriceDate = &price.Date
+			}
+			point.Lines = append(point.Lines, costLine)
+		}
+		point.Lines[index].Cost += line.CalculatedCost
+	}
+
+	subRecipeLines := make(map[uint]int)
+	for _, line := range recipe.SubRecipes {
+		index, ok := subRecipeLines[line.SubRecipeID]
+		if !ok {
+			index = len(point.Lines)
+			subRecipeLines[line.SubRecipeID] = index
+			costLine := models.RecipeCostLine{SubRecipeID: line.SubRecipeID}
+			if line.SubRecipe != nil {
+				costLine.Name = line.SubRecipe.Name
+			}
+			point.Lines = append(point.Lines, costLine)
+		}
+		point.Lines[index].Cost += line.CalculatedCost
+	}
+
+	return point
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"encoding/json"
+	"math"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// setupRecipeCostHistory prices the fixture ingredient at 8, 10 and 12 per kg from January, March
+// and May 2026, and adds 500 g of a marinade made of the same ingredient to the fixture recipe.
+func setupRecipeCostHistory(t *testing.T) (workspacePriceFixture, models.Recipe) {
+	t.Helper()
+
+	fixture := setupWorkspacePriceTest(t)
+	for _, price := range []struct {
+		value float64
+		date  time.Time
+	}{
+		{8, time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC)},
+		{10, time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{12, time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		createPriceWithTimes(t, fixture.User.ID, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID, price.value, price.date, time.Time{})
+	}
+
+	marinade := createSubRecipeFixture(t, fixture)
+	line := models.RecipeSubRecipe{RecipeID: fixture.Recipe.ID, SubRecipeID: marinade.ID, Quantity: "500", Unit: "g"}
+	if err := database.DB.Create(&line).Error; err != nil {
+		t.Fatalf("create sub-recipe line: %v", err)
+	}
+	return fixture, marinade
+}
+
+func TestGetRecipeCostsAtPricesAsOfDate(t *testing.T) {
+	fixture, _ := setupRecipeCostHistory(t)
+	recipePath := "/recipes/" + uintToString(fixture.Recipe.ID)
+
+	for _, tc := range []struct {
+		asOf string
+		want float64
+	}{
+		{"", 15},
+		{"2026-03-31", 12.5},
+		{"2026-01-10", 10},
+		{"2026-03-04T23:59:59Z", 10},
+		{"2025-12-31", 0},
+	} {
+		target := recipePath
+		if tc.asOf != "" {
+			target += "?as_of=" + tc.asOf
+		}
+		response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipe, http.MethodGet, "/recipes/:id", target)
+		var recipe models.Recipe
+		if err := json.Unmarshal(response.Body.Bytes(), &recipe); err != nil || response.Code != http.StatusOK {
+			t.Fatalf("as_of %q status = %d body = %s", tc.asOf, response.Code, response.Body.String())
+		}
+		if math.Abs(recipe.TotalCost-tc.want) > 1e-9 {
+			t.Fatalf("as_of %q total cost = %v, want %v", tc.asOf, recipe.TotalCost, tc.want)
+		}
+	}
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipe, http.MethodGet, "/recipes/:id", recipePath+"?as_of=March")
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("invalid as_of status = %d, want 400", response.Code)
+	}
+}
+
+func TestGetRecipeCostHistory(t *testing.T) {
+	fixture, marinade := setupRecipeCostHistory(t)
+	historyPath := "/recipes/" + uintToString(fixture.Recipe.ID) + "/cost-history"
+
+	getHistory := func(target string) models.RecipeCostHistory {
+		t.Helper()
+		response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipeCostHistory, http.MethodGet, "/recipes/:id/cost-history", target)
+		var history models.RecipeCostHistory
+		if err := json.Unmarshal(response.Body.Bytes(), &history); err != nil || response.Code != http.StatusOK {
+			t.Fatalf("%s status = %d body = %s", target, response.Code, response.Body.String())
+		}
+		return history
+	}
+
+	history := getHistory(historyPath)
+	if len(history.Points) != 3 {
+		t.Fatalf("points = %+v, want one per price date", history.Points)
+	}
+	for i, want := range []float64{10, 12.5, 15} {
+		if math.Abs(history.Points[i].TotalCost-want) > 1e-9 {
+			t.Fatalf("point %d total cost = %v, want %v", i, history.Points[i].TotalCost, want)
+		}
+	}
+	last := history.Points[2]
+	if !last.Date.Equal(time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)) || len(last.Lines) != 2 {
+		t.Fatalf("last point = %+v", last)
+	}
+	ingredientLine, marinadeLine := last.Lines[0], last.Lines[1]
+	if ingredientLine.IngredientID != fixture.Ingredient.ID || math.Abs(ingredientLine.Cost-12) > 1e-9 || ingredientLine.PriceID == nil {
+		t.Fatalf("ingredient line = %+v, want 12 at the May price", ingredientLine)
+	}
+	if marinadeLine.SubRecipeID != marinade.ID || marinadeLine.Name != "Marinade" || math.Abs(marinadeLine.Cost-3) > 1e-9 {
+		t.Fatalf("marinade line = %+v, want 3", marinadeLine)
+	}
+
+	history = getHistory(historyPath + "?from=2026-02-01&to=2026-04-30")
+	if len(history.Points) != 2 || !history.Points[0].Date.Equal(time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("points = %+v, want the cost at from and the March change", history.Points)
+	}
+	if math.Abs(history.Points[0].TotalCost-10) > 1e-9 || math.Abs(history.Points[1].TotalCost-12.5) > 1e-9 {
+		t.Fatalf("points = %+v, want 10 then 12.5", history.Points)
+	}
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipeCostHistory, http.MethodGet,
+		"/recipes/:id/cost-history", historyPath+"?from=2026-05-01&to=2026-04-01")
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("to before from status = %d, want 400", response.Code)
+	}
+}
+
+func TestRecipeCostDatesAreDaysOfWorkspaceTimezone(t *testing.T) {
+	fixture, _ := setupRecipeCostHistory(t)
+	settings := database.DefaultWorkspaceSettings(fixture.PersonalWorkspace.ID)
+	settings.Timezone = "America/New_York"
+	if err := database.SaveWorkspaceSettings(database.DB, &settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load time zone: %v", err)
+	}
+
+	// The March price takes effect at midnight UTC, still March 4 in New York
+	recipePath := "/recipes/" + uintToString(fixture.Recipe.ID)
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipe, http.MethodGet, "/recipes/:id", recipePath+"?as_of=2026-03-04")
+	var recipe models.Recipe
+	if err := json.Unmarshal(response.Body.Bytes(), &recipe); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("as_of status = %d body = %s", response.Code, response.Body.String())
+	}
+	if math.Abs(recipe.TotalCost-12.5) > 1e-9 {
+		t.Fatalf("total cost = %v, want 12.5 at the end of March 4 in New York", recipe.TotalCost)
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipeCostHistory, http.MethodGet,
+		"/recipes/:id/cost-history", recipePath+"/cost-history?from=2026-02-01&to=2026-03-04")
+	var history models.RecipeCostHistory
+	if err := json.Unmarshal(response.Body.Bytes(), &history); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("history status = %d body = %s", response.Code, response.Body.String())
+	}
+	if len(history.Points) != 2 || !history.Points[0].Date.Equal(time.Date(2026, time.February, 1, 0, 0, 0, 0, newYork)) {
+		t.Fatalf("points = %+v, want February 1 in New York and the March change", history.Points)
+	}
+}
+
+func TestGetRecipeCostHistoryKeepsLatestPoints(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	prices := make([]models.Price, 0, recipeCostHistoryMaxPoints+10)
+	for i := 0; i < recipeCostHistoryMaxPoints+10; i++ {
+		prices = append(prices, models.Price{
+			IngredientID: fixture.Ingredient.ID,
+			Price:        float64(i + 1),
+			Quantity:     1,
+			Unit:         "kg",
+			Date:         start.AddDate(0, 0, i),
+			UserID:       fixture.User.ID,
+			WorkspaceID:  &fixture.PersonalWorkspace.ID,
+		})
+	}
+	if err := database.DB.CreateInBatches(&prices, 100).Error; err != nil {
+		t.Fatalf("create prices: %v", err)
+	}
+
+	historyPath := "/recipes/" + uintToString(fixture.Recipe.ID) + "/cost-history"
+	for _, target := range []string{historyPath, historyPath + "?from=2024-12-01"} {
+		response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipeCostHistory, http.MethodGet, "/recipes/:id/cost-history", target)
+		var history models.RecipeCostHistory
+		if err := json.Unmarshal(response.Body.Bytes(), &history); err != nil || response.Code != http.StatusOK {
+			t.Fatalf("%s status = %d body = %s", target, response.Code, response.Body.String())
+		}
+		if !history.Truncated || len(history.Points) != recipeCostHistoryMaxPoints {
+			t.Fatalf("%s: truncated = %v with %d points, want %d truncated points", target, history.Truncated, len(history.Points), recipeCostHistoryMaxPoints)
+		}
+		first, last := history.Points[0].Date, history.Points[len(history.Points)-1].Date
+		if !first.Equal(start.AddDate(0, 0, 10)) || !last.Equal(start.AddDate(0, 0, recipeCostHistoryMaxPoints+9)) {
+			t.Fatalf("%s: points from %v to %v, want the latest ones in order", target, first, last)
+		}
+	}
+}
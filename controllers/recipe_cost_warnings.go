@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRecipeCostWarnings lists the recipes of the workspace whose cost is incomplete
+// @Summary Recipes with incomplete costing
+// @Description List every recipe of the workspace with lines that could not be costed, or were costed from a sub-recipe that is itself incomplete, with the cost status and message of each such line. Recipes are costed at the latest prices, or at the prices effective on as_of.
+// @Tags Recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param as_of query string false "Cost at the prices effective on this date (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {array} models.RecipeCostWarning
+// @Failure 400 {object} map[string]string "Invalid as_of date"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Failed to fetch recipe cost warnings"
+// @Router /api/recipes/cost-warnings [get]
+func GetRecipeCostWarnings(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+	asOf, ok := parseRecipeCostAsOf(c)
+	if !ok {
+		return
+	}
+
+	var recipes []models.Recipe
+	if err := database.DB.Where("workspace_id = ?", workspaceID).
+		Preload("RecipeIngredients.Ingredient").
+		Preload("SubRecipes.SubRecipe").
+		Order("name ASC, id ASC").
+		Find(&recipes).Error; err != nil {
+		log.Printf("Failed to fetch recipes of workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe cost warnings"})
+		return
+	}
+
+	calculator := newRecipeCostCalculator(workspaceID)
+	calculator.asOf = asOf
+	warnings := make([]models.RecipeCostWarning, 0)
+	for i := range recipes {
+		recipe := &recipes[i]
+		calculator.apply(recipe)
+		if recipe.CostComplete {
+			continue
+		}
+
+		warning := models.RecipeCostWarning{RecipeID: recipe.ID, RecipeName: recipe.Name, TotalCost: recipe.TotalCost}
+		for _, line := range recipe.RecipeIngredients {
+			if line.CostStatus != constants.RecipeCostStatusOK {
+				warning.Lines = append(warning.Lines, models.RecipeCostWarningLine{
+					IngredientID: line.IngredientID,
+					Name:         line.Ingredient.Name,
+					Quantity:     line.Quantity,
+					Unit:         line.Unit,
+					CostStatus:   line.CostStatus,
+					CostMessage:  line.CostMessage,
+				})
+			}
+		}
+		for _, line := range recipe.SubRecipes {
+			if line.CostStatus != constants.RecipeCostStatusOK {
+				warningLine := models.RecipeCostWarningLine{
+					SubRecipeID: line.SubRecipeID,
+					Quantity:    line.Quantity,
+					Unit:        line.Unit,
+					CostStatus:  line.CostStatus,
+					CostMessage: line.CostMessage,
+				}
+				if line.SubRecipe != nil {
+					warningLine.Name = line.SubRecipe.Name
+				}
+				warning.Lines = append(warning.Lines, warningLine)
+			}
+		}
+		warnings = append(warnings, warning)
+	}
+
+	c.JSON(http.StatusOK, warnings)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"encoding/json"
+	"math"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// addUncostableLines adds to the fixture recipe a line without a price, a line in a unit the price
+// cannot be converted to, a line with a quantity that is not a number and a sub-recipe without a yield.
+func addUncostableLines(t *testing.T, fixture workspacePriceFixture) models.Recipe {
+	t.Helper()
+
+	salt := models.Ingredient{Name: "Test salt", Type: "spice"}
+	if err := database.DB.Create(&salt).Error; err != nil {
+		t.Fatalf("create salt: %v", err)
+	}
+	lines := []models.RecipeIngredient{
+		{RecipeID: fixture.Recipe.ID, IngredientID: salt.ID, Quantity: "20", Unit: "g"},
+		{RecipeID: fixture.Recipe.ID, IngredientID: fixture.Ingredient.ID, Quantity: "100", Unit: "ml"},
+		{RecipeID: fixture.Recipe.ID, IngredientID: fixture.Ingredient.ID, Quantity: "a pinch", Unit: "g"},
+	}
+	if err := database.DB.Create(&lines).Error; err != nil {
+		t.Fatalf("create recipe lines: %v", err)
+	}
+
+	glaze := models.Recipe{Name: "Glaze", UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID}
+	if err := database.DB.Create(&glaze).Error; err != nil {
+		t.Fatalf("create glaze: %v", err)
+	}
+	subLine := models.RecipeSubRecipe{RecipeID: fixture.Recipe.ID, SubRecipeID: glaze.ID, Quantity: "50", Unit: "g"}
+	if err := database.DB.Create(&subLine).Error; err != nil {
+		t.Fatalf("create sub-recipe line: %v", err)
+	}
+	return glaze
+}
+
+func TestGetRecipeReportsCostStatusPerLine(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	createPrice(t, fixture.User.ID, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID, 10)
+
+	recipe := getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if !recipe.CostComplete || len(recipe.RecipeIngredients) != 1 || recipe.RecipeIngredients[0].CostStatus != constants.RecipeCostStatusOK {
+		t.Fatalf("priced recipe = %+v, want a complete cost", recipe)
+	}
+
+	addUncostableLines(t, fixture)
+	recipe = getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if recipe.CostComplete || math.Abs(recipe.TotalCost-10) > 1e-9 {
+		t.Fatalf("cost complete = %v total = %v, want incomplete 10", recipe.CostComplete, recipe.TotalCost)
+	}
+	statuses := make(map[string]string)
+	for _, line := range recipe.RecipeIngredients {
+		statuses[line.Quantity+" "+line.Unit] = line.CostStatus
+		if line.CostStatus != constants.RecipeCostStatusOK && line.CostMessage == "" {
+			t.Fatalf("line %+v has no cost message", line)
+		}
+	}
+	want := map[string]string{
+		"1000 g":    constants.RecipeCostStatusOK,
+		"20 g":      constants.RecipeCostStatusNoPrice,
+		"100 ml":    constants.RecipeCostStatusIncompatibleUnits,
+		"a pinch g": constants.RecipeCostStatusInvalidQuantity,
+	}
+	for line, status := range want {
+		if statuses[line] != status {
+			t.Fatalf("line statuses = %v, want %v", statuses, want)
+		}
+	}
+	if len(recipe.SubRecipes) != 1 || recipe.SubRecipes[0].CostStatus != constants.RecipeCostStatusNoYield {
+		t.Fatalf("sub-recipe lines = %+v, want no_yield", recipe.SubRecipes)
+	}
+}
+
+func TestGetRecipeReportsInvalidPriceQuantity(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	price := models.Price{
+		IngredientID: fixture.Ingredient.ID,
+		Price:        10,
+		Quantity:     0,
+		Unit:         "kg",
+		Date:         time.Now(),
+		UserID:       fixture.User.ID,
+		WorkspaceID:  &fixture.PersonalWorkspace.ID,
+	}
+	if err := database.DB.Create(&price).Error; err != nil {
+		t.Fatalf("create price: %v", err)
+	}
+
+	recipe := getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if recipe.CostComplete || len(recipe.RecipeIngredients) != 1 || recipe.RecipeIngredients[0].CostStatus != constants.RecipeCostStatusInvalidPrice {
+		t.Fatalf("recipe lines = %+v, want invalid_price", recipe.RecipeIngredients)
+	}
+}
+
+func TestGetRecipeCostWarningsListsIncompleteRecipes(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	createPrice(t, fixture.User.ID, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID, 10)
+	marinade := createSubRecipeFixture(t, fixture)
+	glaze := addUncostableLines(t, fixture)
+
+	// A recipe using the incomplete fixture recipe is incomplete as well
+	if err := database.DB.Model(&fixture.Recipe).Updates(models.Recipe{RecipeYield: models.RecipeYield{YieldQuantity: "1", YieldUnit: "kg"}}).Error; err != nil {
+		t.Fatalf("set recipe yield: %v", err)
+	}
+	platter := models.Recipe{Name: "Platter", UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID}
+	if err := database.DB.Create(&platter).Error; err != nil {
+		t.Fatalf("create platter: %v", err)
+	}
+	if err := database.DB.Create(&models.RecipeSubRecipe{RecipeID: platter.ID, SubRecipeID: fixture.Recipe.ID, Quantity: "1", Unit: "kg"}).Error; err != nil {
+		t.Fatalf("create platter line: %v", err)
+	}
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipeCostWarnings, http.MethodGet,
+		"/recipes/cost-warnings", "/recipes/cost-warnings")
+	var warnings []models.RecipeCostWarning
+	if err := json.Unmarshal(response.Body.Bytes(), &warnings); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("cost warnings status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	byRecipe := make(map[uint]models.RecipeCostWarning)
+	for _, warning := range warnings {
+		byRecipe[warning.RecipeID] = warning
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("warnings = %+v, want the fixture recipe and the platter", warnings)
+	}
+	if _, ok := byRecipe[marinade.ID]; ok {
+		t.Fatalf("warnings = %+v, the fully priced marinade should not be listed", warnings)
+	}
+	if _, ok := byRecipe[glaze.ID]; ok {
+		t.Fatalf("warnings = %+v, the empty glaze has nothing to cost", warnings)
+	}
+	if lines := byRecipe[fixture.Recipe.ID].Lines; len(lines) != 4 || lines[3].SubRecipeID != glaze.ID || lines[3].Name != "Glaze" {
+		t.Fatalf("fixture recipe warning lines = %+v", lines)
+	}
+	if lines := byRecipe[platter.ID].Lines; len(lines) != 1 || lines[0].CostStatus != constants.RecipeCostStatusIncompleteSubRecipe {
+		t.Fatalf("platter warning lines = %+v, want the incomplete sub-recipe", lines)
+	}
+}
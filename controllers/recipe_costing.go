@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"errors"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"time"
+)
+
+// recipeCostCalculator costs recipes at the latest prices of a workspace, or at the prices effective
+// at asOf when it is set. Sub-recipes are loaded once per calculator and costed once per date, so a
+// sub-recipe shared by several recipes of a list is costed once.
+type recipeCostCalculator struct {
+	workspaceID uint
+	asOf        *time.Time
+	subRecipes  map[uint]*models.Recipe
+	costed      map[uint]bool
+	costing     map[uint]bool
+}
+
+func newRecipeCostCalculator(workspaceID uint) *recipeCostCalculator {
+	return &recipeCostCalculator{
+		workspaceID: workspaceID,
+		subRecipes:  make(map[uint]*models.Recipe),
+		costed:      make(map[uint]bool),
+		costing:     make(map[uint]bool),
+	}
+}
+
+// setAsOf moves the calculator to the prices effective at asOf. Loaded sub-recipes are kept and
+// costed again at the new date when they are next used.
+func (calculator *recipeCostCalculator) setAsOf(asOf *time.Time) {
+	calculator.asOf = asOf
+	calculator.costed = make(map[uint]bool)
+}
+
+// apply costs every ingredient line of recipe at the latest price of the workspace and every
+// sub-recipe line at the finished product cost of its sub-recipe, then sums the total and finished
+// product costs. Lines that cannot be costed - without a price, with an incompatible unit or with a
+// sub-recipe without a yield - cost nothing and carry a cost status explaining why, and the recipe
+// is then not marked cost complete. recipe.SubRecipes must be loaded; costs of an earlier apply are
+// replaced.
+func (calculator *recipeCostCalculator) apply(recipe *models.Recipe) {
+	if recipe.ID != 0 {
+		calculator.costing[recipe.ID] = true
+		defer delete(calculator.costing, recipe.ID)
+	}
+
+	totalCost := 0.0
+	complete := true
+	for j, ri := range recipe.RecipeIngredients {
+		line := &recipe.RecipeIngredients[j]
+		line.Ingredient.Prices = nil
+		line.CalculatedCost = 0
+
+		// Load latest price for each ingredient
+		query := database.DB.Where("ingredient_id = ? AND workspace_id = ?", ri.IngredientID, calculator.workspaceID)
+		if calculator.asOf != nil {
+			query = query.Where("date <= ?", *calculator.asOf)
+		}
+		var latestPrice models.Price
+		if err := query.
+			Order(latestPriceOrder).
+			Limit(1).
+			First(&latestPrice).Error; err != nil {
+			line.CostStatus = constants.RecipeCostStatusNoPrice
+			line.CostMessage = "Ingredient has no price"
+			if calculator.asOf != nil {
+				line.CostMessage = "Ingredient has no price on or before " + calculator.asOf.Format(time.RFC3339)
+			}
+			complete = false
+			continue
+		}
+		line.Ingredient.Prices = []models.Price{latestPrice} // Assign latest price manually
+		cost, err := utils.CalculateIngredientCost(latestPrice.Price, latestPrice.Quantity, latestPrice.Unit, ri.Quantity, ri.Unit)
+		line.CostStatus, line.CostMessage = recipeLineCostStatus(err)
+		if err != nil {
+			complete = false
+			continue
+		}
+		line.CalculatedCost = cost // Assign calculated cost
+		totalCost += cost
+	}
+
+	for j := range recipe.SubRecipes {
+		line := &recipe.SubRecipes[j]
+		line.CalculatedCost = 0
+		subRecipe := calculator.subRecipe(line.SubRecipeID)
+		if subRecipe == nil {
+			line.CostStatus = constants.RecipeCostStatusUnavailable
+			line.CostMessage = "Sub-recipe cannot be costed as part of this recipe"
+			complete = false
+			continue
+		}
+		line.SubRecipe = subRecipe
+		if subRecipe.FinishedQuantity == nil || *subRecipe.FinishedQuantity <= 0 {
+			line.CostStatus = constants.RecipeCostStatusNoYield
+			line.CostMessage = "Sub-recipe has no usable yield"
+			complete = false
+			continue
+		}
+		// Cost of one yield unit of the finished sub-recipe
+		unitCost := subRecipe.TotalCost / *subRecipe.FinishedQuantity
+		cost, err := utils.CalculateIngredientCost(unitCost, 1, subRecipe.YieldUnit, line.Quantity, line.Unit)
+		line.CostStatus, line.CostMessage = recipeLineCostStatus(err)
+		if err != nil {
+			complete = false
+			continue
+		}
+		line.CalculatedCost = cost
+		totalCost += cost
+		if !subRecipe.CostComplete {
+			line.CostStatus = constants.RecipeCostStatusIncompleteSubRecipe
+			line.CostMessage = "Sub-recipe has lines without a cost"
+			complete = false
+		}
+	}
+
+	recipe.TotalCost = totalCost // Add total cost to response, but not save to database
+	recipe.CostComplete = complete
+	applyRecipeYieldCosts(recipe)
+}
+
+// recipeLineCostStatus turns the error of utils.CalculateIngredientCost into a line cost status.
+func recipeLineCostStatus(err error) (string, string) {
+	switch {
+	case err == nil:
+		return constants.RecipeCostStatusOK, ""
+	case errors.Is(err, utils.ErrIncompatibleUnits):
+		return constants.RecipeCostStatusIncompatibleUnits, err.Error()
+	case errors.Is(err, utils.ErrInvalidPriceQuantity):
+		return constants.RecipeCostStatusInvalidPrice, err.Error()
+	default:
+		return constants.RecipeCostStatusInvalidQuantity, err.Error()
+	}
+}
+
+// subRecipe loads and costs a sub-recipe of the workspace. It returns nil for missing recipes and
+// for a recipe that is already being costed, so a cycle in stored data cannot recurse forever.
+func (calculator *recipeCostCalculator) subRecipe(recipeID uint) *models.Recipe {
+	if calculator.costing[recipeID] {
+		return nil
+	}
+	subRecipe, loaded := calculator.subRecipes[recipeID]
+	if !loaded {
+		var loadedRecipe models.Recipe
+		if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, calculator.workspaceID).
+			Preload("RecipeIngredients.Ingredient").
+			Preload("SubRecipes").
+			First(&loadedRecipe).Error; err == nil {
+			subRecipe = &loadedRecipe
+		}
+		calculator.subRecipes[recipeID] = subRecipe
+	}
+	if subRecipe == nil {
+		return nil
+	}
+	if !calculator.costed[recipeID] {
+		calculator.apply(subRecipe)
+		calculator.costed[recipeID] = true
+	}
+	return subRecipe
+}
@@ -3,10 +3,12 @@ package controllers
 import (
 	"errors"
 	"log"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
 	"mobile-backend-go/models"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -27,6 +29,7 @@ import (
 // @Failure 404 {object} map[string]string "Recipe not found"
 // @Router /api/recipes/{recipe_id}/ingredients [post]
 func AddIngredientToRecipe(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
 	workspaceID := c.MustGet("workspaceID").(uint)
 	recipeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -75,7 +78,9 @@ func AddIngredientToRecipe(c *gin.Context) {
 		Unit:         requestData.Unit,
 	}
 
-	if err := database.DB.Create(&newRecipeIngredient).Error; err != nil {
+	if _, err := database.ReviseRecipe(database.DB, recipe.ID, userID, constants.RecipeRevisionIngredientAdded, func(tx *gorm.DB) error {
+		return tx.Create(&newRecipeIngredient).Error
+	}); err != nil {
 		log.Printf("Failed to add ingredient to recipe: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add ingredient to recipe"})
 		return
@@ -85,10 +90,105 @@ func AddIngredientToRecipe(c *gin.Context) {
 	if err := database.DB.Preload("Ingredient").First(&newRecipeIngredient, newRecipeIngredient.ID).Error; err != nil {
 		log.Printf("Failed to load recipe ingredient with ingredient: %v", err)
 	}
+	recordActivity(c, constants.AuditEntityRecipeIngredient, newRecipeIngredient.ID, constants.AuditActionCreate, nil, activitySnapshot(newRecipeIngredient))
 
 	c.JSON(http.StatusCreated, newRecipeIngredient)
 }
 
+// UpdateRecipeIngredient changes the quantity or unit of an ingredient in a recipe
+// @Summary Update an ingredient in a recipe
+// @Description Change the quantity and/or unit of the line of an ingredient in a recipe. Omitted fields keep their value. The change is recorded as a recipe revision.
+// @Tags Recipe Ingredients
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param recipe_id path int true "Recipe ID"
+// @Param ingredient_id path int true "Ingredient ID"
+// @Param ingredient body models.RecipeIngredientUpdateDTO true "Fields to change"
+// @Success 200 {object} models.RecipeIngredient
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe or Ingredient not found"
+// @Failure 409 {object} map[string]string "The ingredient appears in several lines of the recipe"
+// @Failure 500 {object} map[string]string "Failed to update recipe ingredient"
+// @Router /api/recipes/{recipe_id}/ingredients/{ingredient_id} [patch]
+func UpdateRecipeIngredient(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+	ingredientID, err := strconv.Atoi(c.Param("ingredient_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ingredient ID"})
+		return
+	}
+
+	var requestData models.RecipeIngredientUpdateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updates := map[string]interface{}{}
+	if requestData.Quantity != nil {
+		if strings.TrimSpace(*requestData.Quantity) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must not be empty"})
+			return
+		}
+		updates["quantity"] = *requestData.Quantity
+	}
+	if requestData.Unit != nil {
+		updates["unit"] = *requestData.Unit
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+
+	var recipe models.Recipe
+	if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, workspaceID).First(&recipe).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+
+	var recipeIngredients []models.RecipeIngredient
+	if err := database.DB.Where("recipe_id = ? AND ingredient_id = ?", recipeID, ingredientID).Find(&recipeIngredients).Error; err != nil {
+		log.Printf("Failed to load ingredient %d of recipe %d: %v", ingredientID, recipeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe ingredient"})
+		return
+	}
+	if len(recipeIngredients) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ingredient not found in recipe"})
+		return
+	}
+	if len(recipeIngredients) > 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ingredient appears in several lines of the recipe; delete and re-add it"})
+		return
+	}
+	recipeIngredient := recipeIngredients[0]
+	before := activitySnapshot(recipeIngredient)
+
+	if _, err := database.ReviseRecipe(database.DB, recipe.ID, userID, constants.RecipeRevisionIngredientUpdated, func(tx *gorm.DB) error {
+		return tx.Model(&models.RecipeIngredient{}).Where("id = ?", recipeIngredient.ID).Updates(updates).Error
+	}); err != nil {
+		log.Printf("Failed to update ingredient %d of recipe %d: %v", ingredientID, recipeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe ingredient"})
+		return
+	}
+
+	if err := database.DB.Preload("Ingredient").First(&recipeIngredient, recipeIngredient.ID).Error; err != nil {
+		log.Printf("Failed to reload recipe ingredient %d: %v", recipeIngredient.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe ingredient"})
+		return
+	}
+	recordActivity(c, constants.AuditEntityRecipeIngredient, recipeIngredient.ID, constants.AuditActionUpdate, before, activitySnapshot(recipeIngredient))
+
+	c.JSON(http.StatusOK, recipeIngredient)
+}
+
 // DeleteIngredientFromRecipe deletes ingredient from recipe
 // @Summary Delete an ingredient from a recipe
 // @Description Delete an ingredient from a recipe by recipe ID and ingredient ID
@@ -102,6 +202,7 @@ func AddIngredientToRecipe(c *gin.Context) {
 // @Failure 404 {object} map[string]string "Recipe or Ingredient not found"
 // @Router /api/recipes/{recipe_id}/ingredients/{ingredient_id} [delete]
 func DeleteIngredientFromRecipe(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
 	workspaceID := c.MustGet("workspaceID").(uint)
 	recipeID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -120,10 +221,26 @@ func DeleteIngredientFromRecipe(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Where("recipe_id = ? AND ingredient_id = ?", recipeID, ingredientID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+	var recipeIngredients []models.RecipeIngredient
+	if err := database.DB.Where("recipe_id = ? AND ingredient_id = ?", recipeID, ingredientID).Find(&recipeIngredients).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ingredient from recipe"})
 		return
 	}
 
+	if _, err := database.ReviseRecipe(database.DB, recipe.ID, userID, constants.RecipeRevisionIngredientRemoved, func(tx *gorm.DB) error {
+		if err := tx.Where("recipe_id = ? AND ingredient_id = ?", recipeID, ingredientID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		return database.RemoveIngredientFromRecipeSteps(tx, recipe.ID, uint(ingredientID))
+	}); err != nil {
+		log.Printf("Failed to delete ingredient %d from recipe %d: %v", ingredientID, recipeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ingredient from recipe"})
+		return
+	}
+
+	for _, recipeIngredient := range recipeIngredients {
+		recordActivity(c, constants.AuditEntityRecipeIngredient, recipeIngredient.ID, constants.AuditActionDelete, activitySnapshot(recipeIngredient), nil)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Ingredient deleted from recipe successfully"})
 }
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"strings"
+)
+
+const kilojoulesPerKilocalorie = 4.184
+
+// recipeNutritionCalculator works out recipe nutrition from the per 100 g values of ingredients,
+// with the nutrition overrides of the workspace applied. Like recipeCostCalculator it loads every
+// sub-recipe once per calculator.
+type recipeNutritionCalculator struct {
+	workspaceID uint
+	overrides   map[uint]models.Nutrition
+	subRecipes  map[uint]*models.Recipe
+	computing   map[uint]bool
+}
+
+func newRecipeNutritionCalculator(workspaceID uint) *recipeNutritionCalculator {
+	return &recipeNutritionCalculator{
+		workspaceID: workspaceID,
+		subRecipes:  make(map[uint]*models.Recipe),
+		computing:   make(map[uint]bool),
+	}
+}
+
+// apply sets the nutrition of recipe. Ingredient lines count by weight, so lines in a unit that is
+// not a mass are left out; sub-recipe lines count at the nutrition per 100 g of the finished
+// sub-recipe. recipe.RecipeIngredients with their Ingredient and recipe.SubRecipes must be loaded.
+func (calculator *recipeNutritionCalculator) apply(recipe *models.Recipe) {
+	if recipe.ID != 0 {
+		calculator.computing[recipe.ID] = true
+		defer delete(calculator.computing, recipe.ID)
+	}
+
+	nutrition := &models.RecipeNutrition{Complete: true}
+	warn := func(warning models.NutritionWarning) {
+		nutrition.Complete = false
+		nutrition.Warnings = append(nutrition.Warnings, warning)
+	}
+
+	for _, line := range recipe.RecipeIngredients {
+		warning := models.NutritionWarning{IngredientID: line.IngredientID, Name: line.Ingredient.Name}
+		grams, err := utils.ConvertQuantity(line.Quantity, line.Unit, "g")
+		if err != nil {
+			warning.Reason = nutritionWeightWarning(err)
+			warn(warning)
+			continue
+		}
+		nutrition.BatchWeightGrams += grams
+		per100g := line.Ingredient.Nutrition.Override(calculator.override(line.IngredientID))
+		if !addIngredientNutrition(&nutrition.Batch, per100g, grams) {
+			warning.Reason = constants.NutritionWarningMissingValues
+			warn(warning)
+		}
+	}
+
+	for _, line := range recipe.SubRecipes {
+		warning := models.NutritionWarning{SubRecipeID: line.SubRecipeID}
+		subRecipe := calculator.subRecipe(line.SubRecipeID)
+		if subRecipe == nil {
+			warning.Reason = constants.NutritionWarningUnavailableSubRecipe
+			warn(warning)
+			continue
+		}
+		warning.Name = subRecipe.Name
+		if subRecipe.Nutrition.Per100g == nil {
+			warning.Reason = constants.NutritionWarningNoFinishedWeight
+			warn(warning)
+			continue
+		}
+		grams, err := utils.ConvertQuantity(line.Quantity, line.Unit, "g")
+		if err != nil {
+			warning.Reason = nutritionWeightWarning(err)
+			warn(warning)
+			continue
+		}
+		nutrition.BatchWeightGrams += grams
+		addNutritionValues(&nutrition.Batch, *subRecipe.Nutrition.Per100g, grams/100)
+		if !subRecipe.Nutrition.Complete {
+			warning.Reason = constants.NutritionWarningIncompleteSubRecipe
+			warn(warning)
+		}
+	}
+	nutrition.Batch.EnergyKJ = nutrition.Batch.EnergyKcal * kilojoulesPerKilocalorie
+
+	finishedWeight, err := utils.FinishedWeightGrams(nutrition.BatchWeightGrams, recipe.YieldQuantity, recipe.YieldUnit, recipe.ProcessLoss, recipe.PieceSize, recipe.PieceUnit)
+	if err == nil && finishedWeight > 0 {
+		nutrition.FinishedWeightGrams = &finishedWeight
+		per100g := scaleNutritionValues(nutrition.Batch, 100/finishedWeight)
+		nutrition.Per100g = &per100g
+		if strings.TrimSpace(recipe.PieceSize) != "" {
+			if pieceWeight, err := utils.PieceWeightGrams(recipe.PieceSize, recipe.PieceUnit); err == nil {
+				perPiece := scaleNutritionValues(nutrition.Batch, pieceWeight/finishedWeight)
+				nutrition.PerPiece = &perPiece
+			}
+		}
+	}
+
+	recipe.Nutrition = nutrition
+}
+
+// override returns the nutrition override of an ingredient in the workspace.
+func (calculator *recipeNutritionCalculator) override(ingredientID uint) models.Nutrition {
+	if calculator.overrides == nil {
+		calculator.overrides = make(map[uint]models.Nutrition)
+		var workspaceIngredients []models.WorkspaceIngredient
+		if err := database.DB.Where("workspace_id = ?", calculator.workspaceID).Find(&workspaceIngredients).Error; err != nil {
+			log.Printf("Failed to load nutrition overrides of workspace %d: %v", calculator.workspaceID, err)
+		}
+		for _, workspaceIngredient := range workspaceIngredients {
+			calculator.overrides[workspaceIngredient.IngredientID] = workspaceIngredient.NutritionOverride
+		}
+	}
+	return calculator.overrides[ingredientID]
+}
+
+// subRecipe loads a sub-recipe of the workspace with its nutrition. It returns nil for missing
+// recipes and for a recipe whose nutrition is being worked out, so a cycle cannot recurse forever.
+func (calculator *recipeNutritionCalculator) subRecipe(recipeID uint) *models.Recipe {
+	if calculator.computing[recipeID] {
+		return nil
+	}
+	if subRecipe, ok := calculator.subRecipes[recipeID]; ok {
+		return subRecipe
+	}
+
+	var subRecipe models.Recipe
+	if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, calculator.workspaceID).
+		Preload("RecipeIngredients.Ingredient").
+		Preload("SubRecipes").
+		First(&subRecipe).Error; err != nil {
+		calculator.subRecipes[recipeID] = nil
+		return nil
+	}
+	calculator.apply(&subRecipe)
+	calculator.subRecipes[recipeID] = &subRecipe
+	return &subRecipe
+}
+
+// attachProductNutrition sets on every product option the nutrition of its recipe.
+func attachProductNutrition(workspaceID uint, products []models.Product) {
+	recipeIDs := make([]uint, 0)
+	for _, product := range products {
+		for _, option := range product.Options {
+			recipeIDs = append(recipeIDs, option.RecipeID)
+		}
+	}
+	if len(recipeIDs) == 0 {
+		return
+	}
+
+	var recipes []models.Recipe
+	if err := database.DB.Where("workspace_id = ? AND id IN ?", workspaceID, recipeIDs).
+		Preload("RecipeIngredients.Ingredient").
+		Preload("SubRecipes").
+		Find(&recipes).Error; err != nil {
+		log.Printf("Failed to load product recipes of workspace %d: %v", workspaceID, err)
+		return
+	}
+
+	calculator := newRecipeNutritionCalculator(workspaceID)
+	nutrition := make(map[uint]*models.RecipeNutrition, len(recipes))
+	for i := range recipes {
+		calculator.apply(&recipes[i])
+		nutrition[recipes[i].ID] = recipes[i].Nutrition
+	}
+	for i := range products {
+		for j := range products[i].Options {
+			products[i].Options[j].Nutrition = nutrition[products[i].Options[j].RecipeID]
+		}
+	}
+}
+
+// addIngredientNutrition adds grams of an ingredient with per100g nutrition to total. It reports
+// false when some values are unknown; those count as zero.
+func addIngredientNutrition(total *models.NutritionValues, per100g models.Nutrition, grams float64) bool {
+	complete := true
+	value := func(per100g *float64) float64 {
+		if per100g == nil {
+			complete = false
+			return 0
+		}
+		return *per100g
+	}
+	addNutritionValues(total, models.NutritionValues{
+		EnergyKcal:    value(per100g.EnergyKcal),
+		Protein:       value(per100g.Protein),
+		Fat:           value(per100g.Fat),
+		SaturatedFat:  value(per100g.SaturatedFat),
+		Carbohydrates: value(per100g.Carbohydrates),
+		Sugars:        value(per100g.Sugars),
+		Salt:          value(per100g.Salt),
+	}, grams/100)
+	return complete
+}
+
+// addNutritionValues adds values multiplied by factor to total. Energy in kJ is derived from kcal
+// once the total is complete.
+func addNutritionValues(total *models.NutritionValues, values models.NutritionValues, factor float64) {
+	total.EnergyKcal += values.EnergyKcal * factor
+	total.Protein += values.Protein * factor
+	total.Fat += values.Fat * factor
+	total.SaturatedFat += values.SaturatedFat * factor
+	total.Carbohydrates += values.Carbohydrates * factor
+	total.Sugars += values.Sugars * factor
+	total.Salt += values.Salt * factor
+}
+
+func scaleNutritionValues(values models.NutritionValues, factor float64) models.NutritionValues {
+	var scaled models.NutritionValues
+	addNutritionValues(&scaled, values, factor)
+	scaled.EnergyKJ = scaled.EnergyKcal * kilojoulesPerKilocalorie
+	return scaled
+}
+
+func nutritionWeightWarning(err error) string {
+	if errors.Is(err, utils.ErrIncompatibleUnits) {
+		return constants.NutritionWarningNotByWeight
+	}
+	return constants.NutritionWarningInvalidQuantity
+}
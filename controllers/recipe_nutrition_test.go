@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"math"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"testing"
+)
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+func TestRecipeNutritionPerBatchAndFinishedProduct(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	if err := database.DB.AutoMigrate(&models.WorkspaceIngredient{}); err != nil {
+		t.Fatalf("migrate workspace ingredients: %v", err)
+	}
+
+	nutrition := models.Nutrition{
+		EnergyKcal: float64Ptr(120), Protein: float64Ptr(20), Fat: float64Ptr(5), SaturatedFat: float64Ptr(2),
+		Carbohydrates: float64Ptr(0), Sugars: float64Ptr(0), Salt: float64Ptr(0.1),
+	}
+	if err := database.DB.Model(&fixture.Ingredient).Updates(models.Ingredient{Nutrition: nutrition}).Error; err != nil {
+		t.Fatalf("set ingredient nutrition: %v", err)
+	}
+	workspaceIngredient, err := database.EnsureWorkspaceIngredient(database.DB, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID)
+	if err != nil {
+		t.Fatalf("link ingredient: %v", err)
+	}
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateWorkspaceIngredient, http.MethodPatch,
+		"/workspace-ingredients/:id", "/workspace-ingredients/"+uintToString(workspaceIngredient.ID), map[string]any{"nutrition_override": map[string]any{"protein": 22}})
+	if response.Code != http.StatusOK {
+		t.Fatalf("override nutrition status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	// 1 kg of the ingredient and 500 g of a marinade of 1 kg of the ingredient making 2 kg,
+	// dried from 1.5 kg to 600 g and packed by 50 g
+	marinade := createSubRecipeFixture(t, fixture)
+	if err := database.DB.Create(&models.RecipeSubRecipe{RecipeID: fixture.Recipe.ID, SubRecipeID: marinade.ID, Quantity: "500", Unit: "g"}).Error; err != nil {
+		t.Fatalf("create sub-recipe line: %v", err)
+	}
+	yield := models.RecipeYield{YieldQuantity: "1.5", YieldUnit: "kg", ProcessLoss: 0.6, PieceSize: "50", PieceUnit: "g"}
+	if err := database.DB.Model(&fixture.Recipe).Updates(models.Recipe{RecipeYield: yield}).Error; err != nil {
+		t.Fatalf("set recipe yield: %v", err)
+	}
+
+	recipe := getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	facts := recipe.Nutrition
+	if facts == nil || !facts.Complete || facts.BatchWeightGrams != 1500 || facts.FinishedWeightGrams == nil || math.Abs(*facts.FinishedWeightGrams-600) > 1e-9 {
+		t.Fatalf("nutrition = %+v, want a complete 1500 g batch finishing at 600 g", facts)
+	}
+	if math.Abs(facts.Batch.EnergyKcal-1500) > 1e-9 || math.Abs(facts.Batch.Protein-275) > 1e-9 {
+		t.Fatalf("batch = %+v, want 1500 kcal and 275 g protein with the workspace override", facts.Batch)
+	}
+	if facts.Per100g == nil || math.Abs(facts.Per100g.EnergyKcal-250) > 1e-9 || math.Abs(facts.Per100g.EnergyKJ-1046) > 1e-9 {
+		t.Fatalf("per 100 g = %+v, want 250 kcal", facts.Per100g)
+	}
+	if facts.PerPiece == nil || math.Abs(facts.PerPiece.EnergyKcal-125) > 1e-9 {
+		t.Fatalf("per piece = %+v, want 125 kcal", facts.PerPiece)
+	}
+
+	products := []models.Product{{Options: []models.ProductOption{{RecipeID: fixture.Recipe.ID}}}}
+	attachProductNutrition(fixture.PersonalWorkspace.ID, products)
+	if option := products[0].Options[0]; option.Nutrition == nil || option.Nutrition.Per100g == nil || math.Abs(option.Nutrition.Per100g.EnergyKcal-250) > 1e-9 {
+		t.Fatalf("product option nutrition = %+v, want the recipe nutrition", option.Nutrition)
+	}
+
+	salt := models.Ingredient{Name: "Test salt", Type: "spice"}
+	if err := database.DB.Create(&salt).Error; err != nil {
+		t.Fatalf("create salt: %v", err)
+	}
+	lines := []models.RecipeIngredient{
+		{RecipeID: marinade.ID, IngredientID: salt.ID, Quantity: "20", Unit: "g"},
+		{RecipeID: fixture.Recipe.ID, IngredientID: salt.ID, Quantity: "2", Unit: "pcs"},
+	}
+	if err := database.DB.Create(&lines).Error; err != nil {
+		t.Fatalf("create salt lines: %v", err)
+	}
+
+	recipe = getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	reasons := make(map[string]bool)
+	for _, warning := range recipe.Nutrition.Warnings {
+		reasons[warning.Reason] = true
+	}
+	if recipe.Nutrition.Complete || len(reasons) != 2 || !reasons[constants.NutritionWarningNotByWeight] || !reasons[constants.NutritionWarningIncompleteSubRecipe] {
+		t.Fatalf("warnings = %+v, want the salt pieces and the incomplete marinade", recipe.Nutrition.Warnings)
+	}
+}
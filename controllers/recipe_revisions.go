@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListRecipeRevisions returns the revision history of a recipe
+// @Summary List recipe revisions
+// @Description List the immutable revisions of a recipe, newest first. Every rename, ingredient change and restore adds a revision with the full name and ingredient lines. Recipes created before revisions were recorded start with a baseline revision at their first change.
+// @Tags Recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Success 200 {array} models.RecipeRevision
+// @Failure 400 {object} map[string]string "Invalid recipe ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 500 {object} map[string]string "Failed to load revisions"
+// @Router /api/recipes/{id}/revisions [get]
+func ListRecipeRevisions(c *gin.Context) {
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := database.ListRecipeRevisions(database.DB, recipe.ID)
+	if err != nil {
+		log.Printf("Failed to list revisions of recipe %d: %v", recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load revisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRecipeRevision returns one revision of a recipe
+// @Summary Get a recipe revision
+// @Description Get the name and ingredient lines of a recipe as they were in a revision.
+// @Tags Recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} models.RecipeRevision
+// @Failure 400 {object} map[string]string "Invalid recipe ID or revision"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe or revision not found"
+// @Router /api/recipes/{id}/revisions/{revision} [get]
+func GetRecipeRevision(c *gin.Context) {
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+	revision, ok := findRecipeRevision(c, recipe.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRecipeRevisions compares two revisions of a recipe
+// @Summary Diff recipe revisions
+// @Description Show the name change and the added, removed and changed ingredient lines between revision from and the revision in the path. from defaults to the preceding revision; from=0 compares against an empty recipe.
+// @Tags Recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param revision path int true "Revision number"
+// @Param from query int false "Revision number to compare with"
+// @Success 200 {object} models.RecipeRevisionDiff
+// @Failure 400 {object} map[string]string "Invalid recipe ID or revision"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe or revision not found"
+// @Router /api/recipes/{id}/revisions/{revision}/diff [get]
+func DiffRecipeRevisions(c *gin.Context) {
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+	to, ok := findRecipeRevision(c, recipe.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	fromParam := c.DefaultQuery("from", strconv.FormatUint(uint64(to.Number-1), 10))
+	var from models.RecipeRevision
+	if fromParam != "0" {
+		from, ok = findRecipeRevision(c, recipe.ID, fromParam)
+		if !ok {
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, database.DiffRecipeRevisions(from, to))
+}
+
+// RestoreRecipeRevision sets a recipe back to one of its revisions
+// @Summary Restore a recipe revision
+// @Description Set the name, yield, ingredient and sub-recipe lines of a recipe back to a revision. The restore is recorded as a new revision, so it can itself be undone. Ingredients and sub-recipes must still be usable in the workspace.
+// @Tags Recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} models.RecipeRevision
+// @Failure 400 {object} map[string]string "Invalid revision or ingredient no longer in workspace"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe or revision not found"
+// @Failure 409 {object} map[string]string "A sub-recipe of the revision now uses this recipe"
+// @Failure 500 {object} map[string]string "Failed to restore revision"
+// @Router /api/recipes/{id}/revisions/{revision}/restore [post]
+func RestoreRecipeRevision(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+	target, ok := findRecipeRevision(c, recipe.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	for _, line := range target.Ingredients {
+		if line.SubRecipeID != 0 {
+			var subRecipe models.Recipe
+			if err := database.DB.Where("id = ? AND workspace_id = ?", line.SubRecipeID, workspaceID).First(&subRecipe).Error; err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Sub-recipe is not in workspace", "sub_recipe_id": line.SubRecipeID})
+				return
+			}
+			continue
+		}
+		if err := prepareWorkspaceIngredientForWrite(workspaceID, line.IngredientID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, database.ErrWorkspaceIngredientNotActive) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Ingredient is not in workspace", "ingredient_id": line.IngredientID})
+				return
+			}
+			log.Printf("Failed to validate restored ingredient %d in workspace %d: %v", line.IngredientID, workspaceID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+			return
+		}
+	}
+
+	before := recipeContentActivitySnapshot(recipe.ID)
+	revision, err := database.RestoreRecipeRevision(database.DB, recipe.ID, userID, target.Number)
+	if err != nil {
+		if errors.Is(err, database.ErrSubRecipeCycle) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A sub-recipe of the revision now uses this recipe"})
+			return
+		}
+		log.Printf("Failed to restore revision %d of recipe %d: %v", target.Number, recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+		return
+	}
+
+	recordActivity(c, constants.AuditEntityRecipe, recipe.ID, constants.AuditActionUpdate, before, recipeContentActivitySnapshot(recipe.ID))
+	c.JSON(http.StatusOK, revision)
+}
+
+// findWorkspaceRecipe loads the recipe in the id path parameter from the current workspace.
+// It responds with an error and returns false when there is none.
+func findWorkspaceRecipe(c *gin.Context) (models.Recipe, bool) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+	var recipe models.Recipe
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return recipe, false
+	}
+	if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, workspaceID).First(&recipe).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return recipe, false
+	}
+	return recipe, true
+}
+
+// findRecipeRevision loads revision number of recipeID. It responds with an error and returns false
+// when the number is invalid or the revision does not exist.
+func findRecipeRevision(c *gin.Context, recipeID uint, number string) (models.RecipeRevision, bool) {
+	parsed, err := strconv.ParseUint(number, 10, 32)
+	if err != nil || parsed == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return models.RecipeRevision{}, false
+	}
+
+	revision, err := database.FindRecipeRevision(database.DB, recipeID, uint(parsed))
+	if err != nil {
+		if errors.Is(err, database.ErrRecipeRevisionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+			return revision, false
+		}
+		log.Printf("Failed to load revision %d of recipe %d: %v", parsed, recipeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load revision"})
+		return revision, false
+	}
+	return revision, true
+}
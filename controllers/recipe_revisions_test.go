@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+func TestRecipeChangesAreRecordedAsRestorableRevisions(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+	userID := fixture.User.ID
+	workspaceID := fixture.PersonalWorkspace.ID
+	recipePath := fmt.Sprintf("/recipes/%d", fixture.PersonalRecipe.ID)
+
+	response := runWorkspaceJSONRequest(userID, workspaceID, UpdateRecipe, http.MethodPut, "/recipes/:id", recipePath, map[string]any{"name": "Teriyaki"})
+	if response.Code != http.StatusOK {
+		t.Fatalf("update recipe status = %d body = %s", response.Code, response.Body.String())
+	}
+	response = runWorkspaceJSONRequest(userID, workspaceID, AddIngredientToRecipe, http.MethodPost, "/recipes/:id/ingredients", recipePath+"/ingredients",
+		map[string]any{"ingredient_id": fixture.Ingredient.ID, "quantity": "10", "unit": "g"})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("add ingredient status = %d body = %s", response.Code, response.Body.String())
+	}
+	ingredientPath := fmt.Sprintf("%s/ingredients/%d", recipePath, fixture.Ingredient.ID)
+	for i := 0; i < 2; i++ {
+		response = runWorkspaceJSONRequest(userID, workspaceID, UpdateRecipeIngredient, http.MethodPatch, "/recipes/:id/ingredients/:ingredient_id", ingredientPath, map[string]any{"quantity": "15"})
+		if response.Code != http.StatusOK {
+			t.Fatalf("patch ingredient status = %d body = %s", response.Code, response.Body.String())
+		}
+	}
+	var line models.RecipeIngredient
+	if err := json.Unmarshal(response.Body.Bytes(), &line); err != nil || line.Quantity != "15" || line.Unit != "g" {
+		t.Fatalf("patched line = %s", response.Body.String())
+	}
+
+	response = runWorkspaceRequest(userID, workspaceID, ListRecipeRevisions, http.MethodGet, "/recipes/:id/revisions", recipePath+"/revisions")
+	var revisions []models.RecipeRevision
+	if err := json.Unmarshal(response.Body.Bytes(), &revisions); err != nil {
+		t.Fatalf("decode revisions: %v", err)
+	}
+	wantActions := []string{
+		constants.RecipeRevisionIngredientUpdated,
+		constants.RecipeRevisionIngredientAdded,
+		constants.RecipeRevisionUpdated,
+		constants.RecipeRevisionBaseline,
+	}
+	if len(revisions) != len(wantActions) {
+		t.Fatalf("revisions = %+v, want %d (an unchanged patch adds none)", revisions, len(wantActions))
+	}
+	for i, action := range wantActions {
+		if revisions[i].Action != action || revisions[i].Number != uint(len(wantActions)-i) {
+			t.Fatalf("revision %d = %+v, want action %s", i, revisions[i], action)
+		}
+	}
+	if revisions[3].Name != "Personal recipe" || len(revisions[0].Ingredients) != 1 || revisions[0].Ingredients[0].Quantity != "15" {
+		t.Fatalf("revision snapshots = %+v", revisions)
+	}
+
+	response = runWorkspaceRequest(userID, workspaceID, DiffRecipeRevisions, http.MethodGet, "/recipes/:id/revisions/:revision/diff", recipePath+"/revisions/4/diff")
+	var diff models.RecipeRevisionDiff
+	if err := json.Unmarshal(response.Body.Bytes(), &diff); err != nil || diff.From != 3 || len(diff.ChangedIngredients) != 1 || diff.Name != nil {
+		t.Fatalf("diff 3..4 = %s", response.Body.String())
+	}
+	if change := diff.ChangedIngredients[0].Changes["quantity"]; change.Before != "10" || change.After != "15" {
+		t.Fatalf("quantity change = %+v", change)
+	}
+	response = runWorkspaceRequest(userID, workspaceID, DiffRecipeRevisions, http.MethodGet, "/recipes/:id/revisions/:revision/diff", recipePath+"/revisions/4/diff?from=1")
+	if err := json.Unmarshal(response.Body.Bytes(), &diff); err != nil || diff.Name == nil || len(diff.AddedIngredients) != 1 {
+		t.Fatalf("diff 1..4 = %s", response.Body.String())
+	}
+
+	response = runWorkspaceRequest(userID, workspaceID, RestoreRecipeRevision, http.MethodPost, "/recipes/:id/revisions/:revision/restore", recipePath+"/revisions/2/restore")
+	var restored models.RecipeRevision
+	if err := json.Unmarshal(response.Body.Bytes(), &restored); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("restore status = %d body = %s", response.Code, response.Body.String())
+	}
+	if restored.Number != 5 || restored.Action != constants.RecipeRevisionRestored || restored.RestoredFrom == nil || *restored.RestoredFrom != 2 ||
+		restored.Name != "Teriyaki" || len(restored.Ingredients) != 0 {
+		t.Fatalf("restored revision = %+v", restored)
+	}
+	var lines int64
+	database.DB.Model(&models.RecipeIngredient{}).Where("recipe_id = ?", fixture.PersonalRecipe.ID).Count(&lines)
+	if lines != 0 {
+		t.Fatalf("recipe lines after restore = %d, want 0", lines)
+	}
+	var restoreLog models.AuditLog
+	if err := database.DB.Where("entity_type = ? AND entity_id = ?", constants.AuditEntityRecipe, fixture.PersonalRecipe.ID).
+		Order("id DESC").First(&restoreLog).Error; err != nil {
+		t.Fatalf("load restore activity: %v", err)
+	}
+	if restoreLog.Action != constants.AuditActionUpdate || restoreLog.Changes["ingredients"].Before == nil || restoreLog.Changes["name"].After != nil {
+		t.Fatalf("restore activity = %+v, want the removed ingredient line and no name change", restoreLog)
+	}
+
+	response = runWorkspaceRequest(userID, workspaceID, GetRecipeRevision, http.MethodGet, "/recipes/:id/revisions/:revision", recipePath+"/revisions/9")
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("missing revision status = %d, want 404", response.Code)
+	}
+	response = runWorkspaceJSONRequest(userID, workspaceID, UpdateRecipeIngredient, http.MethodPatch, "/recipes/:id/ingredients/:ingredient_id", ingredientPath, map[string]any{"unit": "kg"})
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("patch removed ingredient status = %d, want 404", response.Code)
+	}
+	otherWorkspacePath := fmt.Sprintf("/recipes/%d/revisions", fixture.SecondRecipe.ID)
+	response = runWorkspaceRequest(userID, workspaceID, ListRecipeRevisions, http.MethodGet, "/recipes/:id/revisions", otherWorkspacePath)
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("revisions of other workspace recipe status = %d, want 404", response.Code)
+	}
+}
+
+func TestCreateRecipeRecordsFirstRevision(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CreateRecipe, http.MethodPost, "/recipes", "/recipes", map[string]any{"name": "Honey garlic"})
+	var recipe models.Recipe
+	if err := json.Unmarshal(response.Body.Bytes(), &recipe); err != nil || response.Code != http.StatusCreated {
+		t.Fatalf("create recipe status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	revisions, err := database.ListRecipeRevisions(database.DB, recipe.ID)
+	if err != nil {
+		t.Fatalf("list revisions: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Number != 1 || revisions[0].Action != constants.RecipeRevisionCreated || revisions[0].Name != "Honey garlic" {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+}
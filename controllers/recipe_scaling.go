@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScaleRecipe scales a recipe by a factor or to a target quantity of one of its ingredients
+// @Summary Scale a recipe
+// @Description Scale the ingredient lines and yield of a recipe either by factor or so that anchor_ingredient_id adds up to target_quantity of target_unit. Masses and volumes are promoted to kg and l from 1000 g or ml up. The scaled recipe is costed at the latest workspace prices. With save the scaled recipe is stored as a new recipe named name (default: the recipe name with the factor), which also requires permission to create recipes.
+// @Tags Recipes
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param request body models.RecipeScaleDTO true "Factor or anchor ingredient target"
+// @Success 200 {object} models.RecipeScaleResult
+// @Success 201 {object} models.RecipeScaleResult "Scaled recipe saved"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 500 {object} map[string]string "Failed to save scaled recipe"
+// @Router /api/recipes/{id}/scale [post]
+func ScaleRecipe(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	var requestData models.RecipeScaleDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (requestData.Factor == nil) == (requestData.AnchorIngredientID == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either factor or anchor_ingredient_id with target_quantity"})
+		return
+	}
+	if requestData.Save && !requireCurrentWorkspacePermission(c, constants.WorkspaceResourceRecipes, constants.WorkspaceActionCreate) {
+		return
+	}
+
+	var recipe models.Recipe
+	if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, workspaceID).
+		Preload("RecipeIngredients.Ingredient").
+		Preload("SubRecipes").
+		Preload("Steps", orderRecipeSteps).
+		First(&recipe).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+
+	var factor float64
+	if requestData.Factor != nil {
+		factor = *requestData.Factor
+		if factor <= 0 || math.IsInf(factor, 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Factor must be greater than zero"})
+			return
+		}
+	} else {
+		factor, err = anchorScaleFactor(recipe, requestData)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	scaled, err := scaleRecipe(recipe, factor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requestData.Save {
+		newRecipeCostCalculator(workspaceID).apply(&scaled)
+		c.JSON(http.StatusOK, models.RecipeScaleResult{SourceRecipeID: recipe.ID, Factor: factor, Recipe: scaled})
+		return
+	}
+
+	scaled.Name = strings.TrimSpace(requestData.Name)
+	if scaled.Name == "" {
+		scaled.Name = fmt.Sprintf("%s x%s", recipe.Name, strconv.FormatFloat(math.Round(factor*1000)/1000, 'f', -1, 64))
+	}
+	scaled.UserID = userID
+	scaled.WorkspaceID = &workspaceID
+	for i := range scaled.RecipeIngredients {
+		// Lines are created with the recipe; the ingredients and sub-recipes themselves already exist
+		scaled.RecipeIngredients[i].Ingredient = models.Ingredient{}
+	}
+	for i := range scaled.SubRecipes {
+		scaled.SubRecipes[i].SubRecipe = nil
+	}
+
+	if _, err := database.CreateRecipe(database.DB, &scaled); err != nil {
+		log.Printf("Failed to save recipe %d scaled by %v: %v", recipe.ID, factor, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save scaled recipe"})
+		return
+	}
+
+	var saved models.Recipe
+	if err := database.DB.Preload("RecipeIngredients.Ingredient").Preload("SubRecipes").Preload("Steps", orderRecipeSteps).First(&saved, scaled.ID).Error; err != nil {
+		log.Printf("Failed to reload scaled recipe %d: %v", scaled.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save scaled recipe"})
+		return
+	}
+	recordActivity(c, constants.AuditEntityRecipe, saved.ID, constants.AuditActionCreate, nil, activitySnapshot(saved))
+
+	newRecipeCostCalculator(workspaceID).apply(&saved)
+	c.JSON(http.StatusCreated, models.RecipeScaleResult{SourceRecipeID: recipe.ID, Factor: factor, Saved: true, Recipe: saved})
+}
+
+// anchorScaleFactor returns the factor that makes all lines of the anchor ingredient add up to
+// the requested target quantity.
+func anchorScaleFactor(recipe models.Recipe, requestData models.RecipeScaleDTO) (float64, error) {
+	if strings.TrimSpace(requestData.TargetQuantity) == "" {
+		return 0, errors.New("target_quantity is required with anchor_ingredient_id")
+	}
+	target, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(requestData.TargetQuantity), ",", ".", 1), 64)
+	if err != nil || target <= 0 {
+		return 0, errors.New("target_quantity must be a positive number")
+	}
+
+	found := false
+	anchorQuantity := 0.0
+	for _, ri := range recipe.RecipeIngredients {
+		if ri.IngredientID != requestData.AnchorIngredientID {
+			continue
+		}
+		found = true
+		quantity, err := utils.ConvertQuantity(ri.Quantity, ri.Unit, requestData.TargetUnit)
+		if err != nil {
+			return 0, fmt.Errorf("anchor ingredient quantity %s %s cannot be converted to %s: %v", ri.Quantity, ri.Unit, requestData.TargetUnit, err)
+		}
+		anchorQuantity += quantity
+	}
+	if !found {
+		return 0, errors.New("anchor ingredient is not in the recipe")
+	}
+	if anchorQuantity <= 0 {
+		return 0, errors.New("anchor ingredient has no quantity in the recipe")
+	}
+	return target / anchorQuantity, nil
+}
+
+// scaleRecipe returns an unsaved copy of recipe with its ingredient and sub-recipe lines and yield
+// multiplied by factor.
+// Process loss, piece size and the steps do not depend on the batch size and are kept.
+func scaleRecipe(recipe models.Recipe, factor float64) (models.Recipe, error) {
+	scaled := models.Recipe{
+		Name:        recipe.Name,
+		RecipeYield: recipe.RecipeYield,
+	}
+	if strings.TrimSpace(recipe.YieldQuantity) != "" {
+		quantity, unit, err := utils.ScaleQuantity(recipe.YieldQuantity, recipe.YieldUnit, factor)
+		if err != nil {
+			return models.Recipe{}, fmt.Errorf("yield quantity cannot be scaled: %v", err)
+		}
+		scaled.YieldQuantity = quantity
+		scaled.YieldUnit = unit
+	}
+
+	scaled.RecipeIngredients = make([]models.RecipeIngredient, 0, len(recipe.RecipeIngredients))
+	for _, ri := range recipe.RecipeIngredients {
+		quantity, unit, err := utils.ScaleQuantity(ri.Quantity, ri.Unit, factor)
+		if err != nil {
+			return models.Recipe{}, fmt.Errorf("quantity %q of ingredient %d cannot be scaled: %v", ri.Quantity, ri.IngredientID, err)
+		}
+		scaled.RecipeIngredients = append(scaled.RecipeIngredients, models.RecipeIngredient{
+			IngredientID: ri.IngredientID,
+			Quantity:     quantity,
+			Unit:         unit,
+			Ingredient:   ri.Ingredient,
+		})
+	}
+
+	scaled.SubRecipes = make([]models.RecipeSubRecipe, 0, len(recipe.SubRecipes))
+	for _, line := range recipe.SubRecipes {
+		quantity, unit, err := utils.ScaleQuantity(line.Quantity, line.Unit, factor)
+		if err != nil {
+			return models.Recipe{}, fmt.Errorf("quantity %q of sub-recipe %d cannot be scaled: %v", line.Quantity, line.SubRecipeID, err)
+		}
+		scaled.SubRecipes = append(scaled.SubRecipes, models.RecipeSubRecipe{
+			SubRecipeID: line.SubRecipeID,
+			Quantity:    quantity,
+			Unit:        unit,
+		})
+	}
+
+	scaled.Steps = make([]models.RecipeStep, 0, len(recipe.Steps))
+	for _, step := range recipe.Steps {
+		scaled.Steps = append(scaled.Steps, models.RecipeStep{Position: step.Position, RecipeStepDetails: step.RecipeStepDetails})
+	}
+	return scaled, nil
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func runRecipeScaleRequest(fixture workspacePriceFixture, role string, body any) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.POST("/recipes/:id/scale", func(c *gin.Context) {
+		c.Set("userID", fixture.User.ID)
+		c.Set("workspaceID", fixture.PersonalWorkspace.ID)
+		c.Set("workspaceRole", role)
+		ScaleRecipe(c)
+	})
+
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(body); err != nil {
+		panic(err)
+	}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/recipes/"+uintToString(fixture.Recipe.ID)+"/scale", &payload)
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func decodeRecipeScaleResult(t *testing.T, response *httptest.ResponseRecorder) models.RecipeScaleResult {
+	t.Helper()
+
+	var result models.RecipeScaleResult
+	if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode scale result: %v body = %s", err, response.Body.String())
+	}
+	return result
+}
+
+func TestScaleRecipeByFactorPromotesUnitsAndCosts(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	createPrice(t, fixture.User.ID, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID, 10)
+
+	response := runRecipeScaleRequest(fixture, constants.WorkspaceRoleViewer, map[string]any{"factor": 3.5})
+	if response.Code != http.StatusOK {
+		t.Fatalf("scale status = %d body = %s", response.Code, response.Body.String())
+	}
+	result := decodeRecipeScaleResult(t, response)
+	if result.Saved || result.Recipe.ID != 0 || result.Factor != 3.5 {
+		t.Fatalf("scale result = %+v, want unsaved recipe scaled by 3.5", result)
+	}
+	if len(result.Recipe.RecipeIngredients) != 1 {
+		t.Fatalf("scaled lines = %+v, want one", result.Recipe.RecipeIngredients)
+	}
+	line := result.Recipe.RecipeIngredients[0]
+	if line.Quantity != "3.5" || line.Unit != "kg" {
+		t.Fatalf("scaled line = %s %s, want 3.5 kg", line.Quantity, line.Unit)
+	}
+	if math.Abs(result.Recipe.TotalCost-35) > 1e-9 {
+		t.Fatalf("scaled total cost = %v, want 35", result.Recipe.TotalCost)
+	}
+
+	var count int64
+	database.DB.Model(&models.Recipe{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("recipes after preview = %d, want 2", count)
+	}
+}
+
+func TestScaleRecipeToAnchorIngredientTarget(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+
+	response := runRecipeScaleRequest(fixture, constants.WorkspaceRoleViewer, map[string]any{
+		"anchor_ingredient_id": fixture.Ingredient.ID,
+		"target_quantity":      "12",
+		"target_unit":          "kg",
+	})
+	if response.Code != http.StatusOK {
+		t.Fatalf("scale status = %d body = %s", response.Code, response.Body.String())
+	}
+	result := decodeRecipeScaleResult(t, response)
+	if math.Abs(result.Factor-12) > 1e-9 || result.Recipe.RecipeIngredients[0].Quantity != "12" {
+		t.Fatalf("scale result = %+v, want factor 12", result)
+	}
+
+	response = runRecipeScaleRequest(fixture, constants.WorkspaceRoleViewer, map[string]any{
+		"anchor_ingredient_id": fixture.Ingredient.ID + 100,
+		"target_quantity":      "12",
+		"target_unit":          "kg",
+	})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("missing anchor status = %d, want 400", response.Code)
+	}
+	response = runRecipeScaleRequest(fixture, constants.WorkspaceRoleViewer, map[string]any{
+		"anchor_ingredient_id": fixture.Ingredient.ID,
+		"target_quantity":      "12",
+		"target_unit":          "l",
+	})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("incompatible target unit status = %d, want 400", response.Code)
+	}
+	response = runRecipeScaleRequest(fixture, constants.WorkspaceRoleViewer, map[string]any{
+		"factor":               2,
+		"anchor_ingredient_id": fixture.Ingredient.ID,
+	})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("factor with anchor status = %d, want 400", response.Code)
+	}
+}
+
+func TestScaleRecipeSavesNewRecipe(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+
+	response := runRecipeScaleRequest(fixture, constants.WorkspaceRoleViewer, map[string]any{"factor": 0.5, "save": true})
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("viewer save status = %d, want 403", response.Code)
+	}
+
+	response = runRecipeScaleRequest(fixture, constants.WorkspaceRoleOwner, map[string]any{"factor": 0.5, "save": true})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("save status = %d body = %s", response.Code, response.Body.String())
+	}
+	result := decodeRecipeScaleResult(t, response)
+	if !result.Saved || result.Recipe.ID == 0 || result.Recipe.Name != "Test recipe x0.5" {
+		t.Fatalf("saved result = %+v", result)
+	}
+
+	var saved models.Recipe
+	if err := database.DB.Preload("RecipeIngredients").First(&saved, result.Recipe.ID).Error; err != nil {
+		t.Fatalf("load saved recipe: %v", err)
+	}
+	if saved.WorkspaceID == nil || *saved.WorkspaceID != fixture.PersonalWorkspace.ID || len(saved.RecipeIngredients) != 1 {
+		t.Fatalf("saved recipe = %+v", saved)
+	}
+	if line := saved.RecipeIngredients[0]; line.IngredientID != fixture.Ingredient.ID || line.Quantity != "500" || line.Unit != "g" {
+		t.Fatalf("saved line = %+v, want 500 g of the ingredient", line)
+	}
+
+	revisions, err := database.ListRecipeRevisions(database.DB, saved.ID)
+	if err != nil || len(revisions) != 1 || len(revisions[0].Ingredients) != 1 {
+		t.Fatalf("saved recipe revisions = %+v err = %v", revisions, err)
+	}
+}
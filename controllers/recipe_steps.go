@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UpdateRecipeSteps replaces the method steps of a recipe
+// @Summary Replace recipe steps
+// @Description Replace all method steps of a recipe with the given ordered list; positions are numbered from 1 in the given order and an empty list removes all steps. Each step has instructions, an optional duration in minutes, drying temperature in °C and humidity in percent, and may reference ingredients of the recipe. The change is recorded as a recipe revision and in the workspace activity.
+// @Tags Recipes
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param steps body models.RecipeStepsUpdateDTO true "Ordered steps"
+// @Success 200 {array} models.RecipeStep
+// @Failure 400 {object} map[string]string "Bad request or ingredient not in recipe"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 500 {object} map[string]string "Failed to update recipe steps"
+// @Router /api/recipes/{id}/steps [put]
+func UpdateRecipeSteps(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+
+	var requestData models.RecipeStepsUpdateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The ingredient lines are checked after ReviseRecipe has locked the recipe, so a line removed
+	// concurrently cannot stay referenced by a step.
+	var missingPosition int
+	var missingIngredientID uint
+	before := recipeContentActivitySnapshot(recipe.ID)
+	_, err := database.ReviseRecipe(database.DB, recipe.ID, userID, constants.RecipeRevisionStepsUpdated, func(tx *gorm.DB) error {
+		var ingredientIDs []uint
+		if err := tx.Model(&models.RecipeIngredient{}).Where("recipe_id = ?", recipe.ID).Distinct().Pluck("ingredient_id", &ingredientIDs).Error; err != nil {
+			return err
+		}
+		inRecipe := make(map[uint]bool, len(ingredientIDs))
+		for _, id := range ingredientIDs {
+			inRecipe[id] = true
+		}
+		for i, step := range requestData.Steps {
+			for _, id := range step.IngredientIDs {
+				if !inRecipe[id] {
+					missingPosition, missingIngredientID = i+1, id
+					return database.ErrStepIngredientNotInRecipe
+				}
+			}
+		}
+		return database.ReplaceRecipeSteps(tx, recipe.ID, requestData.Steps)
+	})
+	if errors.Is(err, database.ErrStepIngredientNotInRecipe) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ingredient is not in recipe", "position": missingPosition, "ingredient_id": missingIngredientID})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to update steps of recipe %d: %v", recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe steps"})
+		return
+	}
+
+	steps, err := database.ListRecipeSteps(database.DB, recipe.ID)
+	if err != nil {
+		log.Printf("Failed to reload steps of recipe %d: %v", recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe steps"})
+		return
+	}
+
+	recordActivity(c, constants.AuditEntityRecipe, recipe.ID, constants.AuditActionUpdate, before, recipeContentActivitySnapshot(recipe.ID))
+	c.JSON(http.StatusOK, steps)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"encoding/json"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"testing"
+)
+
+func putRecipeSteps(fixture workspacePriceFixture, steps []map[string]any) int {
+	return runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateRecipeSteps, http.MethodPut,
+		"/recipes/:id/steps", "/recipes/"+uintToString(fixture.Recipe.ID)+"/steps", map[string]any{"steps": steps}).Code
+}
+
+func TestUpdateRecipeStepsReturnsThemWithRecipe(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+
+	steps := []map[string]any{
+		{"instruction": "Marinate the sliced beef", "duration_minutes": 720, "ingredient_ids": []uint{fixture.Ingredient.ID}},
+		{"instruction": "Dry", "duration_minutes": 360, "temperature_c": 60, "humidity_percent": 20},
+	}
+	if code := putRecipeSteps(fixture, steps); code != http.StatusOK {
+		t.Fatalf("update steps status = %d", code)
+	}
+
+	recipe := getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if len(recipe.Steps) != 2 {
+		t.Fatalf("steps = %+v, want two", recipe.Steps)
+	}
+	marinate, dry := recipe.Steps[0], recipe.Steps[1]
+	if marinate.Position != 1 || marinate.Instruction != "Marinate the sliced beef" || *marinate.DurationMinutes != 720 ||
+		len(marinate.IngredientIDs) != 1 || marinate.IngredientIDs[0] != fixture.Ingredient.ID {
+		t.Fatalf("first step = %+v", marinate)
+	}
+	if dry.Position != 2 || dry.TemperatureC == nil || *dry.TemperatureC != 60 || dry.HumidityPercent == nil || *dry.HumidityPercent != 20 {
+		t.Fatalf("second step = %+v", dry)
+	}
+	var activity models.AuditLog
+	if err := database.DB.Where("entity_type = ? AND entity_id = ?", constants.AuditEntityRecipe, fixture.Recipe.ID).First(&activity).Error; err != nil {
+		t.Fatalf("load steps activity: %v", err)
+	}
+	if activity.Action != constants.AuditActionUpdate || activity.Changes["steps"].After == nil {
+		t.Fatalf("steps activity = %+v, want the new steps", activity)
+	}
+
+	if code := putRecipeSteps(fixture, []map[string]any{{"instruction": "Add salt", "ingredient_ids": []uint{fixture.Ingredient.ID + 100}}}); code != http.StatusBadRequest {
+		t.Fatalf("step with ingredient outside recipe status = %d, want 400", code)
+	}
+	if code := putRecipeSteps(fixture, []map[string]any{{"instruction": "", "duration_minutes": 10}}); code != http.StatusBadRequest {
+		t.Fatalf("step without instruction status = %d, want 400", code)
+	}
+	if code := putRecipeSteps(fixture, []map[string]any{{"instruction": "Dry", "humidity_percent": 120}}); code != http.StatusBadRequest {
+		t.Fatalf("step with humidity above 100 status = %d, want 400", code)
+	}
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, DeleteIngredientFromRecipe, http.MethodDelete,
+		"/recipes/:id/ingredients/:ingredient_id", "/recipes/"+uintToString(fixture.Recipe.ID)+"/ingredients/"+uintToString(fixture.Ingredient.ID))
+	if response.Code != http.StatusOK {
+		t.Fatalf("delete ingredient status = %d body = %s", response.Code, response.Body.String())
+	}
+	recipe = getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if len(recipe.Steps) != 2 || len(recipe.Steps[0].IngredientIDs) != 0 {
+		t.Fatalf("steps after removing the ingredient = %+v, want no ingredient references", recipe.Steps)
+	}
+}
+
+func TestRecipeStepsAreVersioned(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	// Restoring checks that the recipe ingredients are still usable in the workspace
+	if err := database.DB.AutoMigrate(&models.WorkspaceIngredient{}); err != nil {
+		t.Fatalf("migrate workspace ingredients: %v", err)
+	}
+	recipePath := "/recipes/" + uintToString(fixture.Recipe.ID)
+
+	if code := putRecipeSteps(fixture, []map[string]any{{"instruction": "Marinate", "duration_minutes": 720}, {"instruction": "Dry", "duration_minutes": 360}}); code != http.StatusOK {
+		t.Fatalf("first steps status = %d", code)
+	}
+	if code := putRecipeSteps(fixture, []map[string]any{{"instruction": "Marinate", "duration_minutes": 480}}); code != http.StatusOK {
+		t.Fatalf("second steps status = %d", code)
+	}
+
+	revisions, err := database.ListRecipeRevisions(database.DB, fixture.Recipe.ID)
+	if err != nil || len(revisions) != 3 || revisions[0].Action != constants.RecipeRevisionStepsUpdated || len(revisions[0].Steps) != 1 {
+		t.Fatalf("revisions = %+v err = %v", revisions, err)
+	}
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, DiffRecipeRevisions, http.MethodGet,
+		"/recipes/:id/revisions/:revision/diff", recipePath+"/revisions/3/diff")
+	var diff models.RecipeRevisionDiff
+	if err := json.Unmarshal(response.Body.Bytes(), &diff); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("diff status = %d body = %s", response.Code, response.Body.String())
+	}
+	if len(diff.StepChanges) != 2 || diff.StepChanges[0].Position != 1 || diff.StepChanges[1].Position != 2 {
+		t.Fatalf("step changes = %+v, want the changed duration and the removed step", diff.StepChanges)
+	}
+	if duration := diff.StepChanges[0].Changes["duration_minutes"]; duration.Before != float64(720) || duration.After != float64(480) {
+		t.Fatalf("duration change = %+v", duration)
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, RestoreRecipeRevision, http.MethodPost,
+		"/recipes/:id/revisions/:revision/restore", recipePath+"/revisions/2/restore")
+	if response.Code != http.StatusOK {
+		t.Fatalf("restore status = %d body = %s", response.Code, response.Body.String())
+	}
+	recipe := getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if len(recipe.Steps) != 2 || recipe.Steps[1].Instruction != "Dry" || *recipe.Steps[0].DurationMinutes != 720 {
+		t.Fatalf("restored steps = %+v", recipe.Steps)
+	}
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AddSubRecipeToRecipe adds another recipe of the workspace as a line of a recipe
+// @Summary Add a sub-recipe to a recipe
+// @Description Use another recipe of the workspace, such as a marinade or spice blend, as a line of a recipe. Quantity and unit measure the finished output of the sub-recipe, so the sub-recipe needs a yield and the unit must be in the dimension of its yield unit. The line is costed at the finished product cost of the sub-recipe. A recipe cannot contain itself, directly or through its sub-recipes.
+// @Tags Recipe Sub-recipes
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param line body models.RecipeSubRecipeCreateDTO true "Sub-recipe line"
+// @Success 201 {object} models.RecipeSubRecipe
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 409 {object} map[string]string "The sub-recipe would make the recipe contain itself"
+// @Failure 500 {object} map[string]string "Failed to add sub-recipe to recipe"
+// @Router /api/recipes/{id}/sub-recipes [post]
+func AddSubRecipeToRecipe(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+
+	var requestData models.RecipeSubRecipeCreateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var subRecipe models.Recipe
+	if err := database.DB.Where("id = ? AND workspace_id = ?", requestData.SubRecipeID, workspaceID).First(&subRecipe).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sub-recipe ID"})
+		return
+	}
+	if strings.TrimSpace(subRecipe.YieldQuantity) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sub-recipe has no yield"})
+		return
+	}
+	if _, err := utils.ConvertQuantity(requestData.Quantity, requestData.Unit, subRecipe.YieldUnit); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be a number in the dimension of the sub-recipe yield unit", "yield_unit": subRecipe.YieldUnit})
+		return
+	}
+
+	line := models.RecipeSubRecipe{
+		RecipeID:    recipe.ID,
+		SubRecipeID: subRecipe.ID,
+		Quantity:    requestData.Quantity,
+		Unit:        requestData.Unit,
+	}
+
+	if _, err := database.ReviseRecipe(database.DB, recipe.ID, userID, constants.RecipeRevisionSubRecipeAdded, func(tx *gorm.DB) error {
+		if err := database.CheckSubRecipeCycle(tx, recipe.ID, subRecipe.ID); err != nil {
+			return err
+		}
+		return tx.Create(&line).Error
+	}); err != nil {
+		if errors.Is(err, database.ErrSubRecipeCycle) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Sub-recipe would make the recipe contain itself"})
+			return
+		}
+		log.Printf("Failed to add sub-recipe %d to recipe %d: %v", subRecipe.ID, recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add sub-recipe to recipe"})
+		return
+	}
+
+	recordActivity(c, constants.AuditEntityRecipeSubRecipe, line.ID, constants.AuditActionCreate, nil, activitySnapshot(line))
+	line.SubRecipe = &subRecipe
+
+	c.JSON(http.StatusCreated, line)
+}
+
+// DeleteSubRecipeFromRecipe removes the lines of a sub-recipe from a recipe
+// @Summary Delete a sub-recipe from a recipe
+// @Description Remove every line of a sub-recipe from a recipe. The change is recorded as a recipe revision.
+// @Tags Recipe Sub-recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param sub_recipe_id path int true "Sub-recipe ID"
+// @Success 200 {object} map[string]string "Sub-recipe deleted from recipe successfully"
+// @Failure 400 {object} map[string]string "Invalid sub-recipe ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe or sub-recipe line not found"
+// @Failure 500 {object} map[string]string "Failed to delete sub-recipe from recipe"
+// @Router /api/recipes/{id}/sub-recipes/{sub_recipe_id} [delete]
+func DeleteSubRecipeFromRecipe(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+	subRecipeID, err := strconv.Atoi(c.Param("sub_recipe_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sub-recipe ID"})
+		return
+	}
+
+	var lines []models.RecipeSubRecipe
+	if err := database.DB.Where("recipe_id = ? AND sub_recipe_id = ?", recipe.ID, subRecipeID).Find(&lines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete sub-recipe from recipe"})
+		return
+	}
+	if len(lines) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sub-recipe is not used in this recipe"})
+		return
+	}
+
+	if _, err := database.ReviseRecipe(database.DB, recipe.ID, userID, constants.RecipeRevisionSubRecipeRemoved, func(tx *gorm.DB) error {
+		return tx.Where("recipe_id = ? AND sub_recipe_id = ?", recipe.ID, subRecipeID).Delete(&models.RecipeSubRecipe{}).Error
+	}); err != nil {
+		log.Printf("Failed to delete sub-recipe %d from recipe %d: %v", subRecipeID, recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete sub-recipe from recipe"})
+		return
+	}
+
+	for _, line := range lines {
+		recordActivity(c, constants.AuditEntityRecipeSubRecipe, line.ID, constants.AuditActionDelete, activitySnapshot(line), nil)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sub-recipe deleted from recipe successfully"})
+}
+
+// GetRecipeUsages lists the recipes that use a recipe as a sub-recipe
+// @Summary Where a recipe is used
+// @Description List the sub-recipe lines that use a recipe, directly (depth 1) or through other sub-recipes (depth 2 and more, with sub_recipe_id naming the recipe in between).
+// @Tags Recipe Sub-recipes
+// @Security BearerAuth
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Success 200 {array} models.RecipeUsage
+// @Failure 400 {object} map[string]string "Invalid recipe ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 500 {object} map[string]string "Failed to fetch recipe usages"
+// @Router /api/recipes/{id}/where-used [get]
+func GetRecipeUsages(c *gin.Context) {
+	recipe, ok := findWorkspaceRecipe(c)
+	if !ok {
+		return
+	}
+
+	usages, err := database.ListRecipeUsages(database.DB, recipe.ID)
+	if err != nil {
+		log.Printf("Failed to list usages of recipe %d: %v", recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe usages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, usages)
+}
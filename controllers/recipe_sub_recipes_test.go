@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"encoding/json"
+	"math"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+	"testing"
+)
+
+func createSubRecipeFixture(t *testing.T, fixture workspacePriceFixture) models.Recipe {
+	t.Helper()
+
+	// 1 kg of the fixture ingredient makes 2 kg of marinade
+	marinade := models.Recipe{
+		Name:        "Marinade",
+		UserID:      fixture.User.ID,
+		WorkspaceID: &fixture.PersonalWorkspace.ID,
+		RecipeYield: models.RecipeYield{YieldQuantity: "2", YieldUnit: "kg"},
+	}
+	if err := database.DB.Create(&marinade).Error; err != nil {
+		t.Fatalf("create marinade: %v", err)
+	}
+	line := models.RecipeIngredient{RecipeID: marinade.ID, IngredientID: fixture.Ingredient.ID, Quantity: "1", Unit: "kg"}
+	if err := database.DB.Create(&line).Error; err != nil {
+		t.Fatalf("create marinade ingredient: %v", err)
+	}
+	return marinade
+}
+
+func addSubRecipe(fixture workspacePriceFixture, recipeID uint, body map[string]any) int {
+	return runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, AddSubRecipeToRecipe, http.MethodPost,
+		"/recipes/:id/sub-recipes", "/recipes/"+uintToString(recipeID)+"/sub-recipes", body).Code
+}
+
+func TestSubRecipeCostRollsUpIntoRecipe(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	createPrice(t, fixture.User.ID, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID, 10)
+	marinade := createSubRecipeFixture(t, fixture)
+
+	if code := addSubRecipe(fixture, fixture.Recipe.ID, map[string]any{"sub_recipe_id": marinade.ID, "quantity": "500", "unit": "ml"}); code != http.StatusBadRequest {
+		t.Fatalf("add sub-recipe in volume of a mass yield status = %d, want 400", code)
+	}
+	if code := addSubRecipe(fixture, fixture.Recipe.ID, map[string]any{"sub_recipe_id": marinade.ID, "quantity": "500", "unit": "g"}); code != http.StatusCreated {
+		t.Fatalf("add sub-recipe status = %d", code)
+	}
+
+	// 1 kg of the ingredient at 10 plus 500 g of marinade at 5 per kg
+	recipe := getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if math.Abs(recipe.TotalCost-12.5) > 1e-9 {
+		t.Fatalf("recipe total cost = %v, want 12.5", recipe.TotalCost)
+	}
+	if len(recipe.SubRecipes) != 1 || math.Abs(recipe.SubRecipes[0].CalculatedCost-2.5) > 1e-9 {
+		t.Fatalf("sub-recipe lines = %+v, want one costing 2.5", recipe.SubRecipes)
+	}
+	if recipe.SubRecipes[0].SubRecipe == nil || recipe.SubRecipes[0].SubRecipe.Name != "Marinade" {
+		t.Fatalf("sub-recipe line = %+v, want the marinade attached", recipe.SubRecipes[0])
+	}
+
+	revisions, err := database.ListRecipeRevisions(database.DB, fixture.Recipe.ID)
+	if err != nil || len(revisions) != 2 || revisions[0].Action != constants.RecipeRevisionSubRecipeAdded {
+		t.Fatalf("revisions = %+v err = %v, want baseline and sub_recipe_added", revisions, err)
+	}
+	if lines := revisions[0].Ingredients; len(lines) != 2 || lines[1].SubRecipeID != marinade.ID || lines[1].IngredientName != "Marinade" {
+		t.Fatalf("revision lines = %+v, want the marinade line", lines)
+	}
+}
+
+func TestSubRecipeCyclesAreRejected(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	marinade := createSubRecipeFixture(t, fixture)
+	if err := database.DB.Model(&fixture.Recipe).Updates(models.Recipe{RecipeYield: models.RecipeYield{YieldQuantity: "1", YieldUnit: "kg"}}).Error; err != nil {
+		t.Fatalf("set recipe yield: %v", err)
+	}
+
+	if code := addSubRecipe(fixture, marinade.ID, map[string]any{"sub_recipe_id": marinade.ID, "quantity": "1", "unit": "kg"}); code != http.StatusConflict {
+		t.Fatalf("add recipe to itself status = %d, want 409", code)
+	}
+	if code := addSubRecipe(fixture, fixture.Recipe.ID, map[string]any{"sub_recipe_id": marinade.ID, "quantity": "1", "unit": "kg"}); code != http.StatusCreated {
+		t.Fatalf("add sub-recipe status = %d", code)
+	}
+	if code := addSubRecipe(fixture, marinade.ID, map[string]any{"sub_recipe_id": fixture.Recipe.ID, "quantity": "1", "unit": "kg"}); code != http.StatusConflict {
+		t.Fatalf("add parent to sub-recipe status = %d, want 409", code)
+	}
+	if code := addSubRecipe(fixture, fixture.Recipe.ID, map[string]any{"sub_recipe_id": fixture.SecondRecipe.ID, "quantity": "1", "unit": "kg"}); code != http.StatusBadRequest {
+		t.Fatalf("add recipe of another workspace status = %d, want 400", code)
+	}
+}
+
+func TestRecipeWhereUsedAndDeleteProtection(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	marinade := createSubRecipeFixture(t, fixture)
+	if err := database.DB.Model(&fixture.Recipe).Updates(models.Recipe{RecipeYield: models.RecipeYield{YieldQuantity: "1", YieldUnit: "kg"}}).Error; err != nil {
+		t.Fatalf("set recipe yield: %v", err)
+	}
+	platter := models.Recipe{Name: "Platter", UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID}
+	if err := database.DB.Create(&platter).Error; err != nil {
+		t.Fatalf("create platter: %v", err)
+	}
+
+	if code := addSubRecipe(fixture, fixture.Recipe.ID, map[string]any{"sub_recipe_id": marinade.ID, "quantity": "300", "unit": "g"}); code != http.StatusCreated {
+		t.Fatalf("add marinade status = %d", code)
+	}
+	if code := addSubRecipe(fixture, platter.ID, map[string]any{"sub_recipe_id": fixture.Recipe.ID, "quantity": "2", "unit": "kg"}); code != http.StatusCreated {
+		t.Fatalf("add recipe to platter status = %d", code)
+	}
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipeUsages, http.MethodGet,
+		"/recipes/:id/where-used", "/recipes/"+uintToString(marinade.ID)+"/where-used")
+	if response.Code != http.StatusOK {
+		t.Fatalf("where-used status = %d body = %s", response.Code, response.Body.String())
+	}
+	var usages []models.RecipeUsage
+	if err := json.Unmarshal(response.Body.Bytes(), &usages); err != nil {
+		t.Fatalf("decode usages: %v", err)
+	}
+	if len(usages) != 2 {
+		t.Fatalf("usages = %+v, want direct and indirect", usages)
+	}
+	if usages[0].RecipeID != fixture.Recipe.ID || usages[0].Depth != 1 || usages[0].Quantity != "300" {
+		t.Fatalf("direct usage = %+v", usages[0])
+	}
+	if usages[1].RecipeID != platter.ID || usages[1].Depth != 2 || usages[1].SubRecipeID != fixture.Recipe.ID {
+		t.Fatalf("indirect usage = %+v", usages[1])
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, DeleteRecipe, http.MethodDelete,
+		"/recipes/:id", "/recipes/"+uintToString(marinade.ID))
+	if response.Code != http.StatusConflict {
+		t.Fatalf("delete used sub-recipe status = %d, want 409", response.Code)
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, DeleteSubRecipeFromRecipe, http.MethodDelete,
+		"/recipes/:id/sub-recipes/:sub_recipe_id", "/recipes/"+uintToString(fixture.Recipe.ID)+"/sub-recipes/"+uintToString(marinade.ID))
+	if response.Code != http.StatusOK {
+		t.Fatalf("delete sub-recipe line status = %d body = %s", response.Code, response.Body.String())
+	}
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, DeleteRecipe, http.MethodDelete,
+		"/recipes/:id", "/recipes/"+uintToString(marinade.ID))
+	if response.Code != http.StatusOK {
+		t.Fatalf("delete unused sub-recipe status = %d body = %s", response.Code, response.Body.String())
+	}
+}
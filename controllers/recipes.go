@@ -1,24 +1,30 @@
 package controllers
 
 import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
 	"mobile-backend-go/models"
 	"mobile-backend-go/utils" // Import utils package
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetRecipes returns list of all recipes with optional filtering by recipe ID and ingredient ID
 // @Summary Get list of recipes
-// @Description Get all recipes available for the authenticated user with optional filtering by recipe_id and ingredient_id
+// @Description Get all recipes available for the authenticated user with optional filtering by recipe_id and ingredient_id. Recipes with a yield also return the finished quantity and the cost per kg and per piece of finished product. Recipes are costed at the latest prices, or at the prices effective on as_of. Nutrition is given per batch, per 100 g of finished product and per piece.
 // @Tags Recipes
 // @Security BearerAuth
 // @Produce  json
 // @Param X-Workspace-ID header int false "Workspace ID"
 // @Param recipe_id query int false "Filter by Recipe ID" example(1)
 // @Param ingredient_id query int false "Filter by Ingredient ID" example(3)
+// @Param as_of query string false "Cost at the prices effective on this date (YYYY-MM-DD or RFC 3339)"
 // @Success 200 {array} models.Recipe
 // @Failure 400 {object} map[string]string "Invalid parameters"
 // @Failure 401 {object} map[string]string "Unauthorized"
@@ -52,8 +58,15 @@ func GetRecipes(c *gin.Context) {
 		ingredientID = uint(id)
 	}
 
+	asOf, ok := parseRecipeCostAsOf(c)
+	if !ok {
+		return
+	}
+
 	query := database.DB.Where("workspace_id = ?", workspaceID).
-		Preload("RecipeIngredients.Ingredient")
+		Preload("RecipeIngredients.Ingredient").
+		Preload("SubRecipes").
+		Preload("Steps", orderRecipeSteps)
 
 	// Apply filters if parameters are provided
 	if recipeID != 0 {
@@ -71,24 +84,14 @@ func GetRecipes(c *gin.Context) {
 	}
 
 	// Calculate total cost for each recipe
-	for i, recipe := range recipes {
-		totalCost := 0.0
-		for j, ri := range recipe.RecipeIngredients {
-			// Load latest price for each ingredient
-			var latestPrice models.Price
-			if err := database.DB.Where("ingredient_id = ? AND workspace_id = ?", ri.IngredientID, workspaceID).
-				Order(latestPriceOrder).
-				Limit(1).
-				First(&latestPrice).Error; err == nil {
-				recipe.RecipeIngredients[j].Ingredient.Prices = []models.Price{latestPrice} // Assign latest price manually
-				cost, err := utils.CalculateIngredientCost(latestPrice.Price, latestPrice.Quantity, latestPrice.Unit, ri.Quantity, ri.Unit)
-				if err == nil {
-					recipes[i].RecipeIngredients[j].CalculatedCost = cost // Assign calculated cost
-					totalCost += cost
-				}
-			}
-		}
-		recipes[i].TotalCost = totalCost // Add total cost to response, but not save to database
+	calculator := newRecipeCostCalculator(workspaceID)
+	calculator.asOf = asOf
+	for i := range recipes {
+		calculator.apply(&recipes[i])
+	}
+	nutritionCalculator := newRecipeNutritionCalculator(workspaceID)
+	for i := range recipes {
+		nutritionCalculator.apply(&recipes[i])
 	}
 
 	c.JSON(http.StatusOK, recipes)
@@ -96,14 +99,15 @@ func GetRecipes(c *gin.Context) {
 
 // GetRecipe returns a single recipe by ID
 // @Summary Get a recipe
-// @Description Get a recipe by its ID for the authenticated user
+// @Description Get a recipe by its ID for the authenticated user with its ingredient and sub-recipe lines and method steps in order. Recipes with a yield also return the finished quantity and the cost per kg and per piece of finished product. The recipe is costed at the latest prices, or at the prices effective on as_of. Nutrition is given per batch, per 100 g of finished product and per piece.
 // @Tags Recipes
 // @Security BearerAuth
 // @Produce  json
 // @Param X-Workspace-ID header int false "Workspace ID"
 // @Param id path int true "Recipe ID"
+// @Param as_of query string false "Cost at the prices effective on this date (YYYY-MM-DD or RFC 3339)"
 // @Success 200 {object} models.Recipe
-// @Failure 400 {object} map[string]string "Invalid recipe ID"
+// @Failure 400 {object} map[string]string "Invalid recipe ID or as_of date"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Recipe not found"
 // @Router /api/recipes/{id} [get]
@@ -114,40 +118,32 @@ func GetRecipe(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
 		return
 	}
+	asOf, ok := parseRecipeCostAsOf(c)
+	if !ok {
+		return
+	}
 
 	var recipe models.Recipe
 	if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, workspaceID).
 		Preload("RecipeIngredients.Ingredient").
+		Preload("SubRecipes").
+		Preload("Steps", orderRecipeSteps).
 		First(&recipe).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
 		return
 	}
 
 	// Calculate total cost of recipe
-	totalCost := 0.0
-	for j, ri := range recipe.RecipeIngredients {
-		// Load latest price for each ingredient
-		var latestPrice models.Price
-		if err := database.DB.Where("ingredient_id = ? AND workspace_id = ?", ri.IngredientID, workspaceID).
-			Order(latestPriceOrder).
-			Limit(1).
-			First(&latestPrice).Error; err == nil {
-			recipe.RecipeIngredients[j].Ingredient.Prices = []models.Price{latestPrice} // Assign latest price manually
-			cost, err := utils.CalculateIngredientCost(latestPrice.Price, latestPrice.Quantity, latestPrice.Unit, ri.Quantity, ri.Unit)
-			if err == nil {
-				recipe.RecipeIngredients[j].CalculatedCost = cost // Assign calculated cost
-				totalCost += cost
-			}
-		}
-	}
-
-	recipe.TotalCost = totalCost // Add total cost to response, but not save to database
+	calculator := newRecipeCostCalculator(workspaceID)
+	calculator.asOf = asOf
+	calculator.apply(&recipe)
+	newRecipeNutritionCalculator(workspaceID).apply(&recipe)
 	c.JSON(http.StatusOK, recipe)
 }
 
 // CreateRecipe creates a new recipe
 // @Summary Create a new recipe
-// @Description Create a new recipe for the authenticated user
+// @Description Create a new recipe for the authenticated user, optionally with its yield: yield_quantity and yield_unit of one batch, process_loss (0 to below 1) and the piece_size and piece_unit of one finished piece
 // @Tags Recipes
 // @Security BearerAuth
 // @Accept  json
@@ -167,24 +163,93 @@ func CreateRecipe(c *gin.Context) {
 		return
 	}
 
+	if err := validateRecipeYield(requestData.RecipeYield); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Create Recipe model from DTO
 	newRecipe := models.Recipe{
 		Name:        requestData.Name,
+		RecipeYield: requestData.RecipeYield,
 		UserID:      userID,
 		WorkspaceID: &workspaceID,
 	}
 
-	if err := database.DB.Create(&newRecipe).Error; err != nil {
+	if _, err := database.CreateRecipe(database.DB, &newRecipe); err != nil {
+		log.Printf("Failed to create recipe: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recipe"})
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityRecipe, newRecipe.ID, constants.AuditActionCreate, nil, activitySnapshot(newRecipe))
 	c.JSON(http.StatusCreated, newRecipe)
 }
 
+// UpdateRecipe updates the name and yield of a recipe
+// @Summary Update a recipe
+// @Description Replace the name and yield of a recipe in the current workspace; omitted yield fields are cleared. The change is recorded as a recipe revision.
+// @Tags Recipes
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param recipe body models.RecipeUpdateDTO true "Recipe data"
+// @Success 200 {object} models.Recipe
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 500 {object} map[string]string "Failed to update recipe"
+// @Router /api/recipes/{id} [put]
+func UpdateRecipe(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	var requestData models.RecipeUpdateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateRecipeYield(requestData.RecipeYield); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var recipe models.Recipe
+	if err := database.DB.Where("id = ? AND workspace_id = ?", recipeID, workspaceID).First(&recipe).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+	before := activitySnapshot(recipe)
+
+	if _, err := database.ReviseRecipe(database.DB, recipe.ID, userID, constants.RecipeRevisionUpdated, func(tx *gorm.DB) error {
+		return database.UpdateRecipeDetails(tx, recipe.ID, requestData.Name, requestData.RecipeYield)
+	}); err != nil {
+		log.Printf("Failed to update recipe %d: %v", recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe"})
+		return
+	}
+
+	if err := database.DB.First(&recipe, recipe.ID).Error; err != nil {
+		log.Printf("Failed to reload recipe %d: %v", recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe"})
+		return
+	}
+	recordActivity(c, constants.AuditEntityRecipe, recipe.ID, constants.AuditActionUpdate, before, activitySnapshot(recipe))
+
+	c.JSON(http.StatusOK, recipe)
+}
+
 // DeleteRecipe deletes a recipe by ID
 // @Summary Delete a recipe
-// @Description Delete a recipe by its ID for the authenticated user
+// @Description Delete a recipe by its ID for the authenticated user. Recipes used as a sub-recipe of other recipes cannot be deleted.
 // @Tags Recipes
 // @Security BearerAuth
 // @Param id path int true "Recipe ID"
@@ -192,6 +257,7 @@ func CreateRecipe(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Invalid recipe ID"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 409 {object} map[string]string "Recipe is used as a sub-recipe"
 // @Router /api/recipes/{id} [delete]
 func DeleteRecipe(c *gin.Context) {
 	workspaceID := c.MustGet("workspaceID").(uint)
@@ -207,10 +273,55 @@ func DeleteRecipe(c *gin.Context) {
 		return
 	}
 
+	used, err := database.RecipeUsedAsSubRecipe(database.DB, recipe.ID)
+	if err != nil {
+		log.Printf("Failed to check usages of recipe %d: %v", recipe.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recipe"})
+		return
+	}
+	if used {
+		c.JSON(http.StatusConflict, gin.H{"error": "Recipe is used as a sub-recipe of other recipes"})
+		return
+	}
+
 	if err := database.DB.Delete(&recipe).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recipe"})
 		return
 	}
 
+	recordActivity(c, constants.AuditEntityRecipe, recipe.ID, constants.AuditActionDelete, activitySnapshot(recipe), nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Recipe deleted successfully"})
 }
+
+// orderRecipeSteps preloads recipe steps in their order.
+func orderRecipeSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// applyRecipeYieldCosts spreads the total cost of recipe over its finished output.
+// Recipes without a usable yield keep empty finished product costs.
+func applyRecipeYieldCosts(recipe *models.Recipe) {
+	if strings.TrimSpace(recipe.YieldQuantity) == "" {
+		return
+	}
+	costs, err := utils.CalculateYieldCosts(recipe.TotalCost, recipe.YieldQuantity, recipe.YieldUnit, recipe.ProcessLoss, recipe.PieceSize, recipe.PieceUnit)
+	if err != nil {
+		return
+	}
+	recipe.FinishedQuantity = &costs.FinishedQuantity
+	recipe.CostPerKg = costs.CostPerKg
+	recipe.CostPerPiece = costs.CostPerPiece
+}
+
+// validateRecipeYield checks that a recipe yield, when set, can be costed.
+func validateRecipeYield(yield models.RecipeYield) error {
+	if strings.TrimSpace(yield.YieldQuantity) == "" {
+		if strings.TrimSpace(yield.PieceSize) != "" {
+			return errors.New("piece_size requires yield_quantity")
+		}
+		return nil
+	}
+	_, err := utils.CalculateYieldCosts(0, yield.YieldQuantity, yield.YieldUnit, yield.ProcessLoss, yield.PieceSize, yield.PieceUnit)
+	return err
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSecurityEventLimit = 50
+	maxSecurityEventLimit     = 200
+)
+
+// GetSecurityEvents returns the recent security events of the authenticated user.
+// @Summary Get sign-in history
+// @Description List recent sign-ins, failed sign-ins, lockouts, password changes and resets, email verification, refresh token reuse, two-factor changes and revoked sessions of the authenticated user, newest first.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param type query string false "login_succeeded, login_failed, account_locked, password_changed, password_reset, email_verified, refresh_token_reuse, two_factor_enabled, two_factor_disabled, recovery_code_used or session_revoked"
+// @Param limit query int false "Number of events (default 50, max 200)"
+// @Success 200 {array} models.SecurityEvent
+// @Failure 400 {object} map[string]string "Invalid parameters"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/security-events [get]
+func GetSecurityEvents(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	eventType := c.Query("type")
+	if eventType != "" && !constants.IsValidSecurityEventType(eventType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event type"})
+		return
+	}
+	limit, ok := parsePositiveIntQuery(c, "limit", defaultSecurityEventLimit)
+	if !ok {
+		return
+	}
+	if limit > maxSecurityEventLimit {
+		limit = maxSecurityEventLimit
+	}
+
+	events, err := database.ListSecurityEvents(database.DB, userID, eventType, limit)
+	if err != nil {
+		log.Printf("Failed to fetch security events for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch security events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// recordSecurityEvent stores a security event for userID with the client address of the request.
+// Failures are logged instead of failing the request.
+func recordSecurityEvent(c *gin.Context, userID uint, eventType string) {
+	event := models.SecurityEvent{
+		UserID:    userID,
+		Type:      eventType,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err := database.RecordSecurityEvent(database.DB, &event); err != nil {
+		log.Printf("Failed to record %s event for user %d: %v", eventType, userID, err)
+	}
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions lists the signed-in devices of the authenticated user.
+// @Summary List active sessions
+// @Description List sessions that are not revoked and can still be refreshed, most recently seen first. The session of the calling token has current set.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.AuthSession
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/sessions [get]
+func GetSessions(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	currentSessionID := c.GetUint("sessionID")
+
+	sessions, err := database.ListActiveAuthSessions(database.DB, userID)
+	if err != nil {
+		log.Printf("Failed to list sessions of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load sessions"})
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs one device of the authenticated user out.
+// @Summary Revoke a session
+// @Description Revoke a session of the authenticated user. Its refresh token and access tokens stop working immediately; revoking the current session logs the caller out.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} map[string]string "Session revoked"
+// @Failure 400 {object} map[string]string "Invalid session ID"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Session not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || sessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := database.RevokeUserAuthSession(database.DB, userID, uint(sessionID), database.SessionRevokedByUser); err != nil {
+		if errors.Is(err, database.ErrAuthSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		log.Printf("Failed to revoke session %d of user %d: %v", sessionID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	recordSecurityEvent(c, userID, constants.SecurityEventSessionRevoked)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeOtherSessions signs every other device of the authenticated user out.
+// @Summary Revoke other sessions
+// @Description Revoke every session of the authenticated user except the one of the calling token.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Number of revoked sessions"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/sessions [delete]
+func RevokeOtherSessions(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	revoked, err := database.RevokeOtherAuthSessions(database.DB, userID, c.GetUint("sessionID"), database.SessionRevokedByUser)
+	if err != nil {
+		log.Printf("Failed to revoke sessions of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	if revoked > 0 {
+		recordSecurityEvent(c, userID, constants.SecurityEventSessionRevoked)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked", "revoked": revoked})
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+const (
+	phoneUserAgent  = "JerkyVault/2.3 (iOS 19.1)"
+	laptopUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36"
+)
+
+func TestSessionsCanBeListedAndRevoked(t *testing.T) {
+	router := setupAuthTest(t)
+	phone := loginWithUserAgent(t, router, phoneUserAgent)
+	laptop := loginWithUserAgent(t, router, laptopUserAgent)
+
+	sessions := listSessions(t, router, laptop.Token)
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %+v, want two", sessions)
+	}
+	var phoneSession, laptopSession models.AuthSession
+	for _, session := range sessions {
+		switch session.DeviceName {
+		case "JerkyVault on iOS":
+			phoneSession = session
+		case "Chrome on Windows":
+			laptopSession = session
+		}
+	}
+	if phoneSession.ID == 0 || laptopSession.ID == 0 || phoneSession.Current || !laptopSession.Current || laptopSession.LastSeenAt == nil {
+		t.Fatalf("sessions = %+v", sessions)
+	}
+
+	// Requests refresh last_seen_at once the interval has passed.
+	stale := time.Now().Add(-time.Hour)
+	database.DB.Model(&models.AuthSession{}).Where("id = ?", laptopSession.ID).Update("last_seen_at", stale)
+	if status := getWithToken(router, laptop.Token); status != http.StatusOK {
+		t.Fatalf("laptop access token status = %d", status)
+	}
+	var touched models.AuthSession
+	database.DB.First(&touched, laptopSession.ID)
+	if touched.LastSeenAt == nil || !touched.LastSeenAt.After(stale.Add(time.Minute)) {
+		t.Fatalf("last seen = %v, want refreshed", touched.LastSeenAt)
+	}
+
+	response := sendAuthJSON(router, http.MethodDelete, fmt.Sprintf("/profile/sessions/%d", phoneSession.ID), laptop.Token, nil)
+	if response.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d body = %s", response.Code, response.Body.String())
+	}
+	if status := getWithToken(router, phone.Token); status != http.StatusUnauthorized {
+		t.Fatalf("revoked phone token status = %d, want 401", status)
+	}
+	postAuthJSON(t, router, "/auth/refresh", map[string]any{"refresh_token": phone.RefreshToken}, http.StatusUnauthorized)
+	if response := sendAuthJSON(router, http.MethodDelete, fmt.Sprintf("/profile/sessions/%d", phoneSession.ID), laptop.Token, nil); response.Code != http.StatusNotFound {
+		t.Fatalf("revoke again status = %d, want 404", response.Code)
+	}
+
+	tablet := loginWithUserAgent(t, router, "Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/604.1")
+	response = sendAuthJSON(router, http.MethodDelete, "/profile/sessions", laptop.Token, nil)
+	var revoked struct {
+		Revoked int64 `json:"revoked"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &revoked); err != nil || revoked.Revoked != 1 {
+		t.Fatalf("revoke others = %s, want one session", response.Body.String())
+	}
+	if status := getWithToken(router, tablet.Token); status != http.StatusUnauthorized {
+		t.Fatalf("tablet token status = %d, want 401", status)
+	}
+	if sessions := listSessions(t, router, laptop.Token); len(sessions) != 1 || !sessions[0].Current {
+		t.Fatalf("sessions after revoking others = %+v", sessions)
+	}
+}
+
+func loginWithUserAgent(t *testing.T, router *gin.Engine, userAgent string) TokenResponse {
+	t.Helper()
+
+	payload, _ := json.Marshal(map[string]any{"username": "auth-user", "password": authTestPassword})
+	request := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", userAgent)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	var tokens TokenResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &tokens); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("login status = %d body = %s", response.Code, response.Body.String())
+	}
+	return tokens
+}
+
+func listSessions(t *testing.T, router *gin.Engine, token string) []models.AuthSession {
+	t.Helper()
+
+	response := sendAuthJSON(router, http.MethodGet, "/profile/sessions", token, nil)
+	var sessions []models.AuthSession
+	if err := json.Unmarshal(response.Body.Bytes(), &sessions); err != nil || response.Code != http.StatusOK {
+		t.Fatalf("list sessions status = %d body = %s", response.Code, response.Body.String())
+	}
+	return sessions
+}
@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	totpIssuer         = "BatchVault"
+	recoveryCodeCount  = 10
+	recoveryCodeDigits = 10
+)
+
+// TwoFactorStatusResponse describes the two-factor setup of the authenticated user.
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Pending                bool  `json:"pending"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorEnrollmentResponse carries a new TOTP secret and the URI authenticator apps scan.
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse carries recovery codes; they are shown only once.
+type RecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallengeResponse is returned by login when a second factor is required.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired  bool      `json:"two_factor_required"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+// GetTwoFactorStatus returns the two-factor setup of the authenticated user.
+// @Summary Get two-factor status
+// @Description Report whether TOTP two-factor authentication is enabled or awaiting confirmation, and how many recovery codes are left.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} TwoFactorStatusResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/2fa [get]
+func GetTwoFactorStatus(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	twoFactor, found, err := database.FindTwoFactor(database.DB, userID)
+	if err != nil {
+		log.Printf("Failed to load two-factor status of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load two-factor status"})
+		return
+	}
+
+	status := TwoFactorStatusResponse{
+		Enabled: found && twoFactor.EnabledAt != nil,
+		Pending: found && twoFactor.EnabledAt == nil,
+	}
+	if status.Enabled {
+		status.RecoveryCodesRemaining, err = database.CountUnusedRecoveryCodes(database.DB, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load two-factor status"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// EnrollTwoFactor starts TOTP enrollment for the authenticated user.
+// @Summary Start two-factor enrollment
+// @Description Create a new TOTP secret and return it with its otpauth:// URI for authenticator apps. Two-factor authentication is enabled once a code is confirmed at /api/profile/2fa/confirm. Starting again replaces a pending secret.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} TwoFactorEnrollmentResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Two-factor authentication already enabled"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/2fa/enroll [post]
+func EnrollTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create secret"})
+		return
+	}
+
+	if err := database.StartTwoFactorEnrollment(database.DB, user.ID, secret); err != nil {
+		if errors.Is(err, database.ErrTwoFactorAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+		log.Printf("Failed to start two-factor enrollment of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPProvisioningURI(totpIssuer, user.Username, secret),
+	})
+}
+
+// ConfirmTwoFactor enables two-factor authentication with a code from the authenticator app.
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication by confirming a current TOTP code for the pending secret. The response contains single-use recovery codes, which are not shown again.
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodeDTO true "TOTP code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} map[string]string "Invalid code or no pending enrollment"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Two-factor authentication already enabled"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/2fa/confirm [post]
+func ConfirmTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var payload models.TwoFactorCodeDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	twoFactor, found, err := database.FindTwoFactor(database.DB, userID)
+	if err != nil {
+		log.Printf("Failed to load two-factor enrollment of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start two-factor enrollment first"})
+		return
+	}
+	if twoFactor.EnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(twoFactor.Secret, payload.Code, time.Now())
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recovery codes"})
+		return
+	}
+	if err := database.EnableTwoFactor(database.DB, userID, step, hashes); err != nil {
+		if errors.Is(err, database.ErrTwoFactorAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+		log.Printf("Failed to enable two-factor authentication of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	recordSecurityEvent(c, userID, constants.SecurityEventTwoFactorEnabled)
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{
+		Message:       "Two-factor authentication enabled",
+		RecoveryCodes: codes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the authenticated user.
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after confirming a TOTP or recovery code. Earlier recovery codes stop working.
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodeDTO true "TOTP or recovery code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} map[string]string "Invalid code or two-factor authentication not enabled"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var payload models.TwoFactorCodeDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	valid, _, err := checkSecondFactor(userID, payload.Code)
+	if err != nil {
+		log.Printf("Failed to check second factor of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recovery codes"})
+		return
+	}
+	if err := database.ReplaceRecoveryCodes(database.DB, userID, hashes); err != nil {
+		log.Printf("Failed to replace recovery codes of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{
+		Message:       "Recovery codes regenerated",
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTwoFactor turns two-factor authentication off for the authenticated user.
+// @Summary Disable two-factor authentication
+// @Description Remove the TOTP secret and recovery codes after confirming the password and a TOTP or recovery code. Workspaces that require two-factor authentication deny access afterwards.
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorDisableDTO true "Password and TOTP or recovery code"
+// @Success 200 {object} map[string]string "Two-factor authentication disabled"
+// @Failure 400 {object} map[string]string "Invalid code or two-factor authentication not enabled"
+// @Failure 401 {object} map[string]string "Unauthorized or wrong password"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/profile/2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var payload models.TwoFactorDisableDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !utils.CheckPassword(user.Password, payload.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return
+	}
+
+	valid, _, err := checkSecondFactor(userID, payload.Code)
+	if err != nil {
+		log.Printf("Failed to check second factor of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if err := database.DisableTwoFactor(database.DB, userID); err != nil {
+		log.Printf("Failed to disable two-factor authentication of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	recordSecurityEvent(c, userID, constants.SecurityEventTwoFactorDisabled)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// VerifyTwoFactorLogin completes a login with a TOTP or recovery code.
+// @Summary Complete two-factor login
+// @Description Exchange the challenge token returned by login and a current TOTP code, or an unused recovery code, for an access and refresh token. Wrong codes count as failed logins; a challenge accepts at most five codes.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorLoginDTO true "Challenge token and code"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Invalid code or expired challenge"
+// @Failure 429 {object} map[string]interface{} "Too many failed attempts; retry_after seconds"
+// @Router /api/auth/2fa/verify [post]
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var payload models.TwoFactorLoginDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	challenge, err := database.FindLoginChallenge(database.DB, utils.HashToken(payload.ChallengeToken))
+	if err != nil {
+		if errors.Is(err, database.ErrOneTimeCodeInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+			return
+		}
+		log.Printf("Failed to load login challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, challenge.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	now := time.Now()
+	lockedUntil, err := database.LoginLockedUntil(database.DB, user.Username, now)
+	if err != nil {
+		log.Printf("Failed to check login lockout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	if lockedUntil != nil {
+		respondLoginLocked(c, *lockedUntil, now)
+		return
+	}
+
+	valid, usedRecoveryCode, err := checkSecondFactor(user.ID, payload.Code)
+	if err != nil {
+		log.Printf("Failed to check second factor of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	if !valid {
+		if err := database.FailLoginChallenge(database.DB, challenge); err != nil {
+			log.Printf("Failed to record failed login challenge %d: %v", challenge.ID, err)
+		}
+		lockedUntil, err := database.RecordFailedLogin(database.DB, user.Username, now)
+		if err != nil {
+			log.Printf("Failed to record failed login: %v", err)
+		}
+		recordSecurityEvent(c, user.ID, constants.SecurityEventLoginFailed)
+		if lockedUntil != nil {
+			recordSecurityEvent(c, user.ID, constants.SecurityEventAccountLocked)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if err := database.CompleteLoginChallenge(database.DB, challenge.ID); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+	if usedRecoveryCode {
+		recordSecurityEvent(c, user.ID, constants.SecurityEventRecoveryCodeUsed)
+	}
+
+	completeLogin(c, user)
+}
+
+// respondTwoFactorChallenge issues a login challenge for userID, whose password was accepted.
+func respondTwoFactorChallenge(c *gin.Context, userID uint) {
+	challengeToken, err := utils.GenerateSecureToken(refreshTokenByteSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create challenge"})
+		return
+	}
+
+	expiresAt, err := database.IssueLoginChallenge(database.DB, userID, utils.HashToken(challengeToken))
+	if err != nil {
+		log.Printf("Failed to issue login challenge for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     challengeToken,
+		ChallengeExpiresAt: expiresAt,
+	})
+}
+
+// checkSecondFactor accepts a TOTP code that was not used before or an unused recovery code of userID.
+// It reports whether the code was valid and whether it was a recovery code.
+func checkSecondFactor(userID uint, code string) (bool, bool, error) {
+	twoFactor, found, err := database.FindTwoFactor(database.DB, userID)
+	if err != nil || !found || twoFactor.EnabledAt == nil {
+		return false, false, err
+	}
+
+	code = normalizeRecoveryCode(code)
+	if len(code) == utils.TOTPDigits {
+		step, valid := utils.ValidateTOTP(twoFactor.Secret, code, time.Now())
+		if !valid {
+			return false, false, nil
+		}
+		accepted, err := database.AcceptTOTPStep(database.DB, userID, step)
+		return accepted, false, err
+	}
+
+	used, err := database.UseRecoveryCode(database.DB, userID, func(codeHash string) bool {
+		return utils.CheckPassword(codeHash, code)
+	})
+	return used, used, err
+}
+
+// generateRecoveryCodes returns recovery codes formatted for display and their bcrypt hashes.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateNumericCode(recoveryCodeDigits)
+		if err != nil {
+			return nil, nil, err
+		}
+		half := recoveryCodeDigits / 2
+		codes = append(codes, code[:half]+"-"+code[half:])
+		hash, err := utils.HashPassword(code)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode strips the separators users may type along with a code.
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code))
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+)
+
+func TestTwoFactorLoginWithTOTPAndRecoveryCodes(t *testing.T) {
+	router := setupAuthTest(t)
+	credentials := map[string]any{"username": "auth-user", "password": authTestPassword}
+	login := postAuthJSON(t, router, "/auth/login", credentials, http.StatusOK)
+
+	response := sendAuthJSON(router, http.MethodPost, "/profile/2fa/enroll", login.Token, map[string]any{})
+	var enrollment TwoFactorEnrollmentResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &enrollment); err != nil || response.Code != http.StatusOK || enrollment.Secret == "" {
+		t.Fatalf("enroll status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	if response := sendAuthJSON(router, http.MethodPost, "/profile/2fa/confirm", login.Token, map[string]any{"code": "000000"}); response.Code != http.StatusBadRequest {
+		t.Fatalf("confirm with wrong code status = %d, want 400", response.Code)
+	}
+	confirmCode, err := utils.TOTPCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	response = sendAuthJSON(router, http.MethodPost, "/profile/2fa/confirm", login.Token, map[string]any{"code": confirmCode})
+	var recovery RecoveryCodesResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &recovery); err != nil || response.Code != http.StatusOK || len(recovery.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("confirm status = %d body = %s", response.Code, response.Body.String())
+	}
+	var stored models.RecoveryCode
+	if err := database.DB.First(&stored).Error; err != nil || !strings.HasPrefix(stored.CodeHash, "$2") {
+		t.Fatalf("stored recovery code = %q (%v), want a bcrypt hash", stored.CodeHash, err)
+	}
+	if response := sendAuthJSON(router, http.MethodPost, "/profile/2fa/enroll", login.Token, map[string]any{}); response.Code != http.StatusConflict {
+		t.Fatalf("enroll again status = %d, want 409", response.Code)
+	}
+
+	// The password alone no longer yields tokens.
+	challenge := startTwoFactorLogin(t, router, credentials)
+	verify := map[string]any{"challenge_token": challenge.ChallengeToken, "code": confirmCode}
+	postAuthJSON(t, router, "/auth/2fa/verify", verify, http.StatusUnauthorized)
+	nextCode, _ := utils.TOTPCode(enrollment.Secret, time.Now().Add(utils.TOTPPeriod))
+	verify["code"] = nextCode
+	tokens := postAuthJSON(t, router, "/auth/2fa/verify", verify, http.StatusOK)
+	if status := getWithToken(router, tokens.Token); status != http.StatusOK {
+		t.Fatalf("two-factor access token status = %d", status)
+	}
+	postAuthJSON(t, router, "/auth/2fa/verify", verify, http.StatusUnauthorized)
+
+	challenge = startTwoFactorLogin(t, router, credentials)
+	postAuthJSON(t, router, "/auth/2fa/verify", map[string]any{"challenge_token": challenge.ChallengeToken, "code": recovery.RecoveryCodes[0]}, http.StatusOK)
+	challenge = startTwoFactorLogin(t, router, credentials)
+	postAuthJSON(t, router, "/auth/2fa/verify", map[string]any{"challenge_token": challenge.ChallengeToken, "code": recovery.RecoveryCodes[0]}, http.StatusUnauthorized)
+
+	response = sendAuthJSON(router, http.MethodGet, "/profile/2fa", tokens.Token, nil)
+	var status TwoFactorStatusResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &status); err != nil || !status.Enabled || status.RecoveryCodesRemaining != recoveryCodeCount-1 {
+		t.Fatalf("status = %s", response.Body.String())
+	}
+
+	response = sendAuthJSON(router, http.MethodPost, "/profile/2fa/disable", tokens.Token, map[string]any{"password": authTestPassword, "code": recovery.RecoveryCodes[1]})
+	if response.Code != http.StatusOK {
+		t.Fatalf("disable status = %d body = %s", response.Code, response.Body.String())
+	}
+	if login := postAuthJSON(t, router, "/auth/login", credentials, http.StatusOK); login.Token == "" {
+		t.Fatal("login after disabling two-factor authentication returned no token")
+	}
+}
+
+func startTwoFactorLogin(t *testing.T, router *gin.Engine, credentials map[string]any) TwoFactorChallengeResponse {
+	t.Helper()
+
+	response := sendAuthJSON(router, http.MethodPost, "/auth/login", "", credentials)
+	var challenge TwoFactorChallengeResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &challenge); err != nil || response.Code != http.StatusOK || !challenge.TwoFactorRequired || challenge.ChallengeToken == "" {
+		t.Fatalf("login status = %d body = %s, want a two-factor challenge", response.Code, response.Body.String())
+	}
+	return challenge
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportWorkspace returns all workspace-scoped data as a versioned JSON bundle.
+// @Summary Export workspace data
+// @Description Export recipes with ingredients, prices, packages, products with options, clients, orders with items, workspace ingredient metadata and settings as a portable JSON bundle. Rows referencing rows outside the workspace are left out and listed in skipped. Requires owner or manager role.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Success 200 {object} models.WorkspaceBundle
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/export [get]
+func ExportWorkspace(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	bundle, err := database.ExportWorkspaceBundle(database.DB, workspaceID)
+	if err != nil {
+		log.Printf("Failed to export workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export workspace"})
+		return
+	}
+
+	filename := bundle.Workspace.Slug
+	if filename == "" {
+		filename = fmt.Sprintf("workspace-%d", workspaceID)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-export.json"`, filename))
+	c.JSON(http.StatusOK, bundle)
+}
+
+// ImportWorkspace adds the rows of a workspace bundle to the current workspace.
+// @Summary Import workspace data
+// @Description Import a bundle produced by the export endpoint into the current workspace. IDs are remapped, ingredients are linked to global ingredients by name (missing ones are created) and everything runs in one transaction. Settings are applied only when the workspace still uses default settings. With dry_run=true nothing is saved and only the report is returned. Requires owner or manager role.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param dry_run query bool false "Validate and report without saving"
+// @Param bundle body models.WorkspaceBundle true "Workspace bundle"
+// @Success 200 {object} models.WorkspaceImportReport
+// @Failure 400 {object} map[string]string "Invalid or unsupported bundle"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/import [post]
+func ImportWorkspace(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	var bundle models.WorkspaceBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := validateBundleRecipeLines(bundle)
+	var report models.WorkspaceImportReport
+	if err == nil {
+		report, err = database.ImportWorkspaceBundle(database.DB, workspaceID, userID, bundle, dryRun)
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrUnsupportedWorkspaceBundleVersion) || errors.Is(err, database.ErrInvalidWorkspaceBundle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to import bundle into workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// validateBundleRecipeLines checks the quantities and units of recipe lines the way the recipe
+// endpoints do, so an import cannot create lines that cannot be costed. Bundles of other format
+// versions and references to recipes outside the bundle are left to the import, which rejects them.
+func validateBundleRecipeLines(bundle models.WorkspaceBundle) error {
+	if bundle.FormatVersion != models.WorkspaceBundleFormatVersion {
+		return nil
+	}
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", database.ErrInvalidWorkspaceBundle, fmt.Sprintf(format, args...))
+	}
+
+	recipes := make(map[uint]models.WorkspaceBundleRecipe, len(bundle.Recipes))
+	for _, recipe := range bundle.Recipes {
+		recipes[recipe.ID] = recipe
+	}
+	for i, recipe := range bundle.Recipes {
+		for j, line := range recipe.Ingredients {
+			if _, err := utils.ConvertQuantity(line.Quantity, line.Unit, line.Unit); err != nil {
+				return invalid("recipes[%d].ingredients[%d] has invalid quantity %q", i, j, line.Quantity)
+			}
+		}
+		for j, line := range recipe.SubRecipes {
+			subRecipe, ok := recipes[line.RecipeID]
+			if !ok {
+				continue
+			}
+			if strings.TrimSpace(subRecipe.YieldQuantity) == "" {
+				return invalid("recipes[%d].sub_recipes[%d] references recipe %d, which has no yield", i, j, line.RecipeID)
+			}
+			if _, err := utils.ConvertQuantity(line.Quantity, line.Unit, subRecipe.YieldUnit); err != nil {
+				return invalid("recipes[%d].sub_recipes[%d] requires a quantity in the dimension of yield unit %q", i, j, subRecipe.YieldUnit)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+func TestWorkspaceExportImportRoundTrip(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+	db := database.DB
+
+	paprika := models.Ingredient{Name: "Smoked paprika", Type: "spice"}
+	if err := db.Create(&paprika).Error; err != nil {
+		t.Fatalf("create ingredient: %v", err)
+	}
+	lines := []models.RecipeIngredient{
+		{RecipeID: fixture.PersonalRecipe.ID, IngredientID: fixture.Ingredient.ID, Quantity: "10", Unit: "g"},
+		{RecipeID: fixture.PersonalRecipe.ID, IngredientID: paprika.ID, Quantity: "5", Unit: "g"},
+	}
+	if err := db.Create(&lines).Error; err != nil {
+		t.Fatalf("create recipe ingredients: %v", err)
+	}
+	price := models.Price{IngredientID: fixture.Ingredient.ID, Price: 300, Quantity: 1, Unit: "kg", Date: time.Now(), UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID}
+	if err := db.Create(&price).Error; err != nil {
+		t.Fatalf("create price: %v", err)
+	}
+	option := models.ProductOption{ProductID: fixture.PersonalProduct.ID, RecipeID: fixture.PersonalRecipe.ID, UserID: fixture.User.ID}
+	if err := db.Create(&option).Error; err != nil {
+		t.Fatalf("create product option: %v", err)
+	}
+	workspaceIngredient, err := database.EnsureWorkspaceIngredient(db, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID)
+	if err != nil {
+		t.Fatalf("ensure workspace ingredient: %v", err)
+	}
+	if err := db.Model(&workspaceIngredient).Update("alias", "Black pepper").Error; err != nil {
+		t.Fatalf("set alias: %v", err)
+	}
+	// Rows referencing the second workspace cannot be exported and are reported instead
+	strayProduct := models.Product{Name: "Stray product", UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID, PackageID: fixture.SecondPackage.ID}
+	if err := db.Create(&strayProduct).Error; err != nil {
+		t.Fatalf("create stray product: %v", err)
+	}
+	strayItem := models.OrderItem{OrderID: fixture.PersonalOrder.ID, ProductID: strayProduct.ID, Quantity: 1, Price: 5}
+	if err := db.Create(&strayItem).Error; err != nil {
+		t.Fatalf("create stray order item: %v", err)
+	}
+	strayOrder := models.Order{ClientID: fixture.SecondClient.ID, Date: time.Now(), Status: constants.OrderStatusNew, UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID}
+	if err := db.Create(&strayOrder).Error; err != nil {
+		t.Fatalf("create stray order: %v", err)
+	}
+	settings := database.DefaultWorkspaceSettings(fixture.PersonalWorkspace.ID)
+	settings.DefaultCurrency = "RSD"
+	if err := database.SaveWorkspaceSettings(db, &settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, ExportWorkspace, http.MethodGet, "/workspaces/current/export", "/workspaces/current/export")
+	if response.Code != http.StatusOK {
+		t.Fatalf("export status = %d body = %s", response.Code, response.Body.String())
+	}
+	if got := response.Header().Get("Content-Disposition"); got != `attachment; filename="personal-business-export.json"` {
+		t.Fatalf("Content-Disposition = %q", got)
+	}
+	var bundle models.WorkspaceBundle
+	if err := json.Unmarshal(response.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if bundle.FormatVersion != models.WorkspaceBundleFormatVersion || len(bundle.Recipes) != 1 || len(bundle.Ingredients) != 2 || len(bundle.Orders) != 1 {
+		t.Fatalf("bundle = %+v, want one recipe, two ingredients and one order", bundle)
+	}
+	skipped := make(map[string]uint)
+	for _, row := range bundle.Skipped {
+		skipped[row.Table] = row.ID
+	}
+	if len(bundle.Skipped) != 3 || skipped["products"] != strayProduct.ID || skipped["orders"] != strayOrder.ID || skipped["order_items"] != strayItem.ID {
+		t.Fatalf("skipped = %+v, want the stray product, order and order item", bundle.Skipped)
+	}
+
+	// Rename the paprika in the bundle so the import has to create a new global ingredient.
+	for i := range bundle.Ingredients {
+		if bundle.Ingredients[i].ID == paprika.ID {
+			bundle.Ingredients[i].Name = "Sweet paprika"
+		}
+	}
+
+	target := models.Workspace{Name: "Business", Slug: "business-import"}
+	if err := db.Create(&target).Error; err != nil {
+		t.Fatalf("create target workspace: %v", err)
+	}
+
+	response = runWorkspaceJSONRequest(fixture.User.ID, target.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import?dry_run=true", bundle)
+	if response.Code != http.StatusOK {
+		t.Fatalf("dry run status = %d body = %s", response.Code, response.Body.String())
+	}
+	var report models.WorkspaceImportReport
+	if err := json.Unmarshal(response.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if !report.DryRun || report.Created["recipes"] != 1 || report.Created["order_items"] != 1 || report.Created["workspace_ingredients"] != 1 || report.IngredientsMatched != 1 || report.IngredientsCreated != 1 || !report.SettingsApplied {
+		t.Fatalf("dry run report = %+v", report)
+	}
+	var count int64
+	db.Model(&models.Recipe{}).Where("workspace_id = ?", target.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("dry run created %d recipes", count)
+	}
+	db.Model(&models.Ingredient{}).Where("name = ?", "Sweet paprika").Count(&count)
+	if count != 0 {
+		t.Fatalf("dry run created the ingredient")
+	}
+
+	response = runWorkspaceJSONRequest(fixture.User.ID, target.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import", bundle)
+	if response.Code != http.StatusOK {
+		t.Fatalf("import status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	var recipe models.Recipe
+	if err := db.Preload("RecipeIngredients").Where("workspace_id = ?", target.ID).First(&recipe).Error; err != nil {
+		t.Fatalf("load imported recipe: %v", err)
+	}
+	if recipe.ID == fixture.PersonalRecipe.ID || len(recipe.RecipeIngredients) != 2 {
+		t.Fatalf("imported recipe = %+v", recipe)
+	}
+	var linked models.WorkspaceIngredient
+	if err := db.Where("workspace_id = ? AND ingredient_id = ?", target.ID, fixture.Ingredient.ID).First(&linked).Error; err != nil {
+		t.Fatalf("load linked ingredient: %v", err)
+	}
+	if linked.Alias != "Black pepper" {
+		t.Fatalf("linked alias = %q, want Black pepper", linked.Alias)
+	}
+	db.Model(&models.WorkspaceIngredient{}).Where("workspace_id = ?", target.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("target workspace ingredients = %d, want only the ingredient exported in the workspace", count)
+	}
+
+	response = runWorkspaceJSONRequest(fixture.User.ID, target.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import?dry_run=true", bundle)
+	var repeated models.WorkspaceImportReport
+	if err := json.Unmarshal(response.Body.Bytes(), &repeated); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if response.Code != http.StatusOK || repeated.Created["workspace_ingredients"] != 0 {
+		t.Fatalf("repeated import status = %d report = %+v, want no new workspace ingredients", response.Code, repeated)
+	}
+
+	var order models.Order
+	if err := db.Preload("Items").Preload("Items.Product").Where("workspace_id = ?", target.ID).First(&order).Error; err != nil {
+		t.Fatalf("load imported order: %v", err)
+	}
+	if len(order.Items) != 1 || order.Items[0].Product.WorkspaceID == nil || *order.Items[0].Product.WorkspaceID != target.ID {
+		t.Fatalf("imported order items = %+v, want a product in the target workspace", order.Items)
+	}
+	var client models.Client
+	if err := db.First(&client, order.ClientID).Error; err != nil || client.WorkspaceID == nil || *client.WorkspaceID != target.ID {
+		t.Fatalf("imported order client = %+v err = %v", client, err)
+	}
+
+	targetSettings, err := database.GetWorkspaceSettings(db, target.ID)
+	if err != nil {
+		t.Fatalf("load target settings: %v", err)
+	}
+	if targetSettings.DefaultCurrency != "RSD" {
+		t.Fatalf("target currency = %q, want RSD", targetSettings.DefaultCurrency)
+	}
+}
+
+func TestImportWorkspaceRejectsInvalidBundles(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import", models.WorkspaceBundle{FormatVersion: 99})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("unsupported version status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	bundle := models.WorkspaceBundle{
+		FormatVersion: models.WorkspaceBundleFormatVersion,
+		Clients:       []models.WorkspaceBundleClient{{ID: 1, Name: "Ana"}},
+		Orders: []models.WorkspaceBundleOrder{{
+			ClientID: 1,
+			Date:     time.Now(),
+			Status:   constants.OrderStatusNew,
+			Items:    []models.WorkspaceBundleOrderItem{{ProductID: 7, Quantity: 1}},
+		}},
+	}
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import", bundle)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("dangling reference status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	bundle = models.WorkspaceBundle{
+		FormatVersion: models.WorkspaceBundleFormatVersion,
+		Ingredients:   []models.WorkspaceBundleIngredient{{ID: 1, Name: "Pepper", Type: "spice"}},
+		Prices:        []models.WorkspaceBundlePrice{{IngredientID: 1, Price: 3, Quantity: 0, Unit: "kg", Date: time.Now()}},
+	}
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import", bundle)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("zero price quantity status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	for name, nutrition := range map[string]models.Nutrition{
+		"negative energy":       {EnergyKcal: float64Ptr(-1)},
+		"over 100 g of protein": {Protein: float64Ptr(150)},
+	} {
+		bundle = models.WorkspaceBundle{
+			FormatVersion: models.WorkspaceBundleFormatVersion,
+			Ingredients:   []models.WorkspaceBundleIngredient{{ID: 1, Name: "Pepper", Type: "spice", InWorkspace: true, NutritionOverride: &nutrition}},
+		}
+		response = runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import", bundle)
+		if response.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d body = %s", name, response.Code, response.Body.String())
+		}
+	}
+
+	marinade := models.WorkspaceBundleRecipe{ID: 2, Name: "Marinade", RecipeYield: models.RecipeYield{YieldQuantity: "500", YieldUnit: "g"}}
+	for name, recipes := range map[string][]models.WorkspaceBundleRecipe{
+		"recipes[0].ingredients[0]": {{ID: 1, Name: "Jerky", Ingredients: []models.WorkspaceBundleRecipeIngredient{{IngredientID: 1, Quantity: "a pinch", Unit: "g"}}}},
+		"recipes[0].sub_recipes[0]": {{ID: 1, Name: "Jerky", SubRecipes: []models.WorkspaceBundleRecipeSubRecipe{{RecipeID: 2, Quantity: "100", Unit: "ml"}}}, marinade},
+		"recipes[1].sub_recipes[0]": {marinade, {ID: 1, Name: "Jerky", SubRecipes: []models.WorkspaceBundleRecipeSubRecipe{{RecipeID: 3, Quantity: "1", Unit: "pcs"}}}, {ID: 3, Name: "Rub"}},
+	} {
+		bundle = models.WorkspaceBundle{
+			FormatVersion: models.WorkspaceBundleFormatVersion,
+			Ingredients:   []models.WorkspaceBundleIngredient{{ID: 1, Name: "Pepper", Type: "spice"}},
+			Recipes:       recipes,
+		}
+		response = runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import?dry_run=true", bundle)
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), name) {
+			t.Fatalf("invalid %s dry run status = %d body = %s", name, response.Code, response.Body.String())
+		}
+	}
+
+	var count int64
+	database.DB.Model(&models.Client{}).Where("workspace_id = ?", fixture.SecondWorkspace.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("second workspace clients = %d, want only the fixture client", count)
+	}
+}
+
+func TestImportWorkspaceKeepsNutritionInTheWorkspace(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+
+	bundle := models.WorkspaceBundle{
+		FormatVersion: models.WorkspaceBundleFormatVersion,
+		Ingredients: []models.WorkspaceBundleIngredient{{
+			ID: 1, Name: "Smoked salt", Type: "spice", InWorkspace: true, Active: true,
+			Nutrition:         &models.Nutrition{EnergyKcal: float64Ptr(0), Salt: float64Ptr(95)},
+			NutritionOverride: &models.Nutrition{Salt: float64Ptr(97)},
+		}},
+	}
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, ImportWorkspace, http.MethodPost, "/workspaces/current/import", "/workspaces/current/import", bundle)
+	if response.Code != http.StatusOK {
+		t.Fatalf("import status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	var ingredient models.Ingredient
+	if err := database.DB.Where("name = ?", "Smoked salt").First(&ingredient).Error; err != nil {
+		t.Fatalf("load ingredient: %v", err)
+	}
+	if !ingredient.Nutrition.IsEmpty() {
+		t.Fatalf("global nutrition = %+v, want none", ingredient.Nutrition)
+	}
+	var workspaceIngredient models.WorkspaceIngredient
+	if err := database.DB.Where("workspace_id = ? AND ingredient_id = ?", fixture.SecondWorkspace.ID, ingredient.ID).First(&workspaceIngredient).Error; err != nil {
+		t.Fatalf("load workspace ingredient: %v", err)
+	}
+	override := workspaceIngredient.NutritionOverride
+	if override.EnergyKcal == nil || *override.EnergyKcal != 0 || override.Salt == nil || *override.Salt != 97 {
+		t.Fatalf("nutrition override = %+v, want the bundle nutrition with its override applied", override)
+	}
+}
@@ -54,12 +54,20 @@ func setupWorkspaceBusinessTest(t *testing.T) workspaceBusinessFixture {
 
 	if err := db.AutoMigrate(
 		&models.User{},
+		&models.Account{},
+		&models.AccountMember{},
 		&models.Workspace{},
 		&models.WorkspaceMember{},
+		&models.WorkspaceSettings{},
+		&models.TwoFactor{},
 		&models.Ingredient{},
+		&models.WorkspaceIngredient{},
 		&models.Price{},
 		&models.Recipe{},
 		&models.RecipeIngredient{},
+		&models.RecipeSubRecipe{},
+		&models.RecipeStep{},
+		&models.RecipeRevision{},
 		&models.Package{},
 		&models.Product{},
 		&models.ProductOption{},
@@ -68,6 +76,7 @@ func setupWorkspaceBusinessTest(t *testing.T) workspaceBusinessFixture {
 		&models.OrderItem{},
 		&models.CookingSession{},
 		&models.CookingSessionIngredient{},
+		&models.AuditLog{},
 	); err != nil {
 		t.Fatalf("migrate test database: %v", err)
 	}
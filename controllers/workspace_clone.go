@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CloneRecipe copies a recipe from the current workspace into another workspace.
+// @Summary Clone recipe into another workspace
+// @Description Copy a recipe with its ingredient lines into another workspace the caller belongs to. Ingredients are added to the target workspace working set; with include_prices the latest price of every ingredient is copied too. Requires recipe write access in both workspaces; API keys cannot clone.
+// @Tags Recipes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-Workspace-ID header int false "Source workspace ID"
+// @Param id path int true "Recipe ID"
+// @Param request body models.WorkspaceCloneDTO true "Target workspace"
+// @Success 201 {object} models.Recipe
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "Recipe not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/recipes/{id}/clone [post]
+func CloneRecipe(c *gin.Context) {
+	recipeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	requestData, ok := bindCloneTarget(c, constants.WorkspaceResourceRecipes)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+	recipe, err := database.CloneRecipeToWorkspace(database.DB, uint(recipeID), workspaceID, requestData.TargetWorkspaceID, userID, requestData.IncludePrices)
+	if err != nil {
+		if errors.Is(err, database.ErrCloneSourceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+			return
+		}
+		log.Printf("Failed to clone recipe %d into workspace %d: %v", recipeID, requestData.TargetWorkspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone recipe"})
+		return
+	}
+
+	recordWorkspaceActivity(c, requestData.TargetWorkspaceID, constants.AuditEntityRecipe, recipe.ID, constants.AuditActionCreate, nil, activitySnapshot(recipe))
+	c.JSON(http.StatusCreated, recipe)
+}
+
+// CloneProduct copies a product from the current workspace into another workspace.
+// @Summary Clone product into another workspace
+// @Description Copy a product with its package and option recipes into another workspace the caller belongs to. A package with the same name in the target workspace is reused. Ingredients are added to the target workspace working set; with include_prices the latest price of every ingredient is copied too. Requires product write access in both workspaces; API keys cannot clone.
+// @Tags Products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-Workspace-ID header int false "Source workspace ID"
+// @Param id path int true "Product ID"
+// @Param request body models.WorkspaceCloneDTO true "Target workspace"
+// @Success 201 {object} models.Product
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/products/{id}/clone [post]
+func CloneProduct(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	requestData, ok := bindCloneTarget(c, constants.WorkspaceResourceProducts, constants.WorkspaceResourcePackages, constants.WorkspaceResourceRecipes)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+	product, err := database.CloneProductToWorkspace(database.DB, uint(productID), workspaceID, requestData.TargetWorkspaceID, userID, requestData.IncludePrices)
+	if err != nil {
+		if errors.Is(err, database.ErrCloneSourceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		log.Printf("Failed to clone product %d into workspace %d: %v", productID, requestData.TargetWorkspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone product"})
+		return
+	}
+
+	recordWorkspaceActivity(c, requestData.TargetWorkspaceID, constants.AuditEntityProduct, product.ID, constants.AuditActionCreate, nil, productActivitySnapshot(product))
+	c.JSON(http.StatusCreated, product)
+}
+
+// bindCloneTarget reads the clone request and checks that the caller may create the given
+// resources (and prices, when requested) in the target workspace. Write access in the source
+// workspace is enforced by the route. API keys only grant access to their own workspace, so they
+// cannot clone.
+func bindCloneTarget(c *gin.Context, resources ...string) (models.WorkspaceCloneDTO, bool) {
+	if _, isAPIKey := c.Get("apiKeyID"); isAPIKey {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot access this endpoint"})
+		return models.WorkspaceCloneDTO{}, false
+	}
+
+	var requestData models.WorkspaceCloneDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return requestData, false
+	}
+
+	userID := c.MustGet("userID").(uint)
+	workspaceID := c.MustGet("workspaceID").(uint)
+	if requestData.TargetWorkspaceID == workspaceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target workspace must differ from the current workspace"})
+		return requestData, false
+	}
+
+	member, found, err := database.FindWorkspaceMember(database.DB, userID, requestData.TargetWorkspaceID)
+	if err != nil {
+		log.Printf("Failed to resolve workspace %d for user %d: %v", requestData.TargetWorkspaceID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve workspace"})
+		return requestData, false
+	}
+	if !found {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Workspace access denied"})
+		return requestData, false
+	}
+	if !middleware.TwoFactorRequirementMet(c, userID, member.WorkspaceID) {
+		return requestData, false
+	}
+
+	if requestData.IncludePrices {
+		resources = append(resources, constants.WorkspaceResourcePrices)
+	}
+	for _, resource := range resources {
+		if !requireWorkspacePermission(c, member.Role, resource, constants.WorkspaceActionCreate) {
+			return requestData, false
+		}
+	}
+
+	return requestData, true
+}
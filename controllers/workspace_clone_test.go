@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+func TestCloneProductCopiesPackageRecipesAndPrices(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+	db := database.DB
+
+	line := models.RecipeIngredient{RecipeID: fixture.PersonalRecipe.ID, IngredientID: fixture.Ingredient.ID, Quantity: "10", Unit: "g"}
+	if err := db.Create(&line).Error; err != nil {
+		t.Fatalf("create recipe ingredient: %v", err)
+	}
+	prices := []models.Price{
+		{IngredientID: fixture.Ingredient.ID, Price: 250, Quantity: 1, Unit: "kg", Date: time.Now().Add(-48 * time.Hour), UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID},
+		{IngredientID: fixture.Ingredient.ID, Price: 300, Quantity: 1, Unit: "kg", Date: time.Now(), UserID: fixture.User.ID, WorkspaceID: &fixture.PersonalWorkspace.ID},
+	}
+	if err := db.Create(&prices).Error; err != nil {
+		t.Fatalf("create prices: %v", err)
+	}
+	option := models.ProductOption{ProductID: fixture.PersonalProduct.ID, RecipeID: fixture.PersonalRecipe.ID, UserID: fixture.User.ID}
+	if err := db.Create(&option).Error; err != nil {
+		t.Fatalf("create product option: %v", err)
+	}
+
+	body := map[string]any{"target_workspace_id": fixture.SecondWorkspace.ID, "include_prices": true}
+	route := "/products/:id/clone"
+	target := "/products/" + uintToString(fixture.PersonalProduct.ID) + "/clone"
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CloneProduct, http.MethodPost, route, target, body)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("clone status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	var product models.Product
+	if err := json.Unmarshal(response.Body.Bytes(), &product); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	if product.ID == fixture.PersonalProduct.ID || product.WorkspaceID == nil || *product.WorkspaceID != fixture.SecondWorkspace.ID {
+		t.Fatalf("cloned product = %+v, want a new product in the second workspace", product)
+	}
+	if product.Package.Name != fixture.PersonalPackage.Name || product.PackageID == fixture.PersonalPackage.ID {
+		t.Fatalf("cloned package = %+v, want a copy of %q", product.Package, fixture.PersonalPackage.Name)
+	}
+	if len(product.Options) != 1 {
+		t.Fatalf("cloned options = %+v, want one", product.Options)
+	}
+
+	var recipe models.Recipe
+	if err := db.Preload("RecipeIngredients").First(&recipe, product.Options[0].RecipeID).Error; err != nil {
+		t.Fatalf("load cloned recipe: %v", err)
+	}
+	if recipe.WorkspaceID == nil || *recipe.WorkspaceID != fixture.SecondWorkspace.ID || len(recipe.RecipeIngredients) != 1 {
+		t.Fatalf("cloned recipe = %+v", recipe)
+	}
+
+	inWorkspace, err := database.IngredientInWorkspace(db, fixture.SecondWorkspace.ID, fixture.Ingredient.ID)
+	if err != nil || !inWorkspace {
+		t.Fatalf("ingredient in second workspace = %t err = %v", inWorkspace, err)
+	}
+
+	var clonedPrices []models.Price
+	if err := db.Where("workspace_id = ?", fixture.SecondWorkspace.ID).Find(&clonedPrices).Error; err != nil {
+		t.Fatalf("load cloned prices: %v", err)
+	}
+	if len(clonedPrices) != 1 || clonedPrices[0].Price != 300 {
+		t.Fatalf("cloned prices = %+v, want only the latest price", clonedPrices)
+	}
+
+	// A second clone reuses the package with the same name and does not duplicate the price.
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CloneProduct, http.MethodPost, route, target, body)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("second clone status = %d body = %s", response.Code, response.Body.String())
+	}
+	var count int64
+	db.Model(&models.Package{}).Where("workspace_id = ? AND name = ?", fixture.SecondWorkspace.ID, fixture.PersonalPackage.Name).Count(&count)
+	if count != 1 {
+		t.Fatalf("target packages named %q = %d, want 1", fixture.PersonalPackage.Name, count)
+	}
+	db.Model(&models.Price{}).Where("workspace_id = ?", fixture.SecondWorkspace.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("target prices = %d, want 1", count)
+	}
+}
+
+func TestCloneRecipeRequiresWriteAccessInTargetWorkspace(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+	db := database.DB
+
+	viewerWorkspace := models.Workspace{Name: "Partner", Slug: "partner-business"}
+	strangerWorkspace := models.Workspace{Name: "Stranger", Slug: "stranger-business"}
+	if err := db.Create(&viewerWorkspace).Error; err != nil {
+		t.Fatalf("create viewer workspace: %v", err)
+	}
+	if err := db.Create(&strangerWorkspace).Error; err != nil {
+		t.Fatalf("create stranger workspace: %v", err)
+	}
+	member := models.WorkspaceMember{WorkspaceID: viewerWorkspace.ID, UserID: fixture.User.ID, Role: constants.WorkspaceRoleViewer}
+	if err := db.Create(&member).Error; err != nil {
+		t.Fatalf("create viewer membership: %v", err)
+	}
+
+	route := "/recipes/:id/clone"
+	target := "/recipes/" + uintToString(fixture.PersonalRecipe.ID) + "/clone"
+	for name, tt := range map[string]struct {
+		workspaceID uint
+		status      int
+	}{
+		"viewer":     {viewerWorkspace.ID, http.StatusForbidden},
+		"non-member": {strangerWorkspace.ID, http.StatusForbidden},
+		"same":       {fixture.PersonalWorkspace.ID, http.StatusBadRequest},
+	} {
+		response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CloneRecipe, http.MethodPost, route, target, map[string]any{"target_workspace_id": tt.workspaceID})
+		if response.Code != tt.status {
+			t.Fatalf("%s clone status = %d body = %s, want %d", name, response.Code, response.Body.String(), tt.status)
+		}
+	}
+
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CloneRecipe, http.MethodPost, route, "/recipes/"+uintToString(fixture.SecondRecipe.ID)+"/clone", map[string]any{"target_workspace_id": fixture.SecondWorkspace.ID})
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("foreign recipe clone status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	settings := database.DefaultWorkspaceSettings(fixture.SecondWorkspace.ID)
+	settings.RequireTwoFactor = true
+	if err := database.SaveWorkspaceSettings(db, &settings); err != nil {
+		t.Fatalf("require two-factor: %v", err)
+	}
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CloneRecipe, http.MethodPost, route, target, map[string]any{"target_workspace_id": fixture.SecondWorkspace.ID})
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("clone into two-factor workspace status = %d body = %s", response.Code, response.Body.String())
+	}
+	settings.RequireTwoFactor = false
+	if err := database.SaveWorkspaceSettings(db, &settings); err != nil {
+		t.Fatalf("drop two-factor requirement: %v", err)
+	}
+
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CloneRecipe, http.MethodPost, route, target, map[string]any{"target_workspace_id": fixture.SecondWorkspace.ID})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("clone status = %d body = %s", response.Code, response.Body.String())
+	}
+	var recipe models.Recipe
+	if err := json.Unmarshal(response.Body.Bytes(), &recipe); err != nil {
+		t.Fatalf("decode recipe: %v", err)
+	}
+	if recipe.Name != fixture.PersonalRecipe.Name || recipe.WorkspaceID == nil || *recipe.WorkspaceID != fixture.SecondWorkspace.ID {
+		t.Fatalf("cloned recipe = %+v", recipe)
+	}
+}
@@ -1,21 +1,18 @@
 package controllers
 
 import (
-	"os"
-	"strings"
-
 	"mobile-backend-go/database"
 )
 
-func strictWorkspaceIngredientsEnabled() bool {
-	return strings.EqualFold(os.Getenv("STRICT_WORKSPACE_INGREDIENTS"), "true")
-}
-
 func prepareWorkspaceIngredientForWrite(workspaceID uint, ingredientID uint) error {
-	if strictWorkspaceIngredientsEnabled() {
+	settings, err := database.GetWorkspaceSettings(database.DB, workspaceID)
+	if err != nil {
+		return err
+	}
+	if settings.StrictIngredients {
 		return database.RequireWorkspaceIngredient(database.DB, workspaceID, ingredientID)
 	}
 
-	_, err := database.EnsureWorkspaceIngredient(database.DB, workspaceID, ingredientID)
+	_, err = database.EnsureWorkspaceIngredient(database.DB, workspaceID, ingredientID)
 	return err
 }
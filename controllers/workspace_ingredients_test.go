@@ -45,11 +45,15 @@ func setupWorkspaceIngredientTest(t *testing.T) workspaceIngredientFixture {
 		&models.User{},
 		&models.Workspace{},
 		&models.WorkspaceMember{},
+		&models.WorkspaceSettings{},
 		&models.Ingredient{},
 		&models.WorkspaceIngredient{},
 		&models.Price{},
 		&models.Recipe{},
 		&models.RecipeIngredient{},
+		&models.RecipeSubRecipe{},
+		&models.RecipeStep{},
+		&models.RecipeRevision{},
 	); err != nil {
 		t.Fatalf("migrate test database: %v", err)
 	}
@@ -219,9 +223,44 @@ func TestCreateIngredientExistingNameEnsuresWorkspaceMembership(t *testing.T) {
 	assertWorkspaceIngredientExists(t, fixture.PersonalWorkspace.ID, fixture.GlobalIngredient.ID)
 }
 
+func TestCreateIngredientStoresNutritionAsWorkspaceOverride(t *testing.T) {
+	fixture := setupWorkspaceIngredientTest(t)
+	nutrition := map[string]any{"energy_kcal": 120, "protein": 20}
+
+	for _, tc := range []struct {
+		name   string
+		status int
+	}{
+		{"Fresh beef", http.StatusCreated},
+		{fixture.GlobalIngredient.Name, http.StatusConflict},
+	} {
+		response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, CreateIngredient, http.MethodPost,
+			"/ingredients", "/ingredients", map[string]any{"name": tc.name, "type": "meat", "nutrition": nutrition})
+		if response.Code != tc.status {
+			t.Fatalf("create %q status = %d body = %s", tc.name, response.Code, response.Body.String())
+		}
+
+		var ingredient models.Ingredient
+		if err := database.DB.Where("name = ?", tc.name).First(&ingredient).Error; err != nil {
+			t.Fatalf("load ingredient %q: %v", tc.name, err)
+		}
+		if !ingredient.Nutrition.IsEmpty() {
+			t.Fatalf("global nutrition of %q = %+v, want none", tc.name, ingredient.Nutrition)
+		}
+		var workspaceIngredient models.WorkspaceIngredient
+		if err := database.DB.Where("workspace_id = ? AND ingredient_id = ?", fixture.PersonalWorkspace.ID, ingredient.ID).First(&workspaceIngredient).Error; err != nil {
+			t.Fatalf("load workspace ingredient %q: %v", tc.name, err)
+		}
+		override := workspaceIngredient.NutritionOverride
+		if override.EnergyKcal == nil || *override.EnergyKcal != 120 || override.Protein == nil || *override.Protein != 20 {
+			t.Fatalf("nutrition override of %q = %+v, want the request nutrition", tc.name, override)
+		}
+	}
+}
+
 func TestPriceAndRecipeWritesAutoLinkGlobalIngredientsWhenStrictModeDisabled(t *testing.T) {
-	t.Setenv("STRICT_WORKSPACE_INGREDIENTS", "false")
 	fixture := setupWorkspaceIngredientTest(t)
+	setStrictWorkspaceIngredients(t, fixture.PersonalWorkspace.ID, false)
 
 	priceResponse := runWorkspaceJSONRequest(
 		fixture.User.ID,
@@ -267,8 +306,8 @@ func TestPriceAndRecipeWritesAutoLinkGlobalIngredientsWhenStrictModeDisabled(t *
 }
 
 func TestPriceAndRecipeWritesRejectUnlinkedIngredientsWhenStrictModeEnabled(t *testing.T) {
-	t.Setenv("STRICT_WORKSPACE_INGREDIENTS", "true")
 	fixture := setupWorkspaceIngredientTest(t)
+	setStrictWorkspaceIngredients(t, fixture.PersonalWorkspace.ID, true)
 
 	priceResponse := runWorkspaceJSONRequest(
 		fixture.User.ID,
@@ -313,8 +352,8 @@ func TestPriceAndRecipeWritesRejectUnlinkedIngredientsWhenStrictModeEnabled(t *t
 }
 
 func TestPriceAndRecipeWritesAcceptExplicitlyLinkedIngredientsWhenStrictModeEnabled(t *testing.T) {
-	t.Setenv("STRICT_WORKSPACE_INGREDIENTS", "true")
 	fixture := setupWorkspaceIngredientTest(t)
+	setStrictWorkspaceIngredients(t, fixture.PersonalWorkspace.ID, true)
 
 	linkResponse := runWorkspaceJSONRequest(
 		fixture.User.ID,
@@ -371,8 +410,8 @@ func TestPriceAndRecipeWritesAcceptExplicitlyLinkedIngredientsWhenStrictModeEnab
 }
 
 func TestPriceAndRecipeWritesRejectInactiveIngredientsWhenStrictModeEnabled(t *testing.T) {
-	t.Setenv("STRICT_WORKSPACE_INGREDIENTS", "true")
 	fixture := setupWorkspaceIngredientTest(t)
+	setStrictWorkspaceIngredients(t, fixture.PersonalWorkspace.ID, true)
 
 	if err := database.DB.Model(&models.WorkspaceIngredient{}).
 		Where("workspace_id = ? AND ingredient_id = ?", fixture.PersonalWorkspace.ID, fixture.LinkedIngredient.ID).
@@ -603,6 +642,16 @@ func TestPriceAndRecipeWritesRejectInvalidIngredientIDs(t *testing.T) {
 	}
 }
 
+func setStrictWorkspaceIngredients(t *testing.T, workspaceID uint, strict bool) {
+	t.Helper()
+
+	settings := database.DefaultWorkspaceSettings(workspaceID)
+	settings.StrictIngredients = strict
+	if err := database.SaveWorkspaceSettings(database.DB, &settings); err != nil {
+		t.Fatalf("save workspace settings: %v", err)
+	}
+}
+
 func assertWorkspaceIngredientExists(t *testing.T, workspaceID uint, ingredientID uint) {
 	t.Helper()
 
@@ -0,0 +1,502 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+	"mobile-backend-go/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWorkspaceInvitationTTL    = 72 * time.Hour
+	workspaceInvitationTokenByteSize = 32
+)
+
+// WorkspaceInvitationResponse represents a workspace invitation.
+// Token is only returned once, when a link invitation is created.
+type WorkspaceInvitationResponse struct {
+	ID                uint       `json:"id"`
+	WorkspaceID       uint       `json:"workspace_id"`
+	WorkspaceName     string     `json:"workspace_name"`
+	Role              string     `json:"role"`
+	Status            string     `json:"status"`
+	InviteeUserID     *uint      `json:"invitee_user_id,omitempty"`
+	InviteeUsername   string     `json:"invitee_username,omitempty"`
+	InvitedByUserID   uint       `json:"invited_by_user_id"`
+	InvitedByUsername string     `json:"invited_by_username"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	RespondedAt       *time.Time `json:"responded_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	Token             string     `json:"token,omitempty"`
+}
+
+// CreateWorkspaceInvitation invites a user to a workspace.
+// @Summary Create workspace invitation
+// @Description Invite an existing user by username, or create a one-time link invitation when username is omitted. Requires owner or manager role; only owners can invite owners. The link token is returned only in this response.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param invitation body models.WorkspaceInvitationCreateDTO true "Invitation data"
+// @Success 201 {object} WorkspaceInvitationResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 409 {object} map[string]string "User is already a member or has a pending invitation"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id}/invitations [post]
+func CreateWorkspaceInvitation(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceMembers, constants.WorkspaceActionCreate) {
+		return
+	}
+
+	var requestData models.WorkspaceInvitationCreateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !constants.IsValidWorkspaceRole(requestData.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace role"})
+		return
+	}
+	if !ownerRoleChangeAllowed(member.Role, "", requestData.Role) {
+		respondOwnerRoleRequired(c, member.Role)
+		return
+	}
+	if member.Workspace.PersonalUserID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Personal workspaces cannot be shared"})
+		return
+	}
+
+	ttl := defaultWorkspaceInvitationTTL
+	if requestData.ExpiresInHours > 0 {
+		ttl = time.Duration(requestData.ExpiresInHours) * time.Hour
+	}
+	invitation := models.WorkspaceInvitation{
+		WorkspaceID:     member.WorkspaceID,
+		InvitedByUserID: member.UserID,
+		Role:            requestData.Role,
+		Status:          constants.WorkspaceInvitationPending,
+		ExpiresAt:       time.Now().Add(ttl),
+	}
+
+	var token string
+	username := strings.TrimSpace(requestData.Username)
+	if username != "" {
+		var invitee models.User
+		if err := database.DB.Where("username = ?", username).First(&invitee).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		_, found, err := database.FindWorkspaceMember(database.DB, invitee.ID, member.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace member"})
+			return
+		}
+		if found {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already a workspace member"})
+			return
+		}
+
+		var pendingCount int64
+		if err := database.DB.Model(&models.WorkspaceInvitation{}).
+			Where("workspace_id = ? AND invitee_user_id = ? AND status = ? AND expires_at > ?",
+				member.WorkspaceID, invitee.ID, constants.WorkspaceInvitationPending, time.Now()).
+			Count(&pendingCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check pending invitations"})
+			return
+		}
+		if pendingCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "User already has a pending invitation"})
+			return
+		}
+
+		invitation.InviteeUserID = &invitee.ID
+	} else {
+		var err error
+		token, err = utils.GenerateSecureToken(workspaceInvitationTokenByteSize)
+		if err != nil {
+			log.Printf("Failed to generate invitation token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+			return
+		}
+		tokenHash := utils.HashToken(token)
+		invitation.TokenHash = &tokenHash
+	}
+
+	if err := database.DB.Create(&invitation).Error; err != nil {
+		log.Printf("Failed to create invitation for workspace %d: %v", member.WorkspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	response, err := loadWorkspaceInvitationResponse(invitation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitation"})
+		return
+	}
+	response.Token = token
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetWorkspaceInvitations lists invitations of a workspace.
+// @Summary Get workspace invitations
+// @Description List invitations of a workspace, newest first. Requires owner or manager role.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param status query string false "Filter by status (pending, accepted, declined, revoked, expired)"
+// @Success 200 {array} WorkspaceInvitationResponse
+// @Failure 400 {object} map[string]string "Invalid workspace ID"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id}/invitations [get]
+func GetWorkspaceInvitations(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceMembers, constants.WorkspaceActionCreate) {
+		return
+	}
+
+	query := workspaceInvitationQuery().Where("workspace_invitations.workspace_id = ?", member.WorkspaceID)
+	query = filterWorkspaceInvitationStatus(query, c.Query("status"))
+
+	var invitations []models.WorkspaceInvitation
+	if err := query.Order("workspace_invitations.created_at DESC, workspace_invitations.id DESC").Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaceInvitationResponses(invitations))
+}
+
+// RevokeWorkspaceInvitation revokes a pending invitation.
+// @Summary Revoke workspace invitation
+// @Description Revoke a pending invitation. Requires owner or manager role.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param invitation_id path int true "Invitation ID"
+// @Success 200 {object} map[string]string "Invitation revoked"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 409 {object} map[string]string "Invitation is no longer pending"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id}/invitations/{invitation_id} [delete]
+func RevokeWorkspaceInvitation(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceMembers, constants.WorkspaceActionDelete) {
+		return
+	}
+	invitationID, err := strconv.ParseUint(c.Param("invitation_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	var invitation models.WorkspaceInvitation
+	if err := database.DB.Where("id = ? AND workspace_id = ?", invitationID, member.WorkspaceID).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitation"})
+		return
+	}
+
+	result := database.DB.Model(&models.WorkspaceInvitation{}).
+		Where("id = ? AND status = ?", invitation.ID, constants.WorkspaceInvitationPending).
+		Updates(map[string]interface{}{"status": constants.WorkspaceInvitationRevoked, "responded_at": time.Now()})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Invitation is no longer pending"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// GetMyInvitations lists invitations addressed to the authenticated user.
+// @Summary Get my invitations
+// @Description List pending, unexpired invitations addressed to the authenticated user by username.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} WorkspaceInvitationResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/invitations [get]
+func GetMyInvitations(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var invitations []models.WorkspaceInvitation
+	if err := workspaceInvitationQuery().
+		Where("workspace_invitations.invitee_user_id = ? AND workspace_invitations.status = ? AND workspace_invitations.expires_at > ?",
+			userID, constants.WorkspaceInvitationPending, time.Now()).
+		Order("workspace_invitations.created_at DESC, workspace_invitations.id DESC").
+		Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaceInvitationResponses(invitations))
+}
+
+// AcceptInvitation accepts an invitation addressed to the authenticated user.
+// @Summary Accept invitation
+// @Description Accept a username invitation and join the workspace with the invited role.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} WorkspaceResponse
+// @Failure 400 {object} map[string]string "Invalid invitation ID"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 409 {object} map[string]string "Invitation is no longer pending"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/invitations/{id}/accept [post]
+func AcceptInvitation(c *gin.Context) {
+	invitation, ok := invitationForCurrentUser(c)
+	if !ok {
+		return
+	}
+	acceptWorkspaceInvitation(c, invitation)
+}
+
+// DeclineInvitation declines an invitation addressed to the authenticated user.
+// @Summary Decline invitation
+// @Description Decline a username invitation.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} map[string]string "Invitation declined"
+// @Failure 400 {object} map[string]string "Invalid invitation ID"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 409 {object} map[string]string "Invitation is no longer pending"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/invitations/{id}/decline [post]
+func DeclineInvitation(c *gin.Context) {
+	invitation, ok := invitationForCurrentUser(c)
+	if !ok {
+		return
+	}
+	declineWorkspaceInvitation(c, invitation)
+}
+
+// AcceptInvitationToken accepts a link invitation.
+// @Summary Accept invitation link
+// @Description Redeem a one-time link invitation token and join the workspace with the invited role.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param token body models.WorkspaceInvitationTokenDTO true "Invitation token"
+// @Success 200 {object} WorkspaceResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 409 {object} map[string]string "Invitation is no longer pending"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/invitations/accept [post]
+func AcceptInvitationToken(c *gin.Context) {
+	invitation, ok := invitationFromToken(c)
+	if !ok {
+		return
+	}
+	acceptWorkspaceInvitation(c, invitation)
+}
+
+// DeclineInvitationToken declines a link invitation.
+// @Summary Decline invitation link
+// @Description Decline a one-time link invitation token so it can no longer be redeemed.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param token body models.WorkspaceInvitationTokenDTO true "Invitation token"
+// @Success 200 {object} map[string]string "Invitation declined"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 409 {object} map[string]string "Invitation is no longer pending"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/invitations/decline [post]
+func DeclineInvitationToken(c *gin.Context) {
+	invitation, ok := invitationFromToken(c)
+	if !ok {
+		return
+	}
+	declineWorkspaceInvitation(c, invitation)
+}
+
+func acceptWorkspaceInvitation(c *gin.Context, invitation models.WorkspaceInvitation) {
+	userID := c.MustGet("userID").(uint)
+
+	member, err := database.AcceptWorkspaceInvitation(database.DB, invitation.ID, userID)
+	if err != nil {
+		respondWorkspaceInvitationError(c, err, "Failed to accept invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaceResponseFromMembership(member))
+}
+
+func declineWorkspaceInvitation(c *gin.Context, invitation models.WorkspaceInvitation) {
+	if err := database.DeclineWorkspaceInvitation(database.DB, invitation.ID); err != nil {
+		respondWorkspaceInvitationError(c, err, "Failed to decline invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// invitationForCurrentUser loads the username invitation named by the :id path parameter.
+// Invitations addressed to other users are reported as not found.
+func invitationForCurrentUser(c *gin.Context) (models.WorkspaceInvitation, bool) {
+	userID := c.MustGet("userID").(uint)
+	invitationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return models.WorkspaceInvitation{}, false
+	}
+
+	var invitation models.WorkspaceInvitation
+	if err := database.DB.Where("id = ? AND invitee_user_id = ?", invitationID, userID).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+			return models.WorkspaceInvitation{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitation"})
+		return models.WorkspaceInvitation{}, false
+	}
+
+	return invitation, true
+}
+
+// invitationFromToken loads the link invitation matching the token in the request body.
+func invitationFromToken(c *gin.Context) (models.WorkspaceInvitation, bool) {
+	var requestData models.WorkspaceInvitationTokenDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return models.WorkspaceInvitation{}, false
+	}
+
+	var invitation models.WorkspaceInvitation
+	tokenHash := utils.HashToken(strings.TrimSpace(requestData.Token))
+	if err := database.DB.Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+			return models.WorkspaceInvitation{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitation"})
+		return models.WorkspaceInvitation{}, false
+	}
+
+	return invitation, true
+}
+
+func respondWorkspaceInvitationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrWorkspaceInvitationInactive):
+		c.JSON(http.StatusConflict, gin.H{"error": "Invitation is no longer pending"})
+	case errors.Is(err, database.ErrWorkspaceMemberExists), isUniqueViolation(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a workspace member"})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func workspaceInvitationQuery() *gorm.DB {
+	return database.DB.
+		Joins("JOIN workspaces ON workspaces.id = workspace_invitations.workspace_id AND workspaces.deleted_at IS NULL").
+		Preload("Workspace").
+		Preload("InvitedBy").
+		Preload("Invitee")
+}
+
+// filterWorkspaceInvitationStatus filters by effective status; expired covers pending invitations past their deadline.
+func filterWorkspaceInvitationStatus(query *gorm.DB, status string) *gorm.DB {
+	now := time.Now()
+	switch status {
+	case "":
+		return query
+	case constants.WorkspaceInvitationPending:
+		return query.Where("workspace_invitations.status = ? AND workspace_invitations.expires_at > ?", status, now)
+	case constants.WorkspaceInvitationExpired:
+		return query.Where("workspace_invitations.status = ? AND workspace_invitations.expires_at <= ?", constants.WorkspaceInvitationPending, now)
+	default:
+		return query.Where("workspace_invitations.status = ?", status)
+	}
+}
+
+func loadWorkspaceInvitationResponse(invitationID uint) (WorkspaceInvitationResponse, error) {
+	var invitation models.WorkspaceInvitation
+	if err := workspaceInvitationQuery().First(&invitation, "workspace_invitations.id = ?", invitationID).Error; err != nil {
+		return WorkspaceInvitationResponse{}, err
+	}
+	return workspaceInvitationResponse(invitation), nil
+}
+
+func workspaceInvitationResponses(invitations []models.WorkspaceInvitation) []WorkspaceInvitationResponse {
+	response := make([]WorkspaceInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, workspaceInvitationResponse(invitation))
+	}
+	return response
+}
+
+func workspaceInvitationResponse(invitation models.WorkspaceInvitation) WorkspaceInvitationResponse {
+	status := invitation.Status
+	if status == constants.WorkspaceInvitationPending && !invitation.ExpiresAt.After(time.Now()) {
+		status = constants.WorkspaceInvitationExpired
+	}
+
+	response := WorkspaceInvitationResponse{
+		ID:                invitation.ID,
+		WorkspaceID:       invitation.WorkspaceID,
+		WorkspaceName:     invitation.Workspace.Name,
+		Role:              invitation.Role,
+		Status:            status,
+		InviteeUserID:     invitation.InviteeUserID,
+		InvitedByUserID:   invitation.InvitedByUserID,
+		InvitedByUsername: invitation.InvitedBy.Username,
+		ExpiresAt:         invitation.ExpiresAt,
+		RespondedAt:       invitation.RespondedAt,
+		CreatedAt:         invitation.CreatedAt,
+	}
+	if invitation.Invitee != nil {
+		response.InviteeUsername = invitation.Invitee.Username
+	}
+	return response
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+func setupWorkspaceInvitationTest(t *testing.T) (workspaceManagementFixture, models.User) {
+	t.Helper()
+
+	fixture := setupWorkspaceManagementTest(t)
+	if err := database.DB.AutoMigrate(&models.WorkspaceInvitation{}); err != nil {
+		t.Fatalf("migrate invitations: %v", err)
+	}
+
+	guest := models.User{Username: "workspace-guest", Password: "hashed"}
+	if err := database.DB.Create(&guest).Error; err != nil {
+		t.Fatalf("create guest: %v", err)
+	}
+	return fixture, guest
+}
+
+func TestUsernameInvitationAcceptAddsMemberWithRole(t *testing.T) {
+	fixture, guest := setupWorkspaceInvitationTest(t)
+	invitationsPath := "/workspaces/" + uintToString(fixture.SharedWorkspace.ID) + "/invitations"
+
+	response := runWorkspaceJSONRequest(fixture.Viewer.ID, 0, CreateWorkspaceInvitation, http.MethodPost, "/workspaces/:id/invitations", invitationsPath,
+		models.WorkspaceInvitationCreateDTO{Username: guest.Username, Role: constants.WorkspaceRoleOperator})
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("viewer invite status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, CreateWorkspaceInvitation, http.MethodPost, "/workspaces/:id/invitations", invitationsPath,
+		models.WorkspaceInvitationCreateDTO{Username: guest.Username, Role: "chef"})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("invalid role status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, CreateWorkspaceInvitation, http.MethodPost, "/workspaces/:id/invitations", invitationsPath,
+		models.WorkspaceInvitationCreateDTO{Username: guest.Username, Role: constants.WorkspaceRoleOperator})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("invite status = %d body = %s", response.Code, response.Body.String())
+	}
+	var invitation WorkspaceInvitationResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &invitation); err != nil {
+		t.Fatalf("decode invitation: %v", err)
+	}
+	if invitation.Status != constants.WorkspaceInvitationPending || invitation.InviteeUsername != guest.Username || invitation.Token != "" {
+		t.Fatalf("invitation = %+v, want pending username invitation without token", invitation)
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, CreateWorkspaceInvitation, http.MethodPost, "/workspaces/:id/invitations", invitationsPath,
+		models.WorkspaceInvitationCreateDTO{Username: guest.Username, Role: constants.WorkspaceRoleViewer})
+	if response.Code != http.StatusConflict {
+		t.Fatalf("duplicate invite status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.Viewer.ID, 0, AcceptInvitation, http.MethodPost, "/invitations/:id/accept", "/invitations/"+uintToString(invitation.ID)+"/accept")
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("foreign accept status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(guest.ID, 0, AcceptInvitation, http.MethodPost, "/invitations/:id/accept", "/invitations/"+uintToString(invitation.ID)+"/accept")
+	if response.Code != http.StatusOK {
+		t.Fatalf("accept status = %d body = %s", response.Code, response.Body.String())
+	}
+	member, found, err := database.FindWorkspaceMember(database.DB, guest.ID, fixture.SharedWorkspace.ID)
+	if err != nil || !found || member.Role != constants.WorkspaceRoleOperator {
+		t.Fatalf("guest membership = %+v found=%t err=%v, want operator", member, found, err)
+	}
+
+	response = runWorkspaceRequest(guest.ID, 0, AcceptInvitation, http.MethodPost, "/invitations/:id/accept", "/invitations/"+uintToString(invitation.ID)+"/accept")
+	if response.Code != http.StatusConflict {
+		t.Fatalf("second accept status = %d body = %s", response.Code, response.Body.String())
+	}
+}
+
+func TestLinkInvitationTokenIsSingleUseAndExpires(t *testing.T) {
+	fixture, guest := setupWorkspaceInvitationTest(t)
+	invitationsPath := "/workspaces/" + uintToString(fixture.SharedWorkspace.ID) + "/invitations"
+
+	response := runWorkspaceJSONRequest(fixture.Owner.ID, 0, CreateWorkspaceInvitation, http.MethodPost, "/workspaces/:id/invitations",
+		"/workspaces/"+uintToString(fixture.PersonalWorkspace.ID)+"/invitations",
+		models.WorkspaceInvitationCreateDTO{Role: constants.WorkspaceRoleViewer})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("personal workspace invite status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, CreateWorkspaceInvitation, http.MethodPost, "/workspaces/:id/invitations", invitationsPath,
+		models.WorkspaceInvitationCreateDTO{Role: constants.WorkspaceRoleManager})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("link invite status = %d body = %s", response.Code, response.Body.String())
+	}
+	var invitation WorkspaceInvitationResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &invitation); err != nil {
+		t.Fatalf("decode invitation: %v", err)
+	}
+	if invitation.Token == "" {
+		t.Fatalf("link invitation token is empty")
+	}
+
+	var stored models.WorkspaceInvitation
+	if err := database.DB.First(&stored, invitation.ID).Error; err != nil {
+		t.Fatalf("load invitation: %v", err)
+	}
+	if stored.TokenHash == nil || *stored.TokenHash == invitation.Token {
+		t.Fatalf("stored token hash = %v, want hashed token", stored.TokenHash)
+	}
+
+	tokenBody := models.WorkspaceInvitationTokenDTO{Token: invitation.Token}
+	response = runWorkspaceJSONRequest(guest.ID, 0, AcceptInvitationToken, http.MethodPost, "/invitations/accept", "/invitations/accept", tokenBody)
+	if response.Code != http.StatusOK {
+		t.Fatalf("token accept status = %d body = %s", response.Code, response.Body.String())
+	}
+	var joined WorkspaceResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &joined); err != nil {
+		t.Fatalf("decode workspace: %v", err)
+	}
+	if joined.ID != fixture.SharedWorkspace.ID || joined.Role != constants.WorkspaceRoleManager {
+		t.Fatalf("joined workspace = %+v, want shared workspace as manager", joined)
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Viewer.ID, 0, AcceptInvitationToken, http.MethodPost, "/invitations/accept", "/invitations/accept", tokenBody)
+	if response.Code != http.StatusConflict {
+		t.Fatalf("reused token status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	expired := models.WorkspaceInvitation{
+		WorkspaceID:     fixture.SharedWorkspace.ID,
+		InvitedByUserID: fixture.Owner.ID,
+		InviteeUserID:   &fixture.Viewer.ID,
+		Role:            constants.WorkspaceRoleManager,
+		Status:          constants.WorkspaceInvitationPending,
+		ExpiresAt:       time.Now().Add(-time.Hour),
+	}
+	if err := database.DB.Create(&expired).Error; err != nil {
+		t.Fatalf("create expired invitation: %v", err)
+	}
+	response = runWorkspaceRequest(fixture.Viewer.ID, 0, AcceptInvitation, http.MethodPost, "/invitations/:id/accept", "/invitations/"+uintToString(expired.ID)+"/accept")
+	if response.Code != http.StatusConflict {
+		t.Fatalf("expired accept status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.Owner.ID, 0, GetWorkspaceInvitations, http.MethodGet, "/workspaces/:id/invitations", invitationsPath+"?status=expired")
+	if response.Code != http.StatusOK {
+		t.Fatalf("list invitations status = %d body = %s", response.Code, response.Body.String())
+	}
+	var listed []WorkspaceInvitationResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode invitations: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != expired.ID || listed[0].Status != constants.WorkspaceInvitationExpired {
+		t.Fatalf("expired invitations = %+v, want only %d", listed, expired.ID)
+	}
+}
+
+func TestWorkspaceMembersKeepLastOwner(t *testing.T) {
+	fixture, _ := setupWorkspaceInvitationTest(t)
+	membersPath := "/workspaces/" + uintToString(fixture.SharedWorkspace.ID) + "/members/"
+	ownerPath := membersPath + uintToString(fixture.Owner.ID)
+	viewerPath := membersPath + uintToString(fixture.Viewer.ID)
+
+	response := runWorkspaceRequest(fixture.Viewer.ID, 0, GetWorkspaceMembers, http.MethodGet, "/workspaces/:id/members", "/workspaces/"+uintToString(fixture.SharedWorkspace.ID)+"/members")
+	if response.Code != http.StatusOK {
+		t.Fatalf("list members status = %d body = %s", response.Code, response.Body.String())
+	}
+	var members []WorkspaceMemberResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &members); err != nil {
+		t.Fatalf("decode members: %v", err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("member count = %d, want 2", len(members))
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, UpdateWorkspaceMember, http.MethodPut, "/workspaces/:id/members/:user_id", ownerPath,
+		models.WorkspaceMemberRoleDTO{Role: constants.WorkspaceRoleManager})
+	if response.Code != http.StatusConflict {
+		t.Fatalf("demote last owner status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.Owner.ID, 0, DeleteWorkspaceMember, http.MethodDelete, "/workspaces/:id/members/:user_id", ownerPath)
+	if response.Code != http.StatusConflict {
+		t.Fatalf("remove last owner status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Viewer.ID, 0, UpdateWorkspaceMember, http.MethodPut, "/workspaces/:id/members/:user_id", viewerPath,
+		models.WorkspaceMemberRoleDTO{Role: constants.WorkspaceRoleOwner})
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("viewer self-promotion status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, UpdateWorkspaceMember, http.MethodPut, "/workspaces/:id/members/:user_id", viewerPath,
+		models.WorkspaceMemberRoleDTO{Role: constants.WorkspaceRoleOwner})
+	if response.Code != http.StatusOK {
+		t.Fatalf("promote viewer status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.Owner.ID, 0, DeleteWorkspaceMember, http.MethodDelete, "/workspaces/:id/members/:user_id", ownerPath)
+	if response.Code != http.StatusOK {
+		t.Fatalf("leave with another owner status = %d body = %s", response.Code, response.Body.String())
+	}
+	if _, found, err := database.FindWorkspaceMember(database.DB, fixture.Owner.ID, fixture.SharedWorkspace.ID); err != nil || found {
+		t.Fatalf("removed owner membership found=%t err=%v, want removed", found, err)
+	}
+}
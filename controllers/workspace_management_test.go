@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+)
+
+type workspaceManagementFixture struct {
+	Owner             models.User
+	Viewer            models.User
+	PersonalWorkspace models.Workspace
+	SharedWorkspace   models.Workspace
+}
+
+func setupWorkspaceManagementTest(t *testing.T) workspaceManagementFixture {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.WorkspaceSettings{},
+		&models.TwoFactor{},
+	); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	database.DB = db
+
+	owner := models.User{Username: "workspace-owner", Password: "hashed"}
+	viewer := models.User{Username: "workspace-viewer", Password: "hashed"}
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+	if err := db.Create(&viewer).Error; err != nil {
+		t.Fatalf("create viewer: %v", err)
+	}
+
+	personalMember, err := database.EnsurePersonalWorkspaceForUser(db, owner.ID)
+	if err != nil {
+		t.Fatalf("create personal workspace: %v", err)
+	}
+	sharedMember, err := database.CreateBusinessWorkspace(db, owner.ID, "Shared kitchen", "")
+	if err != nil {
+		t.Fatalf("create shared workspace: %v", err)
+	}
+	viewerMember := models.WorkspaceMember{WorkspaceID: sharedMember.WorkspaceID, UserID: viewer.ID, Role: constants.WorkspaceRoleViewer}
+	if err := db.Create(&viewerMember).Error; err != nil {
+		t.Fatalf("create viewer membership: %v", err)
+	}
+
+	return workspaceManagementFixture{
+		Owner:             owner,
+		Viewer:            viewer,
+		PersonalWorkspace: personalMember.Workspace,
+		SharedWorkspace:   sharedMember.Workspace,
+	}
+}
+
+func TestCreateWorkspaceMakesCreatorOwnerWithUniqueSlug(t *testing.T) {
+	fixture := setupWorkspaceManagementTest(t)
+
+	if fixture.SharedWorkspace.Slug != "shared-kitchen" {
+		t.Fatalf("shared workspace slug = %q, want shared-kitchen", fixture.SharedWorkspace.Slug)
+	}
+
+	response := runWorkspaceJSONRequest(fixture.Viewer.ID, 0, CreateWorkspace, http.MethodPost, "/workspaces", "/workspaces", models.WorkspaceCreateDTO{Name: "Shared Kitchen"})
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create workspace status = %d body = %s", response.Code, response.Body.String())
+	}
+	var created WorkspaceResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode workspace: %v", err)
+	}
+	if created.Slug != "shared-kitchen-2" || created.Role != constants.WorkspaceRoleOwner || created.Name != "Shared Kitchen" {
+		t.Fatalf("created workspace = %+v, want slug shared-kitchen-2 and owner role", created)
+	}
+
+	member, found, err := database.FindWorkspaceMember(database.DB, fixture.Viewer.ID, created.ID)
+	if err != nil || !found || member.Role != constants.WorkspaceRoleOwner {
+		t.Fatalf("creator membership = %+v found=%t err=%v, want owner", member, found, err)
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Viewer.ID, 0, CreateWorkspace, http.MethodPost, "/workspaces", "/workspaces", models.WorkspaceCreateDTO{Name: "Other", Slug: "shared-kitchen"})
+	if response.Code != http.StatusConflict {
+		t.Fatalf("duplicate slug status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Viewer.ID, 0, CreateWorkspace, http.MethodPost, "/workspaces", "/workspaces", models.WorkspaceCreateDTO{Name: "Sneaky", Slug: "personal-99"})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("reserved slug status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Viewer.ID, 0, CreateWorkspace, http.MethodPost, "/workspaces", "/workspaces", models.WorkspaceCreateDTO{Name: "   "})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("blank name status = %d body = %s", response.Code, response.Body.String())
+	}
+}
+
+func TestUpdateWorkspaceRenamesAndRequiresManagerRole(t *testing.T) {
+	fixture := setupWorkspaceManagementTest(t)
+	target := "/workspaces/" + uintToString(fixture.SharedWorkspace.ID)
+
+	newSlug := "smokehouse"
+	response := runWorkspaceJSONRequest(fixture.Owner.ID, 0, UpdateWorkspace, http.MethodPut, "/workspaces/:id", target, models.WorkspaceUpdateDTO{Name: "Smokehouse", Slug: &newSlug})
+	if response.Code != http.StatusOK {
+		t.Fatalf("rename workspace status = %d body = %s", response.Code, response.Body.String())
+	}
+	var renamed WorkspaceResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &renamed); err != nil {
+		t.Fatalf("decode workspace: %v", err)
+	}
+	if renamed.Name != "Smokehouse" || renamed.Slug != "smokehouse" {
+		t.Fatalf("renamed workspace = %+v, want Smokehouse/smokehouse", renamed)
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Viewer.ID, 0, UpdateWorkspace, http.MethodPut, "/workspaces/:id", target, models.WorkspaceUpdateDTO{Name: "Viewer rename"})
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("viewer rename status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, UpdateWorkspace, http.MethodPut, "/workspaces/:id", target, models.WorkspaceUpdateDTO{Name: " \t "})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("blank rename status = %d body = %s", response.Code, response.Body.String())
+	}
+	if err := database.DB.First(&fixture.SharedWorkspace, fixture.SharedWorkspace.ID).Error; err != nil || fixture.SharedWorkspace.Name != "Smokehouse" {
+		t.Fatalf("workspace name = %q err=%v, want Smokehouse kept", fixture.SharedWorkspace.Name, err)
+	}
+
+	personalSlug := "renamed-personal"
+	response = runWorkspaceJSONRequest(
+		fixture.Owner.ID,
+		0,
+		UpdateWorkspace,
+		http.MethodPut,
+		"/workspaces/:id",
+		"/workspaces/"+uintToString(fixture.PersonalWorkspace.ID),
+		models.WorkspaceUpdateDTO{Name: "My kitchen", Slug: &personalSlug},
+	)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("personal slug change status = %d body = %s", response.Code, response.Body.String())
+	}
+}
+
+func TestDeleteWorkspaceArchivesSharedWorkspaceOnly(t *testing.T) {
+	fixture := setupWorkspaceManagementTest(t)
+	sharedTarget := "/workspaces/" + uintToString(fixture.SharedWorkspace.ID)
+
+	response := runWorkspaceRequest(fixture.Viewer.ID, 0, DeleteWorkspace, http.MethodDelete, "/workspaces/:id", sharedTarget)
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("viewer archive status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.Owner.ID, 0, DeleteWorkspace, http.MethodDelete, "/workspaces/:id", "/workspaces/"+uintToString(fixture.PersonalWorkspace.ID))
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("personal archive status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceRequest(fixture.Owner.ID, 0, DeleteWorkspace, http.MethodDelete, "/workspaces/:id", sharedTarget)
+	if response.Code != http.StatusOK {
+		t.Fatalf("archive status = %d body = %s", response.Code, response.Body.String())
+	}
+	if _, found, err := database.FindWorkspaceMember(database.DB, fixture.Owner.ID, fixture.SharedWorkspace.ID); err != nil || found {
+		t.Fatalf("archived workspace membership found=%t err=%v, want hidden", found, err)
+	}
+
+	var archived models.Workspace
+	if err := database.DB.Unscoped().First(&archived, fixture.SharedWorkspace.ID).Error; err != nil {
+		t.Fatalf("load archived workspace: %v", err)
+	}
+	if !archived.DeletedAt.Valid {
+		t.Fatalf("archived workspace deleted_at is not set")
+	}
+}
+
+func TestWorkspaceRoutesByIDEnforceTwoFactorRequirement(t *testing.T) {
+	fixture := setupWorkspaceManagementTest(t)
+	target := "/workspaces/" + uintToString(fixture.SharedWorkspace.ID)
+
+	settings := database.DefaultWorkspaceSettings(fixture.SharedWorkspace.ID)
+	settings.RequireTwoFactor = true
+	if err := database.SaveWorkspaceSettings(database.DB, &settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	response := runWorkspaceJSONRequest(fixture.Owner.ID, 0, UpdateWorkspace, http.MethodPut, "/workspaces/:id", target, models.WorkspaceUpdateDTO{Name: "Smokehouse"})
+	var denied map[string]string
+	if err := json.Unmarshal(response.Body.Bytes(), &denied); err != nil {
+		t.Fatalf("decode denial: %v", err)
+	}
+	if response.Code != http.StatusForbidden || denied["reason"] != middleware.PermissionReasonTwoFactor {
+		t.Fatalf("rename without two-factor status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	enabledAt := time.Now()
+	if err := database.DB.Create(&models.TwoFactor{UserID: fixture.Owner.ID, Secret: "secret", EnabledAt: &enabledAt}).Error; err != nil {
+		t.Fatalf("enable two-factor: %v", err)
+	}
+	response = runWorkspaceJSONRequest(fixture.Owner.ID, 0, UpdateWorkspace, http.MethodPut, "/workspaces/:id", target, models.WorkspaceUpdateDTO{Name: "Smokehouse"})
+	if response.Code != http.StatusOK {
+		t.Fatalf("rename with two-factor status = %d body = %s", response.Code, response.Body.String())
+	}
+}
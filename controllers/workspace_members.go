@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceMemberResponse represents a workspace member.
+type WorkspaceMemberResponse struct {
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// GetWorkspaceMembers lists members of a workspace.
+// @Summary Get workspace members
+// @Description List members of a workspace the authenticated user belongs to
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Success 200 {array} WorkspaceMemberResponse
+// @Failure 400 {object} map[string]string "Invalid workspace ID"
+// @Failure 403 {object} map[string]string "Workspace access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id}/members [get]
+func GetWorkspaceMembers(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceMembers, constants.WorkspaceActionRead) {
+		return
+	}
+
+	var members []models.WorkspaceMember
+	if err := database.DB.
+		Preload("User").
+		Where("workspace_id = ?", member.WorkspaceID).
+		Order("created_at ASC, id ASC").
+		Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace members"})
+		return
+	}
+
+	response := make([]WorkspaceMemberResponse, 0, len(members))
+	for _, workspaceMember := range members {
+		response = append(response, workspaceMemberResponse(workspaceMember))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateWorkspaceMember changes a member role.
+// @Summary Update workspace member role
+// @Description Change a member role. Owners can assign any role; managers cannot assign or change the owner role. A workspace always keeps at least one owner.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param user_id path int true "User ID"
+// @Param role body models.WorkspaceMemberRoleDTO true "New role"
+// @Success 200 {object} WorkspaceMemberResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "Workspace member not found"
+// @Failure 409 {object} map[string]string "Workspace must keep at least one owner"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id}/members/{user_id} [put]
+func UpdateWorkspaceMember(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceMembers, constants.WorkspaceActionUpdate) {
+		return
+	}
+	targetUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var requestData models.WorkspaceMemberRoleDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !constants.IsValidWorkspaceRole(requestData.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace role"})
+		return
+	}
+
+	target, found, err := database.FindWorkspaceMember(database.DB, uint(targetUserID), member.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace member"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace member not found"})
+		return
+	}
+	if !ownerRoleChangeAllowed(member.Role, target.Role, requestData.Role) {
+		respondOwnerRoleRequired(c, member.Role)
+		return
+	}
+
+	updated, err := database.UpdateWorkspaceMemberRole(database.DB, member.WorkspaceID, uint(targetUserID), requestData.Role)
+	if err != nil {
+		respondWorkspaceMemberError(c, err, "Failed to update workspace member")
+		return
+	}
+	if err := database.DB.Preload("User").First(&updated, updated.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaceMemberResponse(updated))
+}
+
+// DeleteWorkspaceMember removes a member from a workspace.
+// @Summary Remove workspace member
+// @Description Remove a member from a workspace. Owners and managers can remove members (managers cannot remove owners); any member can remove themselves. A workspace always keeps at least one owner.
+// @Tags Workspace Members
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} map[string]string "Workspace member removed"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 404 {object} map[string]string "Workspace member not found"
+// @Failure 409 {object} map[string]string "Workspace must keep at least one owner"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id}/members/{user_id} [delete]
+func DeleteWorkspaceMember(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	targetUserID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if member.Workspace.PersonalUserID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Personal workspace membership cannot be changed"})
+		return
+	}
+
+	if uint(targetUserID) != member.UserID {
+		if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceMembers, constants.WorkspaceActionDelete) {
+			return
+		}
+		target, found, err := database.FindWorkspaceMember(database.DB, uint(targetUserID), member.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace member"})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workspace member not found"})
+			return
+		}
+		if !ownerRoleChangeAllowed(member.Role, target.Role, "") {
+			respondOwnerRoleRequired(c, member.Role)
+			return
+		}
+	}
+
+	if err := database.RemoveWorkspaceMember(database.DB, member.WorkspaceID, uint(targetUserID)); err != nil {
+		respondWorkspaceMemberError(c, err, "Failed to remove workspace member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace member removed"})
+}
+
+// ownerRoleChangeAllowed reports whether actorRole may change a member with targetRole to newRole.
+// Only owners can grant the owner role or change and remove other owners; an empty newRole means removal.
+func ownerRoleChangeAllowed(actorRole string, targetRole string, newRole string) bool {
+	if targetRole != constants.WorkspaceRoleOwner && newRole != constants.WorkspaceRoleOwner {
+		return true
+	}
+	return actorRole == constants.WorkspaceRoleOwner
+}
+
+func respondOwnerRoleRequired(c *gin.Context, role string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":  "Only owners can grant or change the owner role",
+		"reason": middleware.PermissionReasonOwnerRole,
+		"role":   role,
+	})
+}
+
+func respondWorkspaceMemberError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrWorkspaceMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace member not found"})
+	case errors.Is(err, database.ErrLastWorkspaceOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "Workspace must keep at least one owner"})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func workspaceMemberResponse(member models.WorkspaceMember) WorkspaceMemberResponse {
+	return WorkspaceMemberResponse{
+		UserID:   member.UserID,
+		Username: member.User.Username,
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}
+}
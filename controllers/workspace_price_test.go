@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -46,10 +47,15 @@ func setupWorkspacePriceTest(t *testing.T) workspacePriceFixture {
 		&models.User{},
 		&models.Workspace{},
 		&models.WorkspaceMember{},
+		&models.WorkspaceSettings{},
 		&models.Ingredient{},
 		&models.Price{},
 		&models.Recipe{},
 		&models.RecipeIngredient{},
+		&models.RecipeSubRecipe{},
+		&models.RecipeStep{},
+		&models.RecipeRevision{},
+		&models.AuditLog{},
 	); err != nil {
 		t.Fatalf("migrate test database: %v", err)
 	}
@@ -218,6 +224,46 @@ func TestGetRecipeCostUsesActiveWorkspacePrice(t *testing.T) {
 	assertAttachedLatestPrice(t, secondRecipe, 20, fixture.SecondWorkspace.ID)
 }
 
+func TestGetRecipeReturnsFinishedProductCosts(t *testing.T) {
+	fixture := setupWorkspacePriceTest(t)
+	createPrice(t, fixture.User.ID, fixture.PersonalWorkspace.ID, fixture.Ingredient.ID, 10)
+	recipePath := "/recipes/" + uintToString(fixture.Recipe.ID)
+
+	recipe := getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if recipe.CostPerKg != nil || recipe.CostPerPiece != nil || recipe.FinishedQuantity != nil {
+		t.Fatalf("recipe without yield costs = %v/%v/%v, want none", recipe.FinishedQuantity, recipe.CostPerKg, recipe.CostPerPiece)
+	}
+
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateRecipe, http.MethodPut, "/recipes/:id", recipePath,
+		map[string]any{"name": "Jerky", "yield_quantity": "1", "yield_unit": "kg", "process_loss": 1})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("update with total loss status = %d, want 400", response.Code)
+	}
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateRecipe, http.MethodPut, "/recipes/:id", recipePath,
+		map[string]any{"name": "Jerky", "yield_quantity": "1", "yield_unit": "kg", "process_loss": 0.6, "piece_size": "50", "piece_unit": "g"})
+	if response.Code != http.StatusOK {
+		t.Fatalf("update yield status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	// 1 kg of raw meat at 10 per kg dries to 400 g
+	recipe = getRecipeForWorkspace(t, fixture, fixture.PersonalWorkspace.ID)
+	if recipe.FinishedQuantity == nil || math.Abs(*recipe.FinishedQuantity-0.4) > 1e-9 {
+		t.Fatalf("finished quantity = %v, want 0.4", recipe.FinishedQuantity)
+	}
+	if recipe.CostPerKg == nil || math.Abs(*recipe.CostPerKg-25) > 1e-9 {
+		t.Fatalf("cost per kg = %v, want 25", recipe.CostPerKg)
+	}
+	if recipe.CostPerPiece == nil || math.Abs(*recipe.CostPerPiece-1.25) > 1e-9 {
+		t.Fatalf("cost per piece = %v, want 1.25", recipe.CostPerPiece)
+	}
+
+	response = runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetRecipes, http.MethodGet, "/recipes", "/recipes")
+	var recipes []models.Recipe
+	if err := json.Unmarshal(response.Body.Bytes(), &recipes); err != nil || len(recipes) != 1 || recipes[0].CostPerKg == nil {
+		t.Fatalf("recipes = %s", response.Body.String())
+	}
+}
+
 func TestGetRecipeCostUsesNewestPriceWhenDatesTie(t *testing.T) {
 	fixture := setupWorkspacePriceTest(t)
 	priceDate := time.Date(2026, time.May, 30, 0, 0, 0, 0, time.UTC)
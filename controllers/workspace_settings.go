@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// GetWorkspaceSettings returns settings of the current workspace.
+// @Summary Get workspace settings
+// @Description Get settings of the workspace resolved for the current request. Defaults are returned when the workspace has not changed any setting.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Success 200 {object} models.WorkspaceSettings
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Workspace access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/settings [get]
+func GetWorkspaceSettings(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	settings, err := database.GetWorkspaceSettings(database.DB, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workspace settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateWorkspaceSettings partially updates settings of the current workspace.
+// @Summary Update workspace settings
+// @Description Update strict ingredient mode, default currency (ISO 4217), timezone (IANA), default order status, unit system (metric or imperial), low-margin threshold and whether members must use two-factor authentication. Omitted fields keep their values. Requires owner or manager role; only owners with two-factor authentication enabled can change require_two_factor.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-Workspace-ID header int false "Workspace ID"
+// @Param settings body models.WorkspaceSettingsUpdateDTO true "Settings to change"
+// @Success 200 {object} models.WorkspaceSettings
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Insufficient workspace permissions"
+// @Failure 409 {object} map[string]string "Owner has no two-factor authentication"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/current/settings [patch]
+func UpdateWorkspaceSettings(c *gin.Context) {
+	workspaceID := c.MustGet("workspaceID").(uint)
+
+	var requestData models.WorkspaceSettingsUpdateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := database.GetWorkspaceSettings(database.DB, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workspace settings"})
+		return
+	}
+
+	if requestData.StrictIngredients != nil {
+		settings.StrictIngredients = *requestData.StrictIngredients
+	}
+	if requestData.DefaultCurrency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*requestData.DefaultCurrency))
+		if !currencyCodePattern.MatchString(currency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "default_currency must be a three-letter ISO 4217 code"})
+			return
+		}
+		settings.DefaultCurrency = currency
+	}
+	if requestData.Timezone != nil {
+		timezone := strings.TrimSpace(*requestData.Timezone)
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || strings.EqualFold(timezone, "Local") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "timezone must be an IANA time zone such as Europe/Belgrade"})
+			return
+		}
+		settings.Timezone = timezone
+	}
+	if requestData.DefaultOrderStatus != nil {
+		if !constants.IsValidOrderStatus(*requestData.DefaultOrderStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order status"})
+			return
+		}
+		settings.DefaultOrderStatus = *requestData.DefaultOrderStatus
+	}
+	if requestData.UnitSystem != nil {
+		if !constants.IsValidUnitSystem(*requestData.UnitSystem) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unit_system must be metric or imperial"})
+			return
+		}
+		settings.UnitSystem = *requestData.UnitSystem
+	}
+	if requestData.LowMarginThresholdPercent != nil {
+		settings.LowMarginThresholdPercent = *requestData.LowMarginThresholdPercent
+	}
+	if requestData.RequireTwoFactor != nil && *requestData.RequireTwoFactor != settings.RequireTwoFactor {
+		// Only an owner signed in as themselves may change who can access the workspace
+		role := c.GetString("workspaceRole")
+		if _, isAPIKey := c.Get("apiKeyID"); isAPIKey || role != constants.WorkspaceRoleOwner {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":  "Only workspace owners can change the two-factor requirement",
+				"reason": middleware.PermissionReasonOwnerRole,
+				"role":   role,
+			})
+			return
+		}
+		if *requestData.RequireTwoFactor {
+			userID := c.MustGet("userID").(uint)
+			enabled, err := database.UserHasTwoFactor(database.DB, userID)
+			if err != nil {
+				log.Printf("Failed to check two-factor status of user %d: %v", userID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save workspace settings"})
+				return
+			}
+			if !enabled {
+				c.JSON(http.StatusConflict, gin.H{"error": "Enable two-factor authentication on your account before requiring it"})
+				return
+			}
+		}
+		settings.RequireTwoFactor = *requestData.RequireTwoFactor
+	}
+
+	if err := database.SaveWorkspaceSettings(database.DB, &settings); err != nil {
+		log.Printf("Failed to save settings for workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save workspace settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// loadWorkspaceLocation returns the time zone of the workspace, in which plain dates of query
+// parameters are read.
+func loadWorkspaceLocation(c *gin.Context, workspaceID uint) (*time.Location, bool) {
+	settings, err := database.GetWorkspaceSettings(database.DB, workspaceID)
+	if err != nil {
+		log.Printf("Failed to load settings of workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workspace settings"})
+		return nil, false
+	}
+	return workspaceLocation(settings), true
+}
+
+// workspaceLocation returns the configured workspace time zone, falling back to UTC.
+func workspaceLocation(settings models.WorkspaceSettings) *time.Location {
+	location, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
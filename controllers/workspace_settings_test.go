@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/models"
+)
+
+func TestWorkspaceSettingsDefaultsAndPartialUpdate(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+
+	response := runWorkspaceRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, GetWorkspaceSettings, http.MethodGet, "/workspaces/current/settings", "/workspaces/current/settings")
+	if response.Code != http.StatusOK {
+		t.Fatalf("get settings status = %d body = %s", response.Code, response.Body.String())
+	}
+	var settings models.WorkspaceSettings
+	if err := json.Unmarshal(response.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if settings != database.DefaultWorkspaceSettings(fixture.PersonalWorkspace.ID) {
+		t.Fatalf("default settings = %+v", settings)
+	}
+
+	for name, body := range map[string]map[string]any{
+		"currency":     {"default_currency": "euro"},
+		"timezone":     {"timezone": "Mars/Olympus"},
+		"order status": {"default_order_status": "shipped"},
+		"unit system":  {"unit_system": "nautical"},
+		"margin":       {"low_margin_threshold_percent": 150},
+	} {
+		response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateWorkspaceSettings, http.MethodPatch, "/workspaces/current/settings", "/workspaces/current/settings", body)
+		if response.Code != http.StatusBadRequest {
+			t.Fatalf("invalid %s status = %d body = %s", name, response.Code, response.Body.String())
+		}
+	}
+
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateWorkspaceSettings, http.MethodPatch, "/workspaces/current/settings", "/workspaces/current/settings", map[string]any{
+		"default_currency":     "rsd",
+		"timezone":             "Europe/Belgrade",
+		"default_order_status": constants.OrderStatusInProgress,
+		"unit_system":          constants.UnitSystemImperial,
+	})
+	if response.Code != http.StatusOK {
+		t.Fatalf("update settings status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, UpdateWorkspaceSettings, http.MethodPatch, "/workspaces/current/settings", "/workspaces/current/settings", map[string]any{
+		"strict_ingredients": true,
+	})
+	if response.Code != http.StatusOK {
+		t.Fatalf("second update settings status = %d body = %s", response.Code, response.Body.String())
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if settings.DefaultCurrency != "RSD" || settings.Timezone != "Europe/Belgrade" || settings.DefaultOrderStatus != constants.OrderStatusInProgress ||
+		settings.UnitSystem != constants.UnitSystemImperial || !settings.StrictIngredients {
+		t.Fatalf("updated settings = %+v, want RSD, Europe/Belgrade, in_progress, imperial and strict mode", settings)
+	}
+
+	secondSettings, err := database.GetWorkspaceSettings(database.DB, fixture.SecondWorkspace.ID)
+	if err != nil {
+		t.Fatalf("load second workspace settings: %v", err)
+	}
+	if secondSettings.StrictIngredients || secondSettings.DefaultCurrency != database.DefaultWorkspaceCurrency {
+		t.Fatalf("second workspace settings = %+v, want defaults", secondSettings)
+	}
+}
+
+func TestAddOrderUsesWorkspaceDefaultStatus(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+
+	settings := database.DefaultWorkspaceSettings(fixture.PersonalWorkspace.ID)
+	settings.DefaultOrderStatus = constants.OrderStatusReady
+	if err := database.SaveWorkspaceSettings(database.DB, &settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	payload := orderPayload(fixture.PersonalClient.ID, fixture.PersonalProduct.ID)
+	delete(payload, "status")
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, AddOrder, http.MethodPost, "/orders", "/orders", payload)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("add order status = %d body = %s", response.Code, response.Body.String())
+	}
+	var order models.Order
+	if err := json.Unmarshal(response.Body.Bytes(), &order); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if order.Status != constants.OrderStatusReady {
+		t.Fatalf("order status = %q, want workspace default %q", order.Status, constants.OrderStatusReady)
+	}
+
+	payload = orderPayload(fixture.SecondClient.ID, fixture.SecondProduct.ID)
+	delete(payload, "status")
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.SecondWorkspace.ID, AddOrder, http.MethodPost, "/orders", "/orders", payload)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("add second order status = %d body = %s", response.Code, response.Body.String())
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &order); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if order.Status != constants.OrderStatusNew {
+		t.Fatalf("second workspace order status = %q, want %q", order.Status, constants.OrderStatusNew)
+	}
+}
+
+func TestAddOrderDatesAreDaysOfWorkspaceTimezone(t *testing.T) {
+	fixture := setupWorkspaceBusinessTest(t)
+
+	settings := database.DefaultWorkspaceSettings(fixture.PersonalWorkspace.ID)
+	settings.Timezone = "Asia/Tokyo"
+	if err := database.SaveWorkspaceSettings(database.DB, &settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("load time zone: %v", err)
+	}
+
+	payload := orderPayload(fixture.PersonalClient.ID, fixture.PersonalProduct.ID)
+	payload["date"] = "2026-03-01"
+	response := runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, AddOrder, http.MethodPost, "/orders", "/orders", payload)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("add order status = %d body = %s", response.Code, response.Body.String())
+	}
+	var order models.Order
+	if err := json.Unmarshal(response.Body.Bytes(), &order); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if want := time.Date(2026, time.March, 1, 0, 0, 0, 0, tokyo); !order.Date.Equal(want) {
+		t.Fatalf("order date = %s, want %s", order.Date, want)
+	}
+
+	delete(payload, "date")
+	before := time.Now()
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, AddOrder, http.MethodPost, "/orders", "/orders", payload)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("add undated order status = %d body = %s", response.Code, response.Body.String())
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &order); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if order.Date.Before(before.Truncate(time.Second)) || order.Date.After(time.Now()) {
+		t.Fatalf("undated order date = %s, want the current time", order.Date)
+	}
+
+	payload["date"] = "01.03.2026"
+	response = runWorkspaceJSONRequest(fixture.User.ID, fixture.PersonalWorkspace.ID, AddOrder, http.MethodPost, "/orders", "/orders", payload)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("invalid date status = %d body = %s", response.Code, response.Body.String())
+	}
+}
@@ -1,9 +1,15 @@
 package controllers
 
 import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
 	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
 	"mobile-backend-go/models"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -93,6 +99,194 @@ func GetCurrentWorkspace(c *gin.Context) {
 	})
 }
 
+// CreateWorkspace creates a shared business workspace.
+// @Summary Create workspace
+// @Description Create a named business workspace. The authenticated user becomes its owner. When slug is omitted it is derived from the name.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspace body models.WorkspaceCreateDTO true "Workspace data"
+// @Success 201 {object} WorkspaceResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Workspace slug is already taken"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces [post]
+func CreateWorkspace(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	var requestData models.WorkspaceCreateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(requestData.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Workspace name must not be blank"})
+		return
+	}
+
+	member, err := database.CreateBusinessWorkspace(database.DB, userID, requestData.Name, requestData.Slug)
+	if err != nil {
+		if respondWorkspaceSlugError(c, err) {
+			return
+		}
+		log.Printf("Failed to create workspace for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspaceResponseFromMembership(member))
+}
+
+// UpdateWorkspace renames a workspace.
+// @Summary Update workspace
+// @Description Rename a workspace and optionally change the slug of a shared workspace. Requires owner or manager role.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Param workspace body models.WorkspaceUpdateDTO true "Workspace data"
+// @Success 200 {object} WorkspaceResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Workspace access denied"
+// @Failure 409 {object} map[string]string "Workspace slug is already taken"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id} [put]
+func UpdateWorkspace(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceWorkspace, constants.WorkspaceActionUpdate) {
+		return
+	}
+
+	var requestData models.WorkspaceUpdateDTO
+	if err := c.ShouldBindJSON(&requestData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(requestData.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Workspace name must not be blank"})
+		return
+	}
+
+	workspace := member.Workspace
+	if err := database.RenameWorkspace(database.DB, &workspace, requestData.Name, requestData.Slug); err != nil {
+		if respondWorkspaceSlugError(c, err) {
+			return
+		}
+		log.Printf("Failed to update workspace %d: %v", workspace.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workspace"})
+		return
+	}
+
+	member.Workspace = workspace
+	c.JSON(http.StatusOK, workspaceResponseFromMembership(member))
+}
+
+// DeleteWorkspace archives a shared workspace.
+// @Summary Archive workspace
+// @Description Soft-archive a shared workspace. Requires owner role. Personal workspaces cannot be archived.
+// @Tags Workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Workspace ID"
+// @Success 200 {object} map[string]string "Workspace archived"
+// @Failure 400 {object} map[string]string "Personal workspaces cannot be archived"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Workspace access denied"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /api/workspaces/{id} [delete]
+func DeleteWorkspace(c *gin.Context) {
+	member, ok := workspaceMemberFromParam(c)
+	if !ok {
+		return
+	}
+	if !requireWorkspacePermission(c, member.Role, constants.WorkspaceResourceWorkspace, constants.WorkspaceActionDelete) {
+		return
+	}
+	if member.Workspace.PersonalUserID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Personal workspaces cannot be archived"})
+		return
+	}
+
+	if err := database.DB.Delete(&models.Workspace{}, member.WorkspaceID).Error; err != nil {
+		log.Printf("Failed to archive workspace %d: %v", member.WorkspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace archived"})
+}
+
+// workspaceMemberFromParam resolves the caller's membership in the workspace named by the :id path parameter.
+func workspaceMemberFromParam(c *gin.Context) (models.WorkspaceMember, bool) {
+	userID := c.MustGet("userID").(uint)
+	workspaceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || workspaceID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return models.WorkspaceMember{}, false
+	}
+
+	member, found, err := database.FindWorkspaceMember(database.DB, userID, uint(workspaceID))
+	if err != nil {
+		log.Printf("Failed to resolve workspace %d for user %d: %v", workspaceID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve workspace"})
+		return models.WorkspaceMember{}, false
+	}
+	if !found {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Workspace access denied"})
+		return models.WorkspaceMember{}, false
+	}
+	if !middleware.TwoFactorRequirementMet(c, userID, member.WorkspaceID) {
+		return models.WorkspaceMember{}, false
+	}
+
+	return member, true
+}
+
+// requireWorkspacePermission checks the permission matrix for routes that resolve the workspace
+// from the path instead of WorkspaceMiddleware, writing the standard 403 response on failure.
+func requireWorkspacePermission(c *gin.Context, role string, resource string, action string) bool {
+	if constants.WorkspaceRoleAllows(role, resource, action) {
+		return true
+	}
+	middleware.AbortWorkspacePermissionDenied(c, role, resource, action)
+	return false
+}
+
+// requireCurrentWorkspacePermission checks an extra permission inside a handler behind
+// WorkspaceMiddleware, for requests whose options need more than the route allows. Like
+// RequireWorkspacePermission it also checks the scopes of API keys.
+func requireCurrentWorkspacePermission(c *gin.Context, resource string, action string) bool {
+	if !requireWorkspacePermission(c, c.GetString("workspaceRole"), resource, action) {
+		return false
+	}
+	if scopes, isAPIKey := c.Get("apiKeyScopes"); isAPIKey && !constants.APIKeyScopesAllow(scopes.([]string), resource, action) {
+		middleware.AbortAPIKeyScopeMissing(c, resource, action)
+		return false
+	}
+	return true
+}
+
+func respondWorkspaceSlugError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, database.ErrWorkspaceSlugInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug must contain only lowercase letters, digits and dashes and must not start with personal-"})
+	case errors.Is(err, database.ErrPersonalWorkspaceSlug):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Personal workspace slug cannot be changed"})
+	case errors.Is(err, database.ErrWorkspaceSlugTaken), isUniqueViolation(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "Workspace slug is already taken"})
+	default:
+		return false
+	}
+	return true
+}
+
 func workspaceResponseFromMembership(membership models.WorkspaceMember) WorkspaceResponse {
 	return WorkspaceResponse{
 		ID:        membership.Workspace.ID,
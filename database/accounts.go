@@ -0,0 +1,138 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/models"
+)
+
+var (
+	ErrLastAccountAdmin         = errors.New("account must keep at least one admin")
+	ErrAccountMemberNotFound    = errors.New("account member not found")
+	ErrAccountMemberExists      = errors.New("user is already an account admin")
+	ErrWorkspaceInOtherAccount  = errors.New("workspace already belongs to another account")
+	ErrPersonalWorkspaceAccount = errors.New("personal workspaces cannot belong to an account")
+	ErrWorkspaceNotInAccount    = errors.New("workspace does not belong to the account")
+)
+
+// CreateAccount creates an account and makes the creator its admin.
+func CreateAccount(db *gorm.DB, userID uint, name string) (models.Account, error) {
+	account := models.Account{Name: strings.TrimSpace(name)}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Workspaces", "Members").Create(&account).Error; err != nil {
+			return err
+		}
+		admin := models.AccountMember{AccountID: account.ID, UserID: userID, Role: constants.AccountRoleAdmin}
+		return tx.Omit("Account", "User").Create(&admin).Error
+	})
+
+	return account, err
+}
+
+// FindAccountMember returns the user's membership in an active account.
+func FindAccountMember(db *gorm.DB, userID uint, accountID uint) (models.AccountMember, bool, error) {
+	var member models.AccountMember
+	result := db.
+		Joins("JOIN accounts ON accounts.id = account_members.account_id AND accounts.deleted_at IS NULL").
+		Preload("Account").
+		Where("account_members.user_id = ? AND account_members.account_id = ?", userID, accountID).
+		Limit(1).
+		Find(&member)
+	if result.Error != nil {
+		return models.AccountMember{}, false, result.Error
+	}
+	return member, result.RowsAffected > 0, nil
+}
+
+// AttachWorkspaceToAccount groups a shared workspace under an account.
+func AttachWorkspaceToAccount(db *gorm.DB, accountID uint, workspaceID uint) (models.Workspace, error) {
+	var workspace models.Workspace
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := withRowLock(tx).First(&workspace, workspaceID).Error; err != nil {
+			return err
+		}
+		if workspace.PersonalUserID != nil {
+			return ErrPersonalWorkspaceAccount
+		}
+		if workspace.AccountID != nil {
+			if *workspace.AccountID == accountID {
+				return nil
+			}
+			return ErrWorkspaceInOtherAccount
+		}
+
+		workspace.AccountID = &accountID
+		return tx.Model(&models.Workspace{}).Where("id = ?", workspace.ID).Update("account_id", accountID).Error
+	})
+
+	return workspace, err
+}
+
+// DetachWorkspaceFromAccount removes a workspace from an account without touching its data.
+func DetachWorkspaceFromAccount(db *gorm.DB, accountID uint, workspaceID uint) error {
+	result := db.Model(&models.Workspace{}).
+		Where("id = ? AND account_id = ?", workspaceID, accountID).
+		Update("account_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWorkspaceNotInAccount
+	}
+	return nil
+}
+
+// AddAccountAdmin grants account admin access to a user.
+func AddAccountAdmin(db *gorm.DB, accountID uint, userID uint) (models.AccountMember, error) {
+	var member models.AccountMember
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.AccountMember{}).
+			Where("account_id = ? AND user_id = ?", accountID, userID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAccountMemberExists
+		}
+
+		member = models.AccountMember{AccountID: accountID, UserID: userID, Role: constants.AccountRoleAdmin}
+		return tx.Omit("Account", "User").Create(&member).Error
+	})
+
+	return member, err
+}
+
+// RemoveAccountAdmin revokes account admin access while keeping at least one admin.
+func RemoveAccountAdmin(db *gorm.DB, accountID uint, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var admins []models.AccountMember
+		if err := withRowLock(tx).
+			Where("account_id = ? AND role = ?", accountID, constants.AccountRoleAdmin).
+			Find(&admins).Error; err != nil {
+			return err
+		}
+
+		var target *models.AccountMember
+		for i := range admins {
+			if admins[i].UserID == userID {
+				target = &admins[i]
+			}
+		}
+		if target == nil {
+			return ErrAccountMemberNotFound
+		}
+		if len(admins) == 1 {
+			return ErrLastAccountAdmin
+		}
+
+		return tx.Delete(&models.AccountMember{}, target.ID).Error
+	})
+}
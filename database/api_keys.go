@@ -0,0 +1,50 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+// APIKeyLastUsedInterval limits how often last_used_at is written for a busy key.
+const APIKeyLastUsedInterval = time.Minute
+
+var ErrAPIKeyInvalid = errors.New("API key is invalid, expired or revoked")
+
+// AuthenticateAPIKey returns the active API key hashing to keyHash together with the current
+// workspace membership of the member who created it. The key stops working when it is revoked,
+// expires, or its creator leaves the workspace.
+func AuthenticateAPIKey(db *gorm.DB, keyHash string) (models.APIKey, models.WorkspaceMember, error) {
+	var key models.APIKey
+	if err := db.Where("key_hash = ? AND revoked_at IS NULL", keyHash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return key, models.WorkspaceMember{}, ErrAPIKeyInvalid
+		}
+		return key, models.WorkspaceMember{}, err
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return key, models.WorkspaceMember{}, ErrAPIKeyInvalid
+	}
+
+	member, found, err := FindWorkspaceMember(db, key.UserID, key.WorkspaceID)
+	if err != nil {
+		return key, member, err
+	}
+	if !found {
+		return key, member, ErrAPIKeyInvalid
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= APIKeyLastUsedInterval {
+		if err := db.Model(&models.APIKey{}).Where("id = ?", key.ID).UpdateColumn("last_used_at", now).Error; err != nil {
+			return key, member, err
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, member, nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+// auditIgnoredFields are bookkeeping columns that never appear in audit diffs.
+var auditIgnoredFields = map[string]bool{
+	"id":           true,
+	"created_at":   true,
+	"updated_at":   true,
+	"deleted_at":   true,
+	"user_id":      true,
+	"workspace_id": true,
+}
+
+// AuditLogFilter narrows the workspace activity feed. Zero values do not filter.
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   uint
+	Action     string
+	UserID     uint
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	PageSize   int
+}
+
+// AuditSnapshot flattens an entity into its scalar JSON fields so two versions can be diffed.
+// Nested relations, lists and bookkeeping columns are dropped.
+func AuditSnapshot(entity interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for key, value := range fields {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			delete(fields, key)
+			continue
+		}
+		if auditIgnoredFields[key] {
+			delete(fields, key)
+		}
+	}
+	return fields, nil
+}
+
+// AuditDiff returns the fields whose JSON values differ between two snapshots.
+// A nil snapshot stands for an entity that does not exist yet or anymore.
+func AuditDiff(before map[string]interface{}, after map[string]interface{}) models.AuditChanges {
+	changes := models.AuditChanges{}
+	for key, beforeValue := range before {
+		afterValue, exists := after[key]
+		if !exists && beforeValue == nil {
+			continue
+		}
+		if !exists || !sameJSONValue(beforeValue, afterValue) {
+			changes[key] = models.AuditChange{Before: beforeValue, After: afterValue}
+		}
+	}
+	for key, afterValue := range after {
+		if _, exists := before[key]; !exists && afterValue != nil {
+			changes[key] = models.AuditChange{Before: nil, After: afterValue}
+		}
+	}
+	return changes
+}
+
+func sameJSONValue(left interface{}, right interface{}) bool {
+	leftData, leftErr := json.Marshal(left)
+	rightData, rightErr := json.Marshal(right)
+	return leftErr == nil && rightErr == nil && bytes.Equal(leftData, rightData)
+}
+
+// RecordAudit stores an audit entry.
+func RecordAudit(db *gorm.DB, entry *models.AuditLog) error {
+	return db.Create(entry).Error
+}
+
+// ListAuditLogs returns one page of workspace audit entries, newest first, and the total match count.
+func ListAuditLogs(db *gorm.DB, workspaceID uint, filter AuditLogFilter) ([]models.AuditLog, int64, error) {
+	query := db.Model(&models.AuditLog{}).Where("workspace_id = ?", workspaceID)
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.AuditLog
+	if err := query.
+		Preload("User").
+		Order("created_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+// RefreshTokenTTL is how long a refresh token can be exchanged after it was issued.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// SessionLastSeenInterval limits how often last_seen_at is written for a busy session.
+const SessionLastSeenInterval = time.Minute
+
+// Reasons stored on revoked sessions.
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedTokenReuse     = "refresh_token_reuse"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedByUser         = "user_revoked"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used")
+	ErrAuthSessionNotFound = errors.New("session not found")
+)
+
+// CreateAuthSession stores session, which carries the user and device, with a first refresh token
+// hashing to refreshTokenHash. It returns the stored session and the refresh token expiry.
+func CreateAuthSession(db *gorm.DB, session models.AuthSession, refreshTokenHash string) (models.AuthSession, time.Time, error) {
+	now := time.Now()
+	session.LastSeenAt = &now
+	var expiresAt time.Time
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		var err error
+		expiresAt, err = storeRefreshToken(tx, session.ID, refreshTokenHash)
+		return err
+	})
+
+	return session, expiresAt, err
+}
+
+// RotateRefreshToken marks the refresh token hashing to tokenHash as used and stores newTokenHash
+// in the same session. Presenting a token that was already exchanged revokes the whole session,
+// because either the legitimate client or an attacker holds a stolen copy.
+func RotateRefreshToken(db *gorm.DB, tokenHash string, newTokenHash string) (models.AuthSession, time.Time, error) {
+	var session models.AuthSession
+	var expiresAt time.Time
+	reused := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var token models.RefreshToken
+		if err := withRowLock(tx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+		if err := withRowLock(tx).First(&session, token.SessionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+		if session.RevokedAt != nil {
+			return ErrRefreshTokenInvalid
+		}
+
+		now := time.Now()
+		if token.UsedAt != nil {
+			reused = true
+			return revokeAuthSession(tx, session.ID, SessionRevokedTokenReuse, now)
+		}
+		if !token.ExpiresAt.After(now) {
+			return ErrRefreshTokenInvalid
+		}
+
+		if err := tx.Model(&models.RefreshToken{}).Where("id = ?", token.ID).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AuthSession{}).Where("id = ?", session.ID).Update("updated_at", now).Error; err != nil {
+			return err
+		}
+
+		var err error
+		expiresAt, err = storeRefreshToken(tx, session.ID, newTokenHash)
+		return err
+	})
+	if err == nil && reused {
+		err = ErrRefreshTokenReused
+	}
+
+	return session, expiresAt, err
+}
+
+// RevokeSessionByRefreshToken revokes the session of the refresh token hashing to tokenHash.
+// Unknown tokens are ignored so logout is idempotent.
+func RevokeSessionByRefreshToken(db *gorm.DB, tokenHash string, reason string) error {
+	var token models.RefreshToken
+	err := db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return revokeAuthSession(db, token.SessionID, reason, time.Now())
+}
+
+// TouchAuthSession reports whether a session exists and has not been revoked. For active sessions
+// it records the request as last seen from ipAddress, at most once per SessionLastSeenInterval.
+func TouchAuthSession(db *gorm.DB, sessionID uint, ipAddress string, now time.Time) (bool, error) {
+	var session models.AuthSession
+	err := db.Select("id", "revoked_at", "last_seen_at").First(&session, sessionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil || session.RevokedAt != nil {
+		return false, err
+	}
+
+	if session.LastSeenAt == nil || now.Sub(*session.LastSeenAt) >= SessionLastSeenInterval {
+		err = db.Model(&models.AuthSession{}).Where("id = ?", session.ID).
+			UpdateColumns(map[string]interface{}{"last_seen_at": now, "ip_address": ipAddress}).Error
+	}
+	return true, err
+}
+
+// ListActiveAuthSessions returns the sessions of userID that are not revoked and can still be
+// refreshed, most recently seen first.
+func ListActiveAuthSessions(db *gorm.DB, userID uint) ([]models.AuthSession, error) {
+	var sessions []models.AuthSession
+	err := db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Where("EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.session_id = auth_sessions.id AND refresh_tokens.used_at IS NULL AND refresh_tokens.expires_at > ?)", time.Now()).
+		Order("COALESCE(last_seen_at, created_at) DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeUserAuthSession revokes the active session sessionID of userID.
+func RevokeUserAuthSession(db *gorm.DB, userID uint, sessionID uint, reason string) error {
+	result := db.Model(&models.AuthSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAuthSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherAuthSessions revokes every active session of userID except keepSessionID
+// and returns how many were revoked.
+func RevokeOtherAuthSessions(db *gorm.DB, userID uint, keepSessionID uint, reason string) (int64, error) {
+	result := db.Model(&models.AuthSession{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+// UserTokenGeneration returns the token generation access tokens of userID must carry.
+func UserTokenGeneration(db *gorm.DB, userID uint) (uint, error) {
+	var user models.User
+	if err := db.Select("id", "token_generation").First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	return user.TokenGeneration, nil
+}
+
+// InvalidateUserTokens bumps the token generation of userID, so all access tokens issued so far
+// are rejected, and revokes every session except keepSessionID (0 keeps none).
+// It returns the new generation.
+func InvalidateUserTokens(db *gorm.DB, userID uint, keepSessionID uint, reason string) (uint, error) {
+	if err := db.Model(&models.User{}).
+		Where("id = ?", userID).
+		UpdateColumn("token_generation", gorm.Expr("token_generation + 1")).Error; err != nil {
+		return 0, err
+	}
+
+	if err := db.Model(&models.AuthSession{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error; err != nil {
+		return 0, err
+	}
+
+	return UserTokenGeneration(db, userID)
+}
+
+func revokeAuthSession(db *gorm.DB, sessionID uint, reason string, now time.Time) error {
+	return db.Model(&models.AuthSession{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason}).Error
+}
+
+func storeRefreshToken(tx *gorm.DB, sessionID uint, tokenHash string) (time.Time, error) {
+	record := models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return time.Time{}, err
+	}
+	return record.ExpiresAt, nil
+}
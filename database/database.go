@@ -42,12 +42,19 @@ func ConnectDatabase() {
 	// Auto-migrate all models
 	err = DB.AutoMigrate(
 		&models.User{},
+		&models.Account{},
+		&models.AccountMember{},
 		&models.Workspace{},
 		&models.WorkspaceMember{},
+		&models.WorkspaceInvitation{},
+		&models.WorkspaceSettings{},
 		&models.Recipe{},
 		&models.Ingredient{},
 		&models.WorkspaceIngredient{},
 		&models.RecipeIngredient{},
+		&models.RecipeSubRecipe{},
+		&models.RecipeStep{},
+		&models.RecipeRevision{},
 		&models.Price{},
 		&models.CookingSession{},
 		&models.CookingSessionIngredient{},
@@ -57,6 +64,15 @@ func ConnectDatabase() {
 		&models.ProductOption{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.AuditLog{},
+		&models.AuthSession{},
+		&models.RefreshToken{},
+		&models.APIKey{},
+		&models.LoginThrottle{},
+		&models.SecurityEvent{},
+		&models.OneTimeCode{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
 	)
 
 	if err != nil {
@@ -82,6 +98,10 @@ func ConnectDatabase() {
 		log.Fatal("Workspace ingredient backfill error: ", err)
 	}
 
+	if err := BackfillLegacyStrictIngredientSettings(DB); err != nil {
+		log.Fatal("Workspace settings backfill error: ", err)
+	}
+
 	backfillOrderDates()
 
 	log.Println("Migrations completed successfully.")
@@ -145,10 +165,21 @@ func createIndexes() {
 
 	// Workspaces: frequently resolved by personal owner and membership
 	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_workspaces_personal_user_id_active_unique ON workspaces(personal_user_id) WHERE personal_user_id IS NOT NULL AND deleted_at IS NULL`)
+	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_workspaces_slug_active_unique ON workspaces(slug) WHERE deleted_at IS NULL`)
 	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id)`)
 	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_workspace_members_workspace_id ON workspace_members(workspace_id)`)
 	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_members_workspace_user ON workspace_members(workspace_id, user_id) WHERE deleted_at IS NULL`)
 
+	// Accounts: workspaces grouped per account, admins resolved per user
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_workspaces_account_id ON workspaces(account_id)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_account_members_user_id ON account_members(user_id)`)
+	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_account_members_account_user ON account_members(account_id, user_id) WHERE deleted_at IS NULL`)
+
+	// Workspace Invitations: listed per workspace and invitee, redeemed by token hash
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_workspace_invitations_workspace_status ON workspace_invitations(workspace_id, status)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_workspace_invitations_invitee_status ON workspace_invitations(invitee_user_id, status)`)
+	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_invitations_token_hash_unique ON workspace_invitations(token_hash) WHERE token_hash IS NOT NULL`)
+
 	// Prices: frequently filtered by workspace and ingredient history
 	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_prices_workspace_id ON prices(workspace_id)`)
 	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_prices_workspace_ingredient_date ON prices(workspace_id, ingredient_id, date DESC)`)
@@ -166,6 +197,39 @@ func createIndexes() {
 	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_recipe_ingredients_recipe_id ON recipe_ingredients(recipe_id)`)
 	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_recipe_ingredients_ingredient_id ON recipe_ingredients(ingredient_id)`)
 
+	// Recipe Sub-recipes: lines listed per recipe, where-used looked up by sub-recipe
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_recipe_sub_recipes_recipe_id ON recipe_sub_recipes(recipe_id)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_recipe_sub_recipes_sub_recipe_id ON recipe_sub_recipes(sub_recipe_id)`)
+
+	// Recipe Steps: listed per recipe in order
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_recipe_steps_recipe_position ON recipe_steps(recipe_id, position)`)
+
+	// Recipe Revisions: numbered per recipe, listed newest first
+	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_recipe_revisions_recipe_number_unique ON recipe_revisions(recipe_id, number)`)
+
+	// Audit Logs: activity feed listed per workspace, newest first, optionally per entity
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_logs_workspace_created_at ON audit_logs(workspace_id, created_at DESC)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_logs_workspace_entity ON audit_logs(workspace_id, entity_type, entity_id)`)
+
+	// Auth sessions: refresh tokens looked up by hash, sessions listed per user
+	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash_unique ON refresh_tokens(token_hash)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_id ON auth_sessions(user_id)`)
+
+	// API keys: looked up by hash on every request, listed per workspace
+	DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash_unique ON api_keys(key_hash)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_api_keys_workspace_id ON api_keys(workspace_id)`)
+
+	// Security events: sign-in history listed per user, newest first
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_security_events_user_created_at ON security_events(user_id, created_at DESC)`)
+
+	// One-time codes: the current code is looked up per user and purpose, login challenges by hash
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_one_time_codes_user_purpose ON one_time_codes(user_id, purpose)`)
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_one_time_codes_code_hash ON one_time_codes(code_hash)`)
+
+	// Recovery codes: checked per user
+	DB.Exec(`CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes(user_id)`)
+
 	log.Println("Indexes created successfully.")
 }
 
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mobile-backend-go/models"
+)
+
+// Login lockout policy. After LoginFailuresBeforeLockout consecutive failures the username is
+// locked for LoginLockoutBase, doubling with every further failure up to LoginLockoutMax.
+// Failures older than LoginFailureResetAfter are forgotten.
+const (
+	LoginFailuresBeforeLockout = 5
+	LoginLockoutBase           = time.Minute
+	LoginLockoutMax            = time.Hour
+	LoginFailureResetAfter     = 24 * time.Hour
+)
+
+// LoginLockedUntil returns when the lockout of username ends, or nil when it is not locked.
+func LoginLockedUntil(db *gorm.DB, username string, now time.Time) (*time.Time, error) {
+	var throttle models.LoginThrottle
+	err := db.Where("username = ?", username).First(&throttle).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if throttle.LockedUntil == nil || !throttle.LockedUntil.After(now) {
+		return nil, nil
+	}
+	return throttle.LockedUntil, nil
+}
+
+// RecordFailedLogin counts a failed login for username. It returns the new lockout end
+// when this failure locks the username, and nil otherwise. Unknown usernames are tracked too, so
+// that lockouts do not reveal which accounts exist; every failure removes the rows whose failures
+// are already forgotten, which keeps the table to the usernames that failed recently.
+func RecordFailedLogin(db *gorm.DB, username string, now time.Time) (*time.Time, error) {
+	var lockedUntil *time.Time
+
+	// Lockouts end long before failures are forgotten, so these rows lock nothing
+	if err := db.Where("last_failed_at < ?", now.Add(-LoginFailureResetAfter)).Delete(&models.LoginThrottle{}).Error; err != nil {
+		return nil, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LoginThrottle{Username: username, LastFailedAt: now}).Error; err != nil {
+			return err
+		}
+
+		var throttle models.LoginThrottle
+		if err := withRowLock(tx).Where("username = ?", username).First(&throttle).Error; err != nil {
+			return err
+		}
+
+		if now.Sub(throttle.LastFailedAt) > LoginFailureResetAfter {
+			throttle.FailedCount = 0
+		}
+		throttle.FailedCount++
+		throttle.LastFailedAt = now
+		if throttle.FailedCount >= LoginFailuresBeforeLockout {
+			until := now.Add(LoginLockoutDuration(throttle.FailedCount))
+			throttle.LockedUntil = &until
+			lockedUntil = &until
+		}
+
+		return tx.Model(&models.LoginThrottle{}).Where("id = ?", throttle.ID).Updates(map[string]interface{}{
+			"failed_count":   throttle.FailedCount,
+			"last_failed_at": throttle.LastFailedAt,
+			"locked_until":   throttle.LockedUntil,
+		}).Error
+	})
+
+	return lockedUntil, err
+}
+
+// ResetFailedLogins forgets the failed logins of username after a successful sign-in.
+func ResetFailedLogins(db *gorm.DB, username string) error {
+	return db.Where("username = ?", username).Delete(&models.LoginThrottle{}).Error
+}
+
+// LoginLockoutDuration returns how long failedCount consecutive failures lock a username.
+func LoginLockoutDuration(failedCount int) time.Duration {
+	if failedCount < LoginFailuresBeforeLockout {
+		return 0
+	}
+	duration := LoginLockoutBase
+	for i := LoginFailuresBeforeLockout; i < failedCount; i++ {
+		duration *= 2
+		if duration >= LoginLockoutMax {
+			return LoginLockoutMax
+		}
+	}
+	return duration
+}
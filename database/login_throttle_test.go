@@ -0,0 +1,25 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLockoutDurationDoublesUpToMax(t *testing.T) {
+	tests := []struct {
+		failedCount int
+		want        time.Duration
+	}{
+		{LoginFailuresBeforeLockout - 1, 0},
+		{LoginFailuresBeforeLockout, LoginLockoutBase},
+		{LoginFailuresBeforeLockout + 1, 2 * LoginLockoutBase},
+		{LoginFailuresBeforeLockout + 3, 8 * LoginLockoutBase},
+		{LoginFailuresBeforeLockout + 20, LoginLockoutMax},
+	}
+
+	for _, tt := range tests {
+		if got := LoginLockoutDuration(tt.failedCount); got != tt.want {
+			t.Fatalf("LoginLockoutDuration(%d) = %v, want %v", tt.failedCount, got, tt.want)
+		}
+	}
+}
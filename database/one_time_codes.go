@@ -0,0 +1,78 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+// One-time code policy.
+const (
+	PasswordResetCodeTTL     = 15 * time.Minute
+	EmailVerificationCodeTTL = 24 * time.Hour
+	OneTimeCodeMaxAttempts   = 5
+)
+
+var ErrOneTimeCodeInvalid = errors.New("code is invalid or expired")
+
+// IssueOneTimeCode stores a code for userID and purpose sent to email, replacing unused earlier codes.
+func IssueOneTimeCode(db *gorm.DB, userID uint, purpose string, email string, codeHash string, ttl time.Duration) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.OneTimeCode{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		code := models.OneTimeCode{
+			UserID:    userID,
+			Purpose:   purpose,
+			Email:     email,
+			CodeHash:  codeHash,
+			ExpiresAt: now.Add(ttl),
+		}
+		return tx.Create(&code).Error
+	})
+}
+
+// ConsumeOneTimeCode checks codeHash against the current code of userID and purpose and marks it used.
+// A wrong guess counts as an attempt; after OneTimeCodeMaxAttempts the code is burnt.
+func ConsumeOneTimeCode(db *gorm.DB, userID uint, purpose string, codeHash string) (models.OneTimeCode, error) {
+	var code models.OneTimeCode
+	mismatch := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := withRowLock(tx).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", userID, purpose, now).
+			Order("id DESC").
+			First(&code).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOneTimeCodeInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if code.CodeHash == codeHash {
+			updates["used_at"] = now
+		} else {
+			mismatch = true
+			updates["attempts"] = code.Attempts + 1
+			if code.Attempts+1 >= OneTimeCodeMaxAttempts {
+				updates["used_at"] = now
+			}
+		}
+		return tx.Model(&models.OneTimeCode{}).Where("id = ?", code.ID).Updates(updates).Error
+	})
+	if err == nil && mismatch {
+		err = ErrOneTimeCodeInvalid
+	}
+
+	return code, err
+}
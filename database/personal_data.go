@@ -0,0 +1,337 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/models"
+)
+
+const deletedUsernamePrefix = "deleted-user-"
+
+// workspaceSuccessorRoles is the order in which members inherit a workspace from its last owner.
+var workspaceSuccessorRoles = []string{
+	constants.WorkspaceRoleManager,
+	constants.WorkspaceRoleOperator,
+	constants.WorkspaceRoleViewer,
+}
+
+// ExportPersonalData collects the profile, memberships, sessions and security events of userID
+// together with the bundle of their personal workspace.
+func ExportPersonalData(db *gorm.DB, userID uint) (models.PersonalDataExport, error) {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return models.PersonalDataExport{}, err
+	}
+
+	export := models.PersonalDataExport{
+		FormatVersion: models.PersonalDataExportFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Profile: models.PersonalDataProfile{
+			ID:              user.ID,
+			Username:        user.Username,
+			Email:           user.Email,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			CreatedAt:       user.CreatedAt,
+		},
+		WorkspaceMemberships: []models.PersonalDataWorkspaceMembership{},
+		AccountMemberships:   []models.PersonalDataAccountMembership{},
+		Sessions:             []models.AuthSession{},
+		SecurityEvents:       []models.SecurityEvent{},
+	}
+
+	var err error
+	if export.TwoFactorEnabled, err = UserHasTwoFactor(db, userID); err != nil {
+		return export, err
+	}
+
+	var members []models.WorkspaceMember
+	if err := db.Preload("Workspace").Where("user_id = ?", userID).Order("id ASC").Find(&members).Error; err != nil {
+		return export, err
+	}
+	personalWorkspaceID := uint(0)
+	for _, member := range members {
+		if member.Workspace.ID == 0 {
+			continue
+		}
+		personal := member.Workspace.PersonalUserID != nil && *member.Workspace.PersonalUserID == userID
+		if personal {
+			personalWorkspaceID = member.WorkspaceID
+		}
+		export.WorkspaceMemberships = append(export.WorkspaceMemberships, models.PersonalDataWorkspaceMembership{
+			WorkspaceID:   member.WorkspaceID,
+			WorkspaceName: member.Workspace.Name,
+			WorkspaceSlug: member.Workspace.Slug,
+			Personal:      personal,
+			Role:          member.Role,
+			JoinedAt:      member.CreatedAt,
+		})
+	}
+
+	var accountMembers []models.AccountMember
+	if err := db.Preload("Account").Where("user_id = ?", userID).Order("id ASC").Find(&accountMembers).Error; err != nil {
+		return export, err
+	}
+	for _, member := range accountMembers {
+		if member.Account.ID == 0 {
+			continue
+		}
+		export.AccountMemberships = append(export.AccountMemberships, models.PersonalDataAccountMembership{
+			AccountID:   member.AccountID,
+			AccountName: member.Account.Name,
+			Role:        member.Role,
+			JoinedAt:    member.CreatedAt,
+		})
+	}
+
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&export.Sessions).Error; err != nil {
+		return export, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").Find(&export.SecurityEvents).Error; err != nil {
+		return export, err
+	}
+
+	if personalWorkspaceID != 0 {
+		bundle, err := ExportWorkspaceBundle(db, personalWorkspaceID)
+		if err != nil {
+			return export, err
+		}
+		export.PersonalWorkspace = &bundle
+	}
+
+	return export, nil
+}
+
+// DeleteUserAccount removes userID from every workspace and account and erases their personal data.
+// Shared workspaces where the user is the last owner pass to the longest-standing member with the
+// highest role, or are closed when nobody else is left; closed workspaces, including the personal
+// one, get their clients anonymized and their audit log deleted. Credentials, sessions and security events are deleted, and the user row is
+// anonymized and soft-deleted so records created by the user keep a valid reference.
+func DeleteUserAccount(db *gorm.DB, userID uint, unusablePasswordHash string) (models.AccountDeletionReport, error) {
+	report := models.AccountDeletionReport{
+		TransferredWorkspaces: []models.AccountDeletionWorkspace{},
+		ClosedWorkspaces:      []models.AccountDeletionWorkspace{},
+		LeftWorkspaces:        []models.AccountDeletionWorkspace{},
+		ClosedAccountIDs:      []uint{},
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := withRowLock(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		var members []models.WorkspaceMember
+		if err := tx.Where("user_id = ?", userID).Order("id ASC").Find(&members).Error; err != nil {
+			return err
+		}
+		for _, member := range members {
+			if err := leaveWorkspaceForDeletion(tx, member, &report); err != nil {
+				return err
+			}
+		}
+
+		if err := leaveAccountsForDeletion(tx, userID, &report); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.WorkspaceInvitation{}).
+			Where("invitee_user_id = ? AND status = ?", userID, constants.WorkspaceInvitationPending).
+			Update("status", constants.WorkspaceInvitationRevoked).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.APIKey{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		if err := deleteUserCredentials(tx, user); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":          fmt.Sprintf("%s%d", deletedUsernamePrefix, userID),
+			"password":          unusablePasswordHash,
+			"email":             nil,
+			"email_verified_at": nil,
+			"token_generation":  gorm.Expr("token_generation + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, userID).Error
+	})
+
+	return report, err
+}
+
+func leaveWorkspaceForDeletion(tx *gorm.DB, member models.WorkspaceMember, report *models.AccountDeletionReport) error {
+	var workspace models.Workspace
+	err := withRowLock(tx).First(&workspace, member.WorkspaceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The workspace is already archived; only the membership remains
+		return tx.Delete(&models.WorkspaceMember{}, member.ID).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	summary := models.AccountDeletionWorkspace{WorkspaceID: workspace.ID, Name: workspace.Name}
+	switch {
+	case workspace.PersonalUserID != nil && *workspace.PersonalUserID == member.UserID:
+		if err := closeWorkspaceForDeletion(tx, workspace.ID); err != nil {
+			return err
+		}
+		report.ClosedWorkspaces = append(report.ClosedWorkspaces, summary)
+
+	case member.Role == constants.WorkspaceRoleOwner:
+		err := requireAnotherOwner(tx, workspace.ID, member.UserID)
+		if err == nil {
+			report.LeftWorkspaces = append(report.LeftWorkspaces, summary)
+			break
+		}
+		if !errors.Is(err, ErrLastWorkspaceOwner) {
+			return err
+		}
+
+		successor, found, err := findWorkspaceSuccessor(tx, workspace.ID, member.UserID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if err := closeWorkspaceForDeletion(tx, workspace.ID); err != nil {
+				return err
+			}
+			report.ClosedWorkspaces = append(report.ClosedWorkspaces, summary)
+			break
+		}
+		if err := tx.Model(&models.WorkspaceMember{}).Where("id = ?", successor.ID).Update("role", constants.WorkspaceRoleOwner).Error; err != nil {
+			return err
+		}
+		summary.NewOwnerUserID = &successor.UserID
+		report.TransferredWorkspaces = append(report.TransferredWorkspaces, summary)
+
+	default:
+		report.LeftWorkspaces = append(report.LeftWorkspaces, summary)
+	}
+
+	return tx.Delete(&models.WorkspaceMember{}, member.ID).Error
+}
+
+// findWorkspaceSuccessor returns the member who inherits a workspace from its last owner.
+func findWorkspaceSuccessor(tx *gorm.DB, workspaceID uint, userID uint) (models.WorkspaceMember, bool, error) {
+	for _, role := range workspaceSuccessorRoles {
+		var member models.WorkspaceMember
+		err := withRowLock(tx).
+			Where("workspace_id = ? AND user_id <> ? AND role = ?", workspaceID, userID, role).
+			Order("created_at ASC, id ASC").
+			First(&member).Error
+		if err == nil {
+			return member, true, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return member, false, err
+		}
+	}
+	return models.WorkspaceMember{}, false, nil
+}
+
+// closeWorkspaceForDeletion closes a workspace nobody is left in after an account deletion. Its
+// clients are anonymized and its audit log, which keeps their earlier values, is deleted.
+func closeWorkspaceForDeletion(tx *gorm.DB, workspaceID uint) error {
+	if err := anonymizeWorkspaceClients(tx, workspaceID); err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.AuditLog{}).Error; err != nil {
+		return err
+	}
+	return closeWorkspace(tx, workspaceID)
+}
+
+// closeWorkspace archives a workspace and stops its pending invitations and API keys.
+func closeWorkspace(tx *gorm.DB, workspaceID uint) error {
+	if err := tx.Model(&models.WorkspaceInvitation{}).
+		Where("workspace_id = ? AND status = ?", workspaceID, constants.WorkspaceInvitationPending).
+		Update("status", constants.WorkspaceInvitationRevoked).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.APIKey{}).
+		Where("workspace_id = ? AND revoked_at IS NULL", workspaceID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Workspace{}, workspaceID).Error
+}
+
+// anonymizeWorkspaceClients erases the contact details of the clients of a workspace, including
+// clients deleted before. Orders keep pointing at the anonymized rows.
+func anonymizeWorkspaceClients(tx *gorm.DB, workspaceID uint) error {
+	if err := tx.Unscoped().Model(&models.Client{}).Where("workspace_id = ?", workspaceID).Updates(map[string]interface{}{
+		"name":      "Deleted",
+		"surname":   "client",
+		"telegram":  "",
+		"instagram": "",
+		"phone":     "",
+		"address":   "",
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Where("workspace_id = ?", workspaceID).Delete(&models.Client{}).Error
+}
+
+// leaveAccountsForDeletion removes userID from every account. Accounts left without an admin are
+// closed and their workspaces detached.
+func leaveAccountsForDeletion(tx *gorm.DB, userID uint, report *models.AccountDeletionReport) error {
+	var members []models.AccountMember
+	if err := tx.Where("user_id = ?", userID).Order("id ASC").Find(&members).Error; err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		var otherAdmins int64
+		if err := tx.Model(&models.AccountMember{}).
+			Where("account_id = ? AND role = ? AND user_id <> ?", member.AccountID, constants.AccountRoleAdmin, userID).
+			Count(&otherAdmins).Error; err != nil {
+			return err
+		}
+		if otherAdmins == 0 {
+			// Workspaces closed earlier in the deletion are detached too
+			if err := tx.Unscoped().Model(&models.Workspace{}).Where("account_id = ?", member.AccountID).Update("account_id", nil).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.Account{}, member.AccountID).Error; err != nil {
+				return err
+			}
+			report.ClosedAccountIDs = append(report.ClosedAccountIDs, member.AccountID)
+		}
+		if err := tx.Delete(&models.AccountMember{}, member.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUserCredentials hard-deletes sessions, second factors, codes, the lockout counter and
+// security events of user.
+func deleteUserCredentials(tx *gorm.DB, user models.User) error {
+	sessionIDs := tx.Model(&models.AuthSession{}).Select("id").Where("user_id = ?", user.ID)
+	if err := tx.Where("session_id IN (?)", sessionIDs).Delete(&models.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	for _, model := range []interface{}{
+		&models.AuthSession{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
+		&models.OneTimeCode{},
+		&models.SecurityEvent{},
+	} {
+		if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("username = ?", user.Username).Delete(&models.LoginThrottle{}).Error
+}
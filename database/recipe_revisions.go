@@ -0,0 +1,351 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/models"
+)
+
+var ErrRecipeRevisionNotFound = errors.New("recipe revision not found")
+
+// CreateRecipe stores recipe together with its first revision.
+func CreateRecipe(db *gorm.DB, recipe *models.Recipe) (models.RecipeRevision, error) {
+	var revision models.RecipeRevision
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(recipe).Error; err != nil {
+			return err
+		}
+		var err error
+		revision, err = reviseRecipe(tx, recipe.ID, recipe.UserID, constants.RecipeRevisionCreated, nil, nil)
+		return err
+	})
+	return revision, err
+}
+
+// ReviseRecipe applies change to recipeID in a transaction and records the resulting recipe as a
+// new revision made by userID. Recipes without any revision first get a baseline revision of their
+// state before the change. When the change leaves the recipe as it was, no revision is recorded and
+// the latest one is returned.
+func ReviseRecipe(db *gorm.DB, recipeID uint, userID uint, action string, change func(tx *gorm.DB) error) (models.RecipeRevision, error) {
+	return reviseRecipe(db, recipeID, userID, action, nil, change)
+}
+
+// RestoreRecipeRevision sets the name, yield, ingredient and sub-recipe lines and method steps of
+// recipeID back to revision number and records the result as a new revision. ErrSubRecipeCycle is returned when a
+// sub-recipe of the revision has come to use recipeID since.
+func RestoreRecipeRevision(db *gorm.DB, recipeID uint, userID uint, number uint) (models.RecipeRevision, error) {
+	target, err := FindRecipeRevision(db, recipeID, number)
+	if err != nil {
+		return models.RecipeRevision{}, err
+	}
+
+	return reviseRecipe(db, recipeID, userID, constants.RecipeRevisionRestored, &number, func(tx *gorm.DB) error {
+		if err := UpdateRecipeDetails(tx, recipeID, target.Name, target.RecipeYield); err != nil {
+			return err
+		}
+		if err := tx.Where("recipe_id = ?", recipeID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("recipe_id = ?", recipeID).Delete(&models.RecipeSubRecipe{}).Error; err != nil {
+			return err
+		}
+		if err := ReplaceRecipeSteps(tx, recipeID, target.Steps); err != nil {
+			return err
+		}
+		for _, line := range target.Ingredients {
+			if line.SubRecipeID != 0 {
+				if err := CheckSubRecipeCycle(tx, recipeID, line.SubRecipeID); err != nil {
+					return err
+				}
+				restored := models.RecipeSubRecipe{
+					RecipeID:    recipeID,
+					SubRecipeID: line.SubRecipeID,
+					Quantity:    line.Quantity,
+					Unit:        line.Unit,
+				}
+				if err := tx.Create(&restored).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			restored := models.RecipeIngredient{
+				RecipeID:     recipeID,
+				IngredientID: line.IngredientID,
+				Quantity:     line.Quantity,
+				Unit:         line.Unit,
+			}
+			if err := tx.Create(&restored).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateRecipeDetails sets the name and yield of recipeID. Empty yield fields are written too.
+func UpdateRecipeDetails(db *gorm.DB, recipeID uint, name string, yield models.RecipeYield) error {
+	return db.Model(&models.Recipe{}).Where("id = ?", recipeID).
+		Select("name", "yield_quantity", "yield_unit", "process_loss", "piece_size", "piece_unit").
+		Updates(models.Recipe{Name: name, RecipeYield: yield}).Error
+}
+
+// ListRecipeRevisions returns the revisions of recipeID, newest first.
+func ListRecipeRevisions(db *gorm.DB, recipeID uint) ([]models.RecipeRevision, error) {
+	var revisions []models.RecipeRevision
+	err := db.Where("recipe_id = ?", recipeID).Order("number DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// FindRecipeRevision returns revision number of recipeID.
+func FindRecipeRevision(db *gorm.DB, recipeID uint, number uint) (models.RecipeRevision, error) {
+	var revision models.RecipeRevision
+	err := db.Where("recipe_id = ? AND number = ?", recipeID, number).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return revision, ErrRecipeRevisionNotFound
+	}
+	return revision, err
+}
+
+// DiffRecipeRevisions compares two revisions of a recipe. A zero from revision stands for an empty
+// recipe. Lines are matched by ingredient; repeated lines of one ingredient are matched in order.
+func DiffRecipeRevisions(from models.RecipeRevision, to models.RecipeRevision) models.RecipeRevisionDiff {
+	diff := models.RecipeRevisionDiff{
+		RecipeID:           to.RecipeID,
+		From:               from.Number,
+		To:                 to.Number,
+		AddedIngredients:   []models.RecipeRevisionIngredient{},
+		RemovedIngredients: []models.RecipeRevisionIngredient{},
+		ChangedIngredients: []models.RecipeRevisionIngredientChange{},
+		StepChanges:        diffRecipeRevisionSteps(from.Steps, to.Steps),
+	}
+	if from.Name != to.Name {
+		diff.Name = &models.AuditChange{Before: from.Name, After: to.Name}
+	}
+	fromYield, _ := AuditSnapshot(from.RecipeYield)
+	toYield, _ := AuditSnapshot(to.RecipeYield)
+	if changes := AuditDiff(fromYield, toYield); len(changes) > 0 {
+		diff.YieldChanges = changes
+	}
+
+	fromLines := keyRecipeRevisionLines(from.Ingredients)
+	toLines := keyRecipeRevisionLines(to.Ingredients)
+
+	for _, key := range orderedRecipeRevisionKeys(to.Ingredients) {
+		after := toLines[key]
+		before, existed := fromLines[key]
+		if !existed {
+			diff.AddedIngredients = append(diff.AddedIngredients, after)
+			continue
+		}
+		changes := models.AuditChanges{}
+		if before.Quantity != after.Quantity {
+			changes["quantity"] = models.AuditChange{Before: before.Quantity, After: after.Quantity}
+		}
+		if before.Unit != after.Unit {
+			changes["unit"] = models.AuditChange{Before: before.Unit, After: after.Unit}
+		}
+		if len(changes) > 0 {
+			diff.ChangedIngredients = append(diff.ChangedIngredients, models.RecipeRevisionIngredientChange{
+				IngredientID:   after.IngredientID,
+				SubRecipeID:    after.SubRecipeID,
+				IngredientName: after.IngredientName,
+				Changes:        changes,
+			})
+		}
+	}
+	for _, key := range orderedRecipeRevisionKeys(from.Ingredients) {
+		if _, kept := toLines[key]; !kept {
+			diff.RemovedIngredients = append(diff.RemovedIngredients, fromLines[key])
+		}
+	}
+	return diff
+}
+
+func reviseRecipe(db *gorm.DB, recipeID uint, userID uint, action string, restoredFrom *uint, change func(tx *gorm.DB) error) (models.RecipeRevision, error) {
+	var revision models.RecipeRevision
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var recipe models.Recipe
+		if err := withRowLock(tx).First(&recipe, recipeID).Error; err != nil {
+			return err
+		}
+
+		var previous models.RecipeRevision
+		found := true
+		err := tx.Where("recipe_id = ?", recipeID).Order("number DESC").First(&previous).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			found = false
+		} else if err != nil {
+			return err
+		}
+		if !found && action != constants.RecipeRevisionCreated {
+			previous, err = snapshotRecipe(tx, recipe)
+			if err != nil {
+				return err
+			}
+			previous.Number = 1
+			previous.Action = constants.RecipeRevisionBaseline
+			previous.UserID = recipe.UserID
+			if err := tx.Create(&previous).Error; err != nil {
+				return err
+			}
+			found = true
+		}
+
+		if change != nil {
+			if err := change(tx); err != nil {
+				return err
+			}
+			if err := tx.First(&recipe, recipeID).Error; err != nil {
+				return err
+			}
+		}
+
+		revision, err = snapshotRecipe(tx, recipe)
+		if err != nil {
+			return err
+		}
+		if found && sameRecipeRevision(previous, revision) {
+			revision = previous
+			return nil
+		}
+		revision.Number = previous.Number + 1
+		revision.Action = action
+		revision.UserID = userID
+		revision.RestoredFrom = restoredFrom
+		return tx.Create(&revision).Error
+	})
+
+	return revision, err
+}
+
+// snapshotRecipe captures the current name, yield, ingredient and sub-recipe lines and steps of recipe.
+func snapshotRecipe(tx *gorm.DB, recipe models.Recipe) (models.RecipeRevision, error) {
+	var lines []models.RecipeIngredient
+	if err := tx.Preload("Ingredient").Where("recipe_id = ?", recipe.ID).Order("id ASC").Find(&lines).Error; err != nil {
+		return models.RecipeRevision{}, err
+	}
+
+	var subRecipeLines []models.RecipeSubRecipe
+	if err := tx.Preload("SubRecipe", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("recipe_id = ?", recipe.ID).Order("id ASC").Find(&subRecipeLines).Error; err != nil {
+		return models.RecipeRevision{}, err
+	}
+
+	recipeSteps, err := ListRecipeSteps(tx, recipe.ID)
+	if err != nil {
+		return models.RecipeRevision{}, err
+	}
+	steps := make(models.RecipeRevisionSteps, 0, len(recipeSteps))
+	for _, step := range recipeSteps {
+		if step.IngredientIDs == nil {
+			step.IngredientIDs = models.RecipeStepIngredientIDs{}
+		}
+		steps = append(steps, step.RecipeStepDetails)
+	}
+
+	ingredients := make(models.RecipeRevisionIngredients, 0, len(lines)+len(subRecipeLines))
+	for _, line := range lines {
+		ingredients = append(ingredients, models.RecipeRevisionIngredient{
+			IngredientID:   line.IngredientID,
+			IngredientName: line.Ingredient.Name,
+			Quantity:       line.Quantity,
+			Unit:           line.Unit,
+		})
+	}
+	for _, line := range subRecipeLines {
+		revisionLine := models.RecipeRevisionIngredient{
+			SubRecipeID: line.SubRecipeID,
+			Quantity:    line.Quantity,
+			Unit:        line.Unit,
+		}
+		if line.SubRecipe != nil {
+			revisionLine.IngredientName = line.SubRecipe.Name
+		}
+		ingredients = append(ingredients, revisionLine)
+	}
+	return models.RecipeRevision{
+		RecipeID:    recipe.ID,
+		WorkspaceID: recipe.WorkspaceID,
+		Name:        recipe.Name,
+		RecipeYield: recipe.RecipeYield,
+		Ingredients: ingredients,
+		Steps:       steps,
+	}, nil
+}
+
+func sameRecipeRevision(left models.RecipeRevision, right models.RecipeRevision) bool {
+	if left.Name != right.Name || left.RecipeYield != right.RecipeYield || len(left.Ingredients) != len(right.Ingredients) {
+		return false
+	}
+	if (len(left.Steps) > 0 || len(right.Steps) > 0) && !sameJSONValue(left.Steps, right.Steps) {
+		return false
+	}
+	for i := range left.Ingredients {
+		if left.Ingredients[i].IngredientID != right.Ingredients[i].IngredientID ||
+			left.Ingredients[i].SubRecipeID != right.Ingredients[i].SubRecipeID ||
+			left.Ingredients[i].Quantity != right.Ingredients[i].Quantity ||
+			left.Ingredients[i].Unit != right.Ingredients[i].Unit {
+			return false
+		}
+	}
+	return true
+}
+
+// keyRecipeRevisionLines keys lines by ingredient or sub-recipe and occurrence, so the second line
+// of an ingredient in one revision is compared with the second line of it in the other.
+func keyRecipeRevisionLines(lines models.RecipeRevisionIngredients) map[string]models.RecipeRevisionIngredient {
+	keyed := make(map[string]models.RecipeRevisionIngredient, len(lines))
+	for i, key := range orderedRecipeRevisionKeys(lines) {
+		keyed[key] = lines[i]
+	}
+	return keyed
+}
+
+func orderedRecipeRevisionKeys(lines models.RecipeRevisionIngredients) []string {
+	occurrences := make(map[string]int, len(lines))
+	keys := make([]string, 0, len(lines))
+	for _, line := range lines {
+		item := fmt.Sprintf("%d", line.IngredientID)
+		if line.SubRecipeID != 0 {
+			item = fmt.Sprintf("recipe:%d", line.SubRecipeID)
+		}
+		occurrences[item]++
+		keys = append(keys, fmt.Sprintf("%s/%d", item, occurrences[item]))
+	}
+	return keys
+}
+
+// diffRecipeRevisionSteps compares steps by position.
+func diffRecipeRevisionSteps(from models.RecipeRevisionSteps, to models.RecipeRevisionSteps) []models.RecipeRevisionStepChange {
+	changes := []models.RecipeRevisionStepChange{}
+	for i := 0; i < len(from) || i < len(to); i++ {
+		var before, after map[string]interface{}
+		if i < len(from) {
+			before = recipeStepSnapshot(from[i])
+		}
+		if i < len(to) {
+			after = recipeStepSnapshot(to[i])
+		}
+		if stepChanges := AuditDiff(before, after); len(stepChanges) > 0 {
+			changes = append(changes, models.RecipeRevisionStepChange{Position: i + 1, Changes: stepChanges})
+		}
+	}
+	return changes
+}
+
+// recipeStepSnapshot is AuditSnapshot of a step that keeps its ingredient references.
+func recipeStepSnapshot(step models.RecipeStepDetails) map[string]interface{} {
+	fields, _ := AuditSnapshot(step)
+	ingredientIDs := make([]interface{}, 0, len(step.IngredientIDs))
+	for _, id := range step.IngredientIDs {
+		ingredientIDs = append(ingredientIDs, float64(id))
+	}
+	fields["ingredient_ids"] = ingredientIDs
+	return fields
+}
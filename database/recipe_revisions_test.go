@@ -0,0 +1,87 @@
+package database
+
+import (
+	"testing"
+
+	"mobile-backend-go/models"
+)
+
+func TestDiffRecipeRevisionsMatchesLinesByIngredient(t *testing.T) {
+	from := models.RecipeRevision{
+		RecipeID: 7,
+		Number:   2,
+		Name:     "Teriyaki",
+		Ingredients: models.RecipeRevisionIngredients{
+			{IngredientID: 1, IngredientName: "Beef", Quantity: "1", Unit: "kg"},
+			{IngredientID: 2, IngredientName: "Soy sauce", Quantity: "100", Unit: "ml"},
+			{IngredientID: 3, IngredientName: "Sugar", Quantity: "20", Unit: "g"},
+			{IngredientID: 3, IngredientName: "Sugar", Quantity: "5", Unit: "g"},
+		},
+	}
+	to := models.RecipeRevision{
+		RecipeID: 7,
+		Number:   5,
+		Name:     "Teriyaki marinade",
+		Ingredients: models.RecipeRevisionIngredients{
+			{IngredientID: 1, IngredientName: "Beef", Quantity: "1", Unit: "kg"},
+			{IngredientID: 3, IngredientName: "Sugar", Quantity: "25", Unit: "g"},
+			{IngredientID: 2, IngredientName: "Soy sauce", Quantity: "0.12", Unit: "l"},
+			{IngredientID: 4, IngredientName: "Ginger", Quantity: "10", Unit: "g"},
+		},
+	}
+
+	diff := DiffRecipeRevisions(from, to)
+	if diff.From != 2 || diff.To != 5 || diff.RecipeID != 7 {
+		t.Fatalf("diff header = %+v", diff)
+	}
+	if diff.Name == nil || diff.Name.Before != "Teriyaki" || diff.Name.After != "Teriyaki marinade" {
+		t.Fatalf("name change = %+v", diff.Name)
+	}
+	if len(diff.AddedIngredients) != 1 || diff.AddedIngredients[0].IngredientID != 4 {
+		t.Fatalf("added = %+v, want ginger", diff.AddedIngredients)
+	}
+	if len(diff.RemovedIngredients) != 1 || diff.RemovedIngredients[0].IngredientID != 3 || diff.RemovedIngredients[0].Quantity != "5" {
+		t.Fatalf("removed = %+v, want the second sugar line", diff.RemovedIngredients)
+	}
+	if len(diff.ChangedIngredients) != 2 {
+		t.Fatalf("changed = %+v, want sugar and soy sauce", diff.ChangedIngredients)
+	}
+	sugar := diff.ChangedIngredients[0]
+	if sugar.IngredientID != 3 || len(sugar.Changes) != 1 || sugar.Changes["quantity"].Before != "20" || sugar.Changes["quantity"].After != "25" {
+		t.Fatalf("sugar change = %+v", sugar)
+	}
+	soy := diff.ChangedIngredients[1]
+	if soy.IngredientID != 2 || len(soy.Changes) != 2 || soy.Changes["unit"].After != "l" {
+		t.Fatalf("soy sauce change = %+v", soy)
+	}
+
+	empty := DiffRecipeRevisions(models.RecipeRevision{}, from)
+	if empty.From != 0 || len(empty.AddedIngredients) != 4 || len(empty.RemovedIngredients) != 0 || empty.Name == nil {
+		t.Fatalf("diff against empty recipe = %+v", empty)
+	}
+}
+
+func TestDiffRecipeRevisionsKeepsSubRecipeLinesApartFromIngredients(t *testing.T) {
+	from := models.RecipeRevision{
+		Number: 1,
+		Ingredients: models.RecipeRevisionIngredients{
+			{IngredientID: 5, IngredientName: "Beef", Quantity: "1", Unit: "kg"},
+			{SubRecipeID: 5, IngredientName: "Marinade", Quantity: "200", Unit: "ml"},
+		},
+	}
+	to := models.RecipeRevision{
+		Number: 2,
+		Ingredients: models.RecipeRevisionIngredients{
+			{IngredientID: 5, IngredientName: "Beef", Quantity: "1", Unit: "kg"},
+			{SubRecipeID: 5, IngredientName: "Marinade", Quantity: "250", Unit: "ml"},
+		},
+	}
+
+	diff := DiffRecipeRevisions(from, to)
+	if len(diff.AddedIngredients) != 0 || len(diff.RemovedIngredients) != 0 || len(diff.ChangedIngredients) != 1 {
+		t.Fatalf("diff = %+v, want only the marinade line changed", diff)
+	}
+	if change := diff.ChangedIngredients[0]; change.SubRecipeID != 5 || change.IngredientID != 0 || change.Changes["quantity"].After != "250" {
+		t.Fatalf("marinade change = %+v", change)
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+var ErrStepIngredientNotInRecipe = errors.New("ingredient is not in recipe")
+
+// ListRecipeSteps returns the method steps of recipeID in order.
+func ListRecipeSteps(db *gorm.DB, recipeID uint) ([]models.RecipeStep, error) {
+	var steps []models.RecipeStep
+	err := db.Where("recipe_id = ?", recipeID).Order("position ASC").Find(&steps).Error
+	return steps, err
+}
+
+// ReplaceRecipeSteps sets the method steps of recipeID to steps, numbered in order from 1.
+func ReplaceRecipeSteps(db *gorm.DB, recipeID uint, steps []models.RecipeStepDetails) error {
+	if err := db.Where("recipe_id = ?", recipeID).Delete(&models.RecipeStep{}).Error; err != nil {
+		return err
+	}
+	for i, details := range steps {
+		if details.IngredientIDs == nil {
+			details.IngredientIDs = models.RecipeStepIngredientIDs{}
+		}
+		step := models.RecipeStep{RecipeID: recipeID, Position: i + 1, RecipeStepDetails: details}
+		if err := db.Create(&step).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveIngredientFromRecipeSteps drops ingredientID from the ingredients referenced by the steps of recipeID.
+func RemoveIngredientFromRecipeSteps(db *gorm.DB, recipeID uint, ingredientID uint) error {
+	steps, err := ListRecipeSteps(db, recipeID)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		kept := make(models.RecipeStepIngredientIDs, 0, len(step.IngredientIDs))
+		for _, id := range step.IngredientIDs {
+			if id != ingredientID {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) == len(step.IngredientIDs) {
+			continue
+		}
+		if err := db.Model(&step).Update("ingredient_ids", kept).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+// RecordSecurityEvent stores a security event.
+func RecordSecurityEvent(db *gorm.DB, event *models.SecurityEvent) error {
+	return db.Create(event).Error
+}
+
+// ListSecurityEvents returns the latest security events of userID, newest first,
+// optionally limited to eventType.
+func ListSecurityEvents(db *gorm.DB, userID uint, eventType string, limit int) ([]models.SecurityEvent, error) {
+	query := db.Where("user_id = ?", userID)
+	if eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+
+	var events []models.SecurityEvent
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+var ErrSubRecipeCycle = errors.New("sub-recipe would make the recipe contain itself")
+
+// CheckSubRecipeCycle returns ErrSubRecipeCycle when using subRecipeID inside recipeID would make
+// recipeID contain itself, directly or through the sub-recipes of subRecipeID. It locks the
+// workspace of recipeID first, so that two transactions adding sub-recipes to different recipes of
+// the workspace cannot each pass the check and close a cycle together.
+func CheckSubRecipeCycle(db *gorm.DB, recipeID uint, subRecipeID uint) error {
+	var recipe models.Recipe
+	if err := db.Select("id", "workspace_id").First(&recipe, recipeID).Error; err != nil {
+		return err
+	}
+	if recipe.WorkspaceID != nil {
+		if err := withRowLock(db).Select("id").First(&models.Workspace{}, *recipe.WorkspaceID).Error; err != nil {
+			return err
+		}
+	}
+
+	visited := map[uint]bool{}
+	pending := []uint{subRecipeID}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if current == recipeID {
+			return ErrSubRecipeCycle
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		var children []uint
+		if err := db.Model(&models.RecipeSubRecipe{}).
+			Where("recipe_id = ?", current).
+			Distinct().
+			Pluck("sub_recipe_id", &children).Error; err != nil {
+			return err
+		}
+		pending = append(pending, children...)
+	}
+	return nil
+}
+
+// ListRecipeUsages returns the sub-recipe lines that use recipeID, followed by the lines that use
+// those recipes and so on, ordered by depth. Each recipe is followed up once.
+func ListRecipeUsages(db *gorm.DB, recipeID uint) ([]models.RecipeUsage, error) {
+	usages := []models.RecipeUsage{}
+	visited := map[uint]bool{recipeID: true}
+	level := []uint{recipeID}
+	for depth := 1; len(level) > 0; depth++ {
+		var lines []models.RecipeUsage
+		if err := db.Table("recipe_sub_recipes").
+			Select("recipe_sub_recipes.recipe_id, recipes.name AS recipe_name, recipe_sub_recipes.sub_recipe_id, sub_recipes.name AS sub_recipe_name, recipe_sub_recipes.quantity, recipe_sub_recipes.unit").
+			Joins("JOIN recipes ON recipes.id = recipe_sub_recipes.recipe_id AND recipes.deleted_at IS NULL").
+			Joins("JOIN recipes AS sub_recipes ON sub_recipes.id = recipe_sub_recipes.sub_recipe_id").
+			Where("recipe_sub_recipes.deleted_at IS NULL AND recipe_sub_recipes.sub_recipe_id IN ?", level).
+			Order("recipe_sub_recipes.id ASC").
+			Scan(&lines).Error; err != nil {
+			return nil, err
+		}
+
+		level = nil
+		for _, line := range lines {
+			line.Depth = depth
+			usages = append(usages, line)
+			if !visited[line.RecipeID] {
+				visited[line.RecipeID] = true
+				level = append(level, line.RecipeID)
+			}
+		}
+	}
+	return usages, nil
+}
+
+// RecipeUsedAsSubRecipe reports whether a recipe that is not deleted uses recipeID as a sub-recipe.
+func RecipeUsedAsSubRecipe(db *gorm.DB, recipeID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.RecipeSubRecipe{}).
+		Joins("JOIN recipes ON recipes.id = recipe_sub_recipes.recipe_id AND recipes.deleted_at IS NULL").
+		Where("recipe_sub_recipes.sub_recipe_id = ?", recipeID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/models"
+)
+
+// LoginChallengeTTL is how long the second login step can be completed after the password was accepted.
+const LoginChallengeTTL = 5 * time.Minute
+
+var ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// FindTwoFactor returns the TOTP enrollment of userID, pending or enabled.
+func FindTwoFactor(db *gorm.DB, userID uint) (models.TwoFactor, bool, error) {
+	var twoFactor models.TwoFactor
+	err := db.Where("user_id = ?", userID).First(&twoFactor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return twoFactor, false, nil
+	}
+	return twoFactor, err == nil, err
+}
+
+// UserHasTwoFactor reports whether userID has confirmed a TOTP enrollment.
+func UserHasTwoFactor(db *gorm.DB, userID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.TwoFactor{}).Where("user_id = ? AND enabled_at IS NOT NULL", userID).Count(&count).Error
+	return count > 0, err
+}
+
+// StartTwoFactorEnrollment stores a pending TOTP secret for userID, replacing an earlier pending one.
+func StartTwoFactorEnrollment(db *gorm.DB, userID uint, secret string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		twoFactor, found, err := FindTwoFactor(withRowLock(tx), userID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return tx.Create(&models.TwoFactor{UserID: userID, Secret: secret}).Error
+		}
+		if twoFactor.EnabledAt != nil {
+			return ErrTwoFactorAlreadyEnabled
+		}
+		return tx.Model(&models.TwoFactor{}).Where("id = ?", twoFactor.ID).
+			Updates(map[string]interface{}{"secret": secret, "last_used_step": 0}).Error
+	})
+}
+
+// EnableTwoFactor confirms the pending enrollment of userID with the time step of the
+// accepted code and replaces the recovery codes.
+func EnableTwoFactor(db *gorm.DB, userID uint, step int64, recoveryCodeHashes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TwoFactor{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": time.Now(), "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTwoFactorAlreadyEnabled
+		}
+		return replaceRecoveryCodes(tx, userID, recoveryCodeHashes)
+	})
+}
+
+// DisableTwoFactor removes the TOTP enrollment and recovery codes of userID.
+func DisableTwoFactor(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+// AcceptTOTPStep records step as the last used time step of userID. It reports false when a code
+// of this or a later step was already accepted, which makes every TOTP code single-use.
+func AcceptTOTPStep(db *gorm.DB, userID uint, step int64) (bool, error) {
+	result := db.Model(&models.TwoFactor{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// UseRecoveryCode marks as used the unused recovery code of userID whose hash matches reports true
+// for. Recovery codes are stored with a salted hash, so they are compared one by one.
+func UseRecoveryCode(db *gorm.DB, userID uint, matches func(codeHash string) bool) (bool, error) {
+	var codes []models.RecoveryCode
+	if err := db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error; err != nil {
+		return false, err
+	}
+	for _, code := range codes {
+		if !matches(code.CodeHash) {
+			continue
+		}
+		result := db.Model(&models.RecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", code.ID).
+			Update("used_at", time.Now())
+		return result.RowsAffected == 1, result.Error
+	}
+	return false, nil
+}
+
+// ReplaceRecoveryCodes discards the recovery codes of userID and stores new ones.
+func ReplaceRecoveryCodes(db *gorm.DB, userID uint, codeHashes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// CountUnusedRecoveryCodes returns how many recovery codes userID has left.
+func CountUnusedRecoveryCodes(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// WorkspaceRequiresTwoFactor reports whether workspaceID requires members to use two-factor authentication.
+func WorkspaceRequiresTwoFactor(db *gorm.DB, workspaceID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.WorkspaceSettings{}).
+		Where("workspace_id = ? AND require_two_factor = ?", workspaceID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// IssueLoginChallenge stores a login challenge for userID and returns its expiry.
+func IssueLoginChallenge(db *gorm.DB, userID uint, tokenHash string) (time.Time, error) {
+	expiresAt := time.Now().Add(LoginChallengeTTL)
+	err := IssueOneTimeCode(db, userID, constants.OneTimeCodeLoginChallenge, "", tokenHash, LoginChallengeTTL)
+	return expiresAt, err
+}
+
+// FindLoginChallenge returns the open login challenge hashing to tokenHash.
+func FindLoginChallenge(db *gorm.DB, tokenHash string) (models.OneTimeCode, error) {
+	var challenge models.OneTimeCode
+	err := db.Where("purpose = ? AND code_hash = ? AND used_at IS NULL AND expires_at > ?", constants.OneTimeCodeLoginChallenge, tokenHash, time.Now()).
+		First(&challenge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return challenge, ErrOneTimeCodeInvalid
+	}
+	return challenge, err
+}
+
+// FailLoginChallenge counts a wrong second factor; after OneTimeCodeMaxAttempts the challenge is closed.
+func FailLoginChallenge(db *gorm.DB, challenge models.OneTimeCode) error {
+	updates := map[string]interface{}{"attempts": gorm.Expr("attempts + 1")}
+	if challenge.Attempts+1 >= OneTimeCodeMaxAttempts {
+		updates["used_at"] = time.Now()
+	}
+	return db.Model(&models.OneTimeCode{}).Where("id = ?", challenge.ID).Updates(updates).Error
+}
+
+// CompleteLoginChallenge closes a login challenge. It fails when the challenge was already used.
+func CompleteLoginChallenge(db *gorm.DB, challengeID uint) error {
+	result := db.Model(&models.OneTimeCode{}).Where("id = ? AND used_at IS NULL", challengeID).Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOneTimeCodeInvalid
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.RecoveryCode, 0, len(codeHashes))
+	for _, codeHash := range codeHashes {
+		codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: codeHash})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,800 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/models"
+)
+
+var (
+	ErrUnsupportedWorkspaceBundleVersion = errors.New("unsupported workspace bundle format version")
+	ErrInvalidWorkspaceBundle            = errors.New("invalid workspace bundle")
+
+	errWorkspaceImportDryRun = errors.New("workspace import dry run")
+	bundleCurrencyPattern    = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// ExportWorkspaceBundle serializes all workspace-scoped rows into a portable bundle. Rows that
+// reference rows outside the workspace cannot be imported again; they are left out and listed in
+// bundle.Skipped.
+func ExportWorkspaceBundle(db *gorm.DB, workspaceID uint) (models.WorkspaceBundle, error) {
+	var workspace models.Workspace
+	if err := db.First(&workspace, workspaceID).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+
+	bundle := models.WorkspaceBundle{
+		FormatVersion: models.WorkspaceBundleFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Workspace:     models.WorkspaceBundleWorkspace{Name: workspace.Name, Slug: workspace.Slug},
+		Ingredients:   []models.WorkspaceBundleIngredient{},
+		Recipes:       []models.WorkspaceBundleRecipe{},
+		Prices:        []models.WorkspaceBundlePrice{},
+		Packages:      []models.WorkspaceBundlePackage{},
+		Products:      []models.WorkspaceBundleProduct{},
+		Clients:       []models.WorkspaceBundleClient{},
+		Orders:        []models.WorkspaceBundleOrder{},
+	}
+
+	settings, err := GetWorkspaceSettings(db, workspaceID)
+	if err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	bundle.Settings = &models.WorkspaceBundleSettings{
+		StrictIngredients:         settings.StrictIngredients,
+		DefaultCurrency:           settings.DefaultCurrency,
+		Timezone:                  settings.Timezone,
+		DefaultOrderStatus:        settings.DefaultOrderStatus,
+		UnitSystem:                settings.UnitSystem,
+		LowMarginThresholdPercent: settings.LowMarginThresholdPercent,
+	}
+
+	ingredientIDs := make(map[uint]bool)
+
+	var workspaceIngredients []models.WorkspaceIngredient
+	if err := db.Where("workspace_id = ?", workspaceID).Order("id ASC").Find(&workspaceIngredients).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	memberships := make(map[uint]models.WorkspaceIngredient, len(workspaceIngredients))
+	for _, workspaceIngredient := range workspaceIngredients {
+		memberships[workspaceIngredient.IngredientID] = workspaceIngredient
+		ingredientIDs[workspaceIngredient.IngredientID] = true
+	}
+
+	var recipes []models.Recipe
+	if err := db.Preload("RecipeIngredients", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("SubRecipes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("workspace_id = ?", workspaceID).Order("id ASC").Find(&recipes).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	recipeIDs := make(map[uint]bool, len(recipes))
+	for _, recipe := range recipes {
+		recipeIDs[recipe.ID] = true
+	}
+	skip := func(table string, id uint, reason string) {
+		bundle.Skipped = append(bundle.Skipped, models.WorkspaceBundleSkippedRow{Table: table, ID: id, Reason: reason})
+	}
+	for _, recipe := range recipes {
+		exported := models.WorkspaceBundleRecipe{
+			ID:          recipe.ID,
+			Name:        recipe.Name,
+			Ingredients: make([]models.WorkspaceBundleRecipeIngredient, 0, len(recipe.RecipeIngredients)),
+			RecipeYield: recipe.RecipeYield,
+		}
+		for _, recipeIngredient := range recipe.RecipeIngredients {
+			ingredientIDs[recipeIngredient.IngredientID] = true
+			exported.Ingredients = append(exported.Ingredients, models.WorkspaceBundleRecipeIngredient{
+				IngredientID: recipeIngredient.IngredientID,
+				Quantity:     recipeIngredient.Quantity,
+				Unit:         recipeIngredient.Unit,
+			})
+		}
+		for _, line := range recipe.SubRecipes {
+			if !recipeIDs[line.SubRecipeID] {
+				skip("recipe_sub_recipes", line.ID, "sub-recipe is not in the workspace")
+				continue
+			}
+			exported.SubRecipes = append(exported.SubRecipes, models.WorkspaceBundleRecipeSubRecipe{
+				RecipeID: line.SubRecipeID,
+				Quantity: line.Quantity,
+				Unit:     line.Unit,
+			})
+		}
+		for _, step := range recipe.Steps {
+			exported.Steps = append(exported.Steps, step.RecipeStepDetails)
+		}
+		bundle.Recipes = append(bundle.Recipes, exported)
+	}
+
+	var prices []models.Price
+	if err := db.Where("workspace_id = ?", workspaceID).Order("date ASC, id ASC").Find(&prices).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	for _, price := range prices {
+		ingredientIDs[price.IngredientID] = true
+		bundle.Prices = append(bundle.Prices, models.WorkspaceBundlePrice{
+			IngredientID: price.IngredientID,
+			Price:        price.Price,
+			Quantity:     price.Quantity,
+			Unit:         price.Unit,
+			Date:         price.Date,
+		})
+	}
+
+	var packages []models.Package
+	if err := db.Where("workspace_id = ?", workspaceID).Order("id ASC").Find(&packages).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	packageIDs := make(map[uint]bool, len(packages))
+	for _, pkg := range packages {
+		packageIDs[pkg.ID] = true
+		bundle.Packages = append(bundle.Packages, models.WorkspaceBundlePackage{ID: pkg.ID, Name: pkg.Name})
+	}
+
+	var products []models.Product
+	if err := db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("workspace_id = ?", workspaceID).Order("id ASC").Find(&products).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	productIDs := make(map[uint]bool, len(products))
+	for _, product := range products {
+		if !packageIDs[product.PackageID] {
+			skip("products", product.ID, "package is not in the workspace")
+			continue
+		}
+		productIDs[product.ID] = true
+		exported := models.WorkspaceBundleProduct{
+			ID:          product.ID,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Cost:        product.Cost,
+			Image:       product.Image,
+			PackageID:   product.PackageID,
+			RecipeIDs:   []uint{},
+		}
+		for _, option := range product.Options {
+			if !recipeIDs[option.RecipeID] {
+				skip("product_options", option.ID, "recipe is not in the workspace")
+				continue
+			}
+			exported.RecipeIDs = append(exported.RecipeIDs, option.RecipeID)
+		}
+		bundle.Products = append(bundle.Products, exported)
+	}
+
+	var clients []models.Client
+	if err := db.Where("workspace_id = ?", workspaceID).Order("id ASC").Find(&clients).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	clientIDs := make(map[uint]bool, len(clients))
+	for _, client := range clients {
+		clientIDs[client.ID] = true
+		bundle.Clients = append(bundle.Clients, models.WorkspaceBundleClient{
+			ID:        client.ID,
+			Name:      client.Name,
+			Surname:   client.Surname,
+			Telegram:  client.Telegram,
+			Instagram: client.Instagram,
+			Phone:     client.Phone,
+			Address:   client.Address,
+			Source:    client.Source,
+		})
+	}
+
+	var orders []models.Order
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("workspace_id = ?", workspaceID).Order("date ASC, id ASC").Find(&orders).Error; err != nil {
+		return models.WorkspaceBundle{}, err
+	}
+	for _, order := range orders {
+		if !clientIDs[order.ClientID] {
+			skip("orders", order.ID, "client is not in the workspace")
+			continue
+		}
+		exported := models.WorkspaceBundleOrder{
+			ClientID: order.ClientID,
+			Date:     order.Date,
+			Status:   order.Status,
+			Comment:  order.Comment,
+			Items:    make([]models.WorkspaceBundleOrderItem, 0, len(order.Items)),
+		}
+		for _, item := range order.Items {
+			if !productIDs[item.ProductID] {
+				skip("order_items", item.ID, "product is not exported")
+				continue
+			}
+			exported.Items = append(exported.Items, models.WorkspaceBundleOrderItem{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				Price:     item.Price,
+				CostPrice: item.Cost_price,
+			})
+		}
+		bundle.Orders = append(bundle.Orders, exported)
+	}
+
+	if len(ingredientIDs) > 0 {
+		ids := make([]uint, 0, len(ingredientIDs))
+		for id := range ingredientIDs {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		var ingredients []models.Ingredient
+		if err := db.Unscoped().Where("id IN ?", ids).Order("id ASC").Find(&ingredients).Error; err != nil {
+			return models.WorkspaceBundle{}, err
+		}
+		for _, ingredient := range ingredients {
+			exported := models.WorkspaceBundleIngredient{
+				ID:   ingredient.ID,
+				Name: ingredient.Name,
+				Type: ingredient.Type,
+			}
+			if !ingredient.Nutrition.IsEmpty() {
+				exported.Nutrition = &ingredient.Nutrition
+			}
+			if membership, ok := memberships[ingredient.ID]; ok {
+				exported.InWorkspace = true
+				exported.Active = membership.Active
+				exported.Alias = membership.Alias
+				exported.Category = membership.Category
+				if !membership.NutritionOverride.IsEmpty() {
+					override := membership.NutritionOverride
+					exported.NutritionOverride = &override
+				}
+			}
+			bundle.Ingredients = append(bundle.Ingredients, exported)
+		}
+	}
+
+	return bundle, nil
+}
+
+// ImportWorkspaceBundle adds bundle rows to a workspace inside one transaction.
+// Bundle IDs are remapped to new rows and ingredients are linked to global ingredients by name.
+// With dryRun the transaction is rolled back and only the report is returned.
+func ImportWorkspaceBundle(db *gorm.DB, workspaceID uint, userID uint, bundle models.WorkspaceBundle, dryRun bool) (models.WorkspaceImportReport, error) {
+	report := models.WorkspaceImportReport{
+		DryRun:        dryRun,
+		FormatVersion: bundle.FormatVersion,
+		Created:       map[string]int{},
+	}
+
+	if bundle.FormatVersion != models.WorkspaceBundleFormatVersion {
+		return report, fmt.Errorf("%w: %d", ErrUnsupportedWorkspaceBundleVersion, bundle.FormatVersion)
+	}
+	if err := validateWorkspaceBundle(bundle); err != nil {
+		return report, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		importer := workspaceBundleImporter{
+			tx:          tx,
+			workspaceID: workspaceID,
+			userID:      userID,
+			report:      &report,
+		}
+		if err := importer.run(bundle); err != nil {
+			return err
+		}
+		if dryRun {
+			return errWorkspaceImportDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errWorkspaceImportDryRun) {
+		err = nil
+	}
+
+	return report, err
+}
+
+type workspaceBundleImporter struct {
+	tx          *gorm.DB
+	workspaceID uint
+	userID      uint
+	report      *models.WorkspaceImportReport
+	ingredients map[uint]uint
+	recipes     map[uint]uint
+	packages    map[uint]uint
+	products    map[uint]uint
+	clients     map[uint]uint
+}
+
+func (importer *workspaceBundleImporter) run(bundle models.WorkspaceBundle) error {
+	steps := []func(models.WorkspaceBundle) error{
+		importer.importIngredients,
+		importer.importRecipes,
+		importer.importPrices,
+		importer.importPackages,
+		importer.importProducts,
+		importer.importClients,
+		importer.importOrders,
+		importer.importSettings,
+	}
+	for _, step := range steps {
+		if err := step(bundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (importer *workspaceBundleImporter) importIngredients(bundle models.WorkspaceBundle) error {
+	importer.ingredients = make(map[uint]uint, len(bundle.Ingredients))
+
+	for _, exported := range bundle.Ingredients {
+		name := strings.TrimSpace(exported.Name)
+
+		var ingredient models.Ingredient
+		created := false
+		err := importer.tx.Where("name = ?", name).First(&ingredient).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = importer.tx.Where("LOWER(name) = ?", strings.ToLower(name)).Order("id ASC").First(&ingredient).Error
+		}
+		switch {
+		case err == nil:
+			importer.report.IngredientsMatched++
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Global ingredients are shared by every workspace, so bundle nutrition only goes to
+			// the workspace ingredient below
+			ingredient = models.Ingredient{Name: name, Type: strings.TrimSpace(exported.Type)}
+			if err := importer.tx.Create(&ingredient).Error; err != nil {
+				return err
+			}
+			created = true
+			importer.report.IngredientsCreated++
+			importer.report.CreatedIngredients = append(importer.report.CreatedIngredients, name)
+		default:
+			return err
+		}
+		importer.ingredients[exported.ID] = ingredient.ID
+
+		// Ingredients only referenced by recipes or prices stay out of the working set
+		if !exported.InWorkspace {
+			continue
+		}
+		var linked int64
+		if err := importer.tx.Model(&models.WorkspaceIngredient{}).
+			Where("workspace_id = ? AND ingredient_id = ?", importer.workspaceID, ingredient.ID).
+			Count(&linked).Error; err != nil {
+			return err
+		}
+		workspaceIngredient, err := EnsureWorkspaceIngredient(importer.tx, importer.workspaceID, ingredient.ID)
+		if err != nil {
+			return err
+		}
+		if linked == 0 {
+			importer.report.Created["workspace_ingredients"]++
+		}
+
+		updates := map[string]interface{}{"active": exported.Active}
+		if exported.Alias != "" {
+			updates["alias"] = exported.Alias
+		}
+		if exported.Category != "" {
+			updates["category"] = exported.Category
+		}
+		// The global values of the exporting instance become the override of a created ingredient
+		var override models.Nutrition
+		if created && exported.Nutrition != nil {
+			override = *exported.Nutrition
+		}
+		if exported.NutritionOverride != nil {
+			override = override.Override(*exported.NutritionOverride)
+		}
+		if !override.IsEmpty() {
+			for column, value := range NutritionOverrideUpdates(override) {
+				updates[column] = value
+			}
+		}
+		if err := importer.tx.Model(&models.WorkspaceIngredient{}).Where("id = ?", workspaceIngredient.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (importer *workspaceBundleImporter) importRecipes(bundle models.WorkspaceBundle) error {
+	importer.recipes = make(map[uint]uint, len(bundle.Recipes))
+
+	for _, exported := range bundle.Recipes {
+		recipe := models.Recipe{Name: exported.Name, RecipeYield: exported.RecipeYield, UserID: importer.userID, WorkspaceID: &importer.workspaceID}
+		if err := importer.tx.Omit("User", "Workspace").Create(&recipe).Error; err != nil {
+			return err
+		}
+		importer.recipes[exported.ID] = recipe.ID
+		importer.report.Created["recipes"]++
+
+		for _, line := range exported.Ingredients {
+			recipeIngredient := models.RecipeIngredient{
+				RecipeID:     recipe.ID,
+				IngredientID: importer.ingredients[line.IngredientID],
+				Quantity:     line.Quantity,
+				Unit:         line.Unit,
+			}
+			if err := importer.tx.Omit("Recipe", "Ingredient").Create(&recipeIngredient).Error; err != nil {
+				return err
+			}
+			importer.report.Created["recipe_ingredients"]++
+		}
+
+		steps := make([]models.RecipeStepDetails, 0, len(exported.Steps))
+		for _, step := range exported.Steps {
+			ingredientIDs := make(models.RecipeStepIngredientIDs, 0, len(step.IngredientIDs))
+			for _, ingredientID := range step.IngredientIDs {
+				ingredientIDs = append(ingredientIDs, importer.ingredients[ingredientID])
+			}
+			step.IngredientIDs = ingredientIDs
+			steps = append(steps, step)
+		}
+		if err := ReplaceRecipeSteps(importer.tx, recipe.ID, steps); err != nil {
+			return err
+		}
+		importer.report.Created["recipe_steps"] += len(steps)
+	}
+
+	// Sub-recipe lines may reference recipes listed later in the bundle
+	for _, exported := range bundle.Recipes {
+		for _, line := range exported.SubRecipes {
+			subRecipeLine := models.RecipeSubRecipe{
+				RecipeID:    importer.recipes[exported.ID],
+				SubRecipeID: importer.recipes[line.RecipeID],
+				Quantity:    line.Quantity,
+				Unit:        line.Unit,
+			}
+			if err := importer.tx.Omit("SubRecipe").Create(&subRecipeLine).Error; err != nil {
+				return err
+			}
+			importer.report.Created["recipe_sub_recipes"]++
+		}
+	}
+
+	return nil
+}
+
+func (importer *workspaceBundleImporter) importPrices(bundle models.WorkspaceBundle) error {
+	for _, exported := range bundle.Prices {
+		price := models.Price{
+			IngredientID: importer.ingredients[exported.IngredientID],
+			Price:        exported.Price,
+			Quantity:     exported.Quantity,
+			Unit:         exported.Unit,
+			Date:         exported.Date,
+			UserID:       importer.userID,
+			WorkspaceID:  &importer.workspaceID,
+		}
+		if err := importer.tx.Omit("User", "Workspace", "Ingredient").Create(&price).Error; err != nil {
+			return err
+		}
+		importer.report.Created["prices"]++
+	}
+	return nil
+}
+
+func (importer *workspaceBundleImporter) importPackages(bundle models.WorkspaceBundle) error {
+	importer.packages = make(map[uint]uint, len(bundle.Packages))
+
+	for _, exported := range bundle.Packages {
+		pkg := models.Package{Name: exported.Name, UserID: importer.userID, WorkspaceID: &importer.workspaceID}
+		if err := importer.tx.Omit("User", "Workspace").Create(&pkg).Error; err != nil {
+			return err
+		}
+		importer.packages[exported.ID] = pkg.ID
+		importer.report.Created["packages"]++
+	}
+	return nil
+}
+
+func (importer *workspaceBundleImporter) importProducts(bundle models.WorkspaceBundle) error {
+	importer.products = make(map[uint]uint, len(bundle.Products))
+
+	for _, exported := range bundle.Products {
+		product := models.Product{
+			Name:        exported.Name,
+			Description: exported.Description,
+			Price:       exported.Price,
+			Cost:        exported.Cost,
+			Image:       exported.Image,
+			UserID:      importer.userID,
+			WorkspaceID: &importer.workspaceID,
+			PackageID:   importer.packages[exported.PackageID],
+		}
+		if err := importer.tx.Omit("User", "Workspace", "Package", "Options").Create(&product).Error; err != nil {
+			return err
+		}
+		importer.products[exported.ID] = product.ID
+		importer.report.Created["products"]++
+
+		for _, recipeID := range exported.RecipeIDs {
+			option := models.ProductOption{ProductID: product.ID, RecipeID: importer.recipes[recipeID], UserID: importer.userID}
+			if err := importer.tx.Omit("Product", "Recipe", "User").Create(&option).Error; err != nil {
+				return err
+			}
+			importer.report.Created["product_options"]++
+		}
+	}
+	return nil
+}
+
+func (importer *workspaceBundleImporter) importClients(bundle models.WorkspaceBundle) error {
+	importer.clients = make(map[uint]uint, len(bundle.Clients))
+
+	for _, exported := range bundle.Clients {
+		client := models.Client{
+			Name:        exported.Name,
+			Surname:     exported.Surname,
+			Telegram:    exported.Telegram,
+			Instagram:   exported.Instagram,
+			Phone:       exported.Phone,
+			Address:     exported.Address,
+			Source:      exported.Source,
+			UserID:      importer.userID,
+			WorkspaceID: &importer.workspaceID,
+		}
+		if err := importer.tx.Omit("User", "Workspace", "Orders").Create(&client).Error; err != nil {
+			return err
+		}
+		importer.clients[exported.ID] = client.ID
+		importer.report.Created["clients"]++
+	}
+	return nil
+}
+
+func (importer *workspaceBundleImporter) importOrders(bundle models.WorkspaceBundle) error {
+	for _, exported := range bundle.Orders {
+		order := models.Order{
+			ClientID:    importer.clients[exported.ClientID],
+			Date:        exported.Date,
+			Status:      exported.Status,
+			Comment:     exported.Comment,
+			UserID:      importer.userID,
+			WorkspaceID: &importer.workspaceID,
+		}
+		if err := importer.tx.Omit("Client", "User", "Workspace", "Items").Create(&order).Error; err != nil {
+			return err
+		}
+		importer.report.Created["orders"]++
+
+		for _, exportedItem := range exported.Items {
+			item := models.OrderItem{
+				OrderID:    order.ID,
+				ProductID:  importer.products[exportedItem.ProductID],
+				Quantity:   exportedItem.Quantity,
+				Price:      exportedItem.Price,
+				Cost_price: exportedItem.CostPrice,
+			}
+			if err := importer.tx.Omit("Order", "Product").Create(&item).Error; err != nil {
+				return err
+			}
+			importer.report.Created["order_items"]++
+		}
+	}
+	return nil
+}
+
+// importSettings applies bundle settings only to workspaces that still use the defaults.
+func (importer *workspaceBundleImporter) importSettings(bundle models.WorkspaceBundle) error {
+	if bundle.Settings == nil {
+		return nil
+	}
+
+	var stored int64
+	if err := importer.tx.Model(&models.WorkspaceSettings{}).Where("workspace_id = ?", importer.workspaceID).Count(&stored).Error; err != nil {
+		return err
+	}
+	if stored > 0 {
+		importer.report.Warnings = append(importer.report.Warnings, "Workspace settings were kept because the target workspace already has settings")
+		return nil
+	}
+	if problem := validateWorkspaceBundleSettings(*bundle.Settings); problem != "" {
+		importer.report.Warnings = append(importer.report.Warnings, "Workspace settings were skipped: "+problem)
+		return nil
+	}
+
+	settings := models.WorkspaceSettings{
+		WorkspaceID:               importer.workspaceID,
+		StrictIngredients:         bundle.Settings.StrictIngredients,
+		DefaultCurrency:           bundle.Settings.DefaultCurrency,
+		Timezone:                  bundle.Settings.Timezone,
+		DefaultOrderStatus:        bundle.Settings.DefaultOrderStatus,
+		UnitSystem:                bundle.Settings.UnitSystem,
+		LowMarginThresholdPercent: bundle.Settings.LowMarginThresholdPercent,
+	}
+	if err := SaveWorkspaceSettings(importer.tx, &settings); err != nil {
+		return err
+	}
+	importer.report.SettingsApplied = true
+	return nil
+}
+
+// validateWorkspaceBundle checks required fields and that every reference points to a row in the bundle.
+func validateWorkspaceBundle(bundle models.WorkspaceBundle) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidWorkspaceBundle, fmt.Sprintf(format, args...))
+	}
+
+	ingredientIDs := make(map[uint]bool, len(bundle.Ingredients))
+	ingredientNames := make(map[string]bool, len(bundle.Ingredients))
+	for i, ingredient := range bundle.Ingredients {
+		name := strings.ToLower(strings.TrimSpace(ingredient.Name))
+		if ingredient.ID == 0 || name == "" || strings.TrimSpace(ingredient.Type) == "" {
+			return invalid("ingredients[%d] requires id, name and type", i)
+		}
+		if ingredientIDs[ingredient.ID] || ingredientNames[name] {
+			return invalid("ingredients[%d] is duplicated", i)
+		}
+		if !validBundleNutrition(ingredient.Nutrition) || !validBundleNutrition(ingredient.NutritionOverride) {
+			return invalid("ingredients[%d] has negative nutrition values", i)
+		}
+		ingredientIDs[ingredient.ID] = true
+		ingredientNames[name] = true
+	}
+
+	recipeIDs := make(map[uint]bool, len(bundle.Recipes))
+	for i, recipe := range bundle.Recipes {
+		if recipe.ID == 0 || strings.TrimSpace(recipe.Name) == "" || recipeIDs[recipe.ID] {
+			return invalid("recipes[%d] requires a unique id and a name", i)
+		}
+		recipeIDs[recipe.ID] = true
+		for j, line := range recipe.Ingredients {
+			if !ingredientIDs[line.IngredientID] {
+				return invalid("recipes[%d].ingredients[%d] references unknown ingredient %d", i, j, line.IngredientID)
+			}
+		}
+		for j, step := range recipe.Steps {
+			if strings.TrimSpace(step.Instruction) == "" {
+				return invalid("recipes[%d].steps[%d] requires an instruction", i, j)
+			}
+			for _, ingredientID := range step.IngredientIDs {
+				if !ingredientIDs[ingredientID] {
+					return invalid("recipes[%d].steps[%d] references unknown ingredient %d", i, j, ingredientID)
+				}
+			}
+		}
+	}
+	subRecipes := make(map[uint][]uint, len(bundle.Recipes))
+	for i, recipe := range bundle.Recipes {
+		for j, line := range recipe.SubRecipes {
+			if !recipeIDs[line.RecipeID] {
+				return invalid("recipes[%d].sub_recipes[%d] references unknown recipe %d", i, j, line.RecipeID)
+			}
+			subRecipes[recipe.ID] = append(subRecipes[recipe.ID], line.RecipeID)
+		}
+	}
+	for i, recipe := range bundle.Recipes {
+		if bundleRecipeContains(subRecipes, recipe.ID, recipe.ID, map[uint]bool{}) {
+			return invalid("recipes[%d] contains itself through its sub-recipes", i)
+		}
+	}
+
+	for i, price := range bundle.Prices {
+		if !ingredientIDs[price.IngredientID] {
+			return invalid("prices[%d] references unknown ingredient %d", i, price.IngredientID)
+		}
+		if price.Price < 0 || price.Date.IsZero() {
+			return invalid("prices[%d] requires a non-negative price and a date", i)
+		}
+		if price.Quantity < 1 {
+			return invalid("prices[%d] requires a quantity of at least 1", i)
+		}
+	}
+
+	packageIDs := make(map[uint]bool, len(bundle.Packages))
+	for i, pkg := range bundle.Packages {
+		if pkg.ID == 0 || strings.TrimSpace(pkg.Name) == "" || packageIDs[pkg.ID] {
+			return invalid("packages[%d] requires a unique id and a name", i)
+		}
+		packageIDs[pkg.ID] = true
+	}
+
+	productIDs := make(map[uint]bool, len(bundle.Products))
+	for i, product := range bundle.Products {
+		if product.ID == 0 || strings.TrimSpace(product.Name) == "" || productIDs[product.ID] {
+			return invalid("products[%d] requires a unique id and a name", i)
+		}
+		if !packageIDs[product.PackageID] {
+			return invalid("products[%d] references unknown package %d", i, product.PackageID)
+		}
+		for _, recipeID := range product.RecipeIDs {
+			if !recipeIDs[recipeID] {
+				return invalid("products[%d] references unknown recipe %d", i, recipeID)
+			}
+		}
+		productIDs[product.ID] = true
+	}
+
+	clientIDs := make(map[uint]bool, len(bundle.Clients))
+	for i, client := range bundle.Clients {
+		if client.ID == 0 || strings.TrimSpace(client.Name) == "" || clientIDs[client.ID] {
+			return invalid("clients[%d] requires a unique id and a name", i)
+		}
+		clientIDs[client.ID] = true
+	}
+
+	for i, order := range bundle.Orders {
+		if !clientIDs[order.ClientID] {
+			return invalid("orders[%d] references unknown client %d", i, order.ClientID)
+		}
+		if !constants.IsValidOrderStatus(order.Status) {
+			return invalid("orders[%d] has invalid status %q", i, order.Status)
+		}
+		for j, item := range order.Items {
+			if !productIDs[item.ProductID] {
+				return invalid("orders[%d].items[%d] references unknown product %d", i, j, item.ProductID)
+			}
+			if item.Quantity < 1 {
+				return invalid("orders[%d].items[%d] requires a positive quantity", i, j)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateWorkspaceBundleSettings(settings models.WorkspaceBundleSettings) string {
+	switch {
+	case !bundleCurrencyPattern.MatchString(settings.DefaultCurrency):
+		return "invalid default currency"
+	case !constants.IsValidOrderStatus(settings.DefaultOrderStatus):
+		return "invalid default order status"
+	case !constants.IsValidUnitSystem(settings.UnitSystem):
+		return "invalid unit system"
+	case settings.LowMarginThresholdPercent < 0 || settings.LowMarginThresholdPercent > 100:
+		return "invalid low-margin threshold"
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "" {
+		return "invalid timezone"
+	}
+	return ""
+}
+
+// bundleRecipeContains reports whether recipeID uses target through the sub-recipe graph of a bundle.
+func bundleRecipeContains(subRecipes map[uint][]uint, recipeID uint, target uint, visited map[uint]bool) bool {
+	for _, subRecipeID := range subRecipes[recipeID] {
+		if subRecipeID == target {
+			return true
+		}
+		if visited[subRecipeID] {
+			continue
+		}
+		visited[subRecipeID] = true
+		if bundleRecipeContains(subRecipes, subRecipeID, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// validBundleNutrition rejects nutrition values the API would not accept either: negative values,
+// and more than 100 g of a nutrient per 100 g.
+func validBundleNutrition(nutrition *models.Nutrition) bool {
+	if nutrition == nil {
+		return true
+	}
+	if nutrition.EnergyKcal != nil && *nutrition.EnergyKcal < 0 {
+		return false
+	}
+	for _, grams := range []*float64{nutrition.Protein, nutrition.Fat, nutrition.SaturatedFat, nutrition.Carbohydrates, nutrition.Sugars, nutrition.Salt} {
+		if grams != nil && (*grams < 0 || *grams > 100) {
+			return false
+		}
+	}
+	return true
+}
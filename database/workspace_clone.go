@@ -0,0 +1,237 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"mobile-backend-go/models"
+)
+
+var ErrCloneSourceNotFound = errors.New("clone source not found")
+
+// workspaceCloner copies recipes and products into a target workspace within one transaction.
+// Recipes cloned for product options are cached so a product referencing the same recipe twice
+// gets a single copy.
+type workspaceCloner struct {
+	tx                *gorm.DB
+	sourceWorkspaceID uint
+	targetWorkspaceID uint
+	userID            uint
+	includePrices     bool
+	recipes           map[uint]uint
+}
+
+// CloneRecipeToWorkspace copies a recipe with its ingredient lines from one workspace into another.
+// Recipe ingredients are added to the target working set. With includePrices the latest source
+// price of every ingredient is copied as well.
+func CloneRecipeToWorkspace(db *gorm.DB, recipeID uint, sourceWorkspaceID uint, targetWorkspaceID uint, userID uint, includePrices bool) (models.Recipe, error) {
+	var clone models.Recipe
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cloner := newWorkspaceCloner(tx, sourceWorkspaceID, targetWorkspaceID, userID, includePrices)
+		clonedID, err := cloner.cloneRecipe(recipeID)
+		if err != nil {
+			return err
+		}
+		return tx.Preload("RecipeIngredients.Ingredient").First(&clone, clonedID).Error
+	})
+
+	return clone, err
+}
+
+// CloneProductToWorkspace copies a product with its package and option recipes into another workspace.
+// A package with the same name in the target workspace is reused instead of being duplicated.
+func CloneProductToWorkspace(db *gorm.DB, productID uint, sourceWorkspaceID uint, targetWorkspaceID uint, userID uint, includePrices bool) (models.Product, error) {
+	var clone models.Product
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Preload("Options").Preload("Package").
+			Where("id = ? AND workspace_id = ?", productID, sourceWorkspaceID).
+			First(&product).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCloneSourceNotFound
+			}
+			return err
+		}
+
+		if product.Package.ID == 0 {
+			return fmt.Errorf("%w: product package", ErrCloneSourceNotFound)
+		}
+
+		cloner := newWorkspaceCloner(tx, sourceWorkspaceID, targetWorkspaceID, userID, includePrices)
+
+		packageID, err := cloner.clonePackage(product.Package)
+		if err != nil {
+			return err
+		}
+
+		clone = models.Product{
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Cost:        product.Cost,
+			Image:       product.Image,
+			UserID:      userID,
+			WorkspaceID: &targetWorkspaceID,
+			PackageID:   packageID,
+		}
+		if err := tx.Omit("User", "Workspace", "Package", "Options").Create(&clone).Error; err != nil {
+			return err
+		}
+
+		for _, option := range product.Options {
+			recipeID, err := cloner.cloneRecipe(option.RecipeID)
+			if err != nil {
+				return err
+			}
+			clonedOption := models.ProductOption{ProductID: clone.ID, RecipeID: recipeID, UserID: userID}
+			if err := tx.Omit("Product", "Recipe", "User").Create(&clonedOption).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Preload("Options").Preload("Package").First(&clone, clone.ID).Error
+	})
+
+	return clone, err
+}
+
+func newWorkspaceCloner(tx *gorm.DB, sourceWorkspaceID uint, targetWorkspaceID uint, userID uint, includePrices bool) *workspaceCloner {
+	return &workspaceCloner{
+		tx:                tx,
+		sourceWorkspaceID: sourceWorkspaceID,
+		targetWorkspaceID: targetWorkspaceID,
+		userID:            userID,
+		includePrices:     includePrices,
+		recipes:           make(map[uint]uint),
+	}
+}
+
+func (cloner *workspaceCloner) cloneRecipe(recipeID uint) (uint, error) {
+	if clonedID, ok := cloner.recipes[recipeID]; ok {
+		return clonedID, nil
+	}
+
+	var recipe models.Recipe
+	if err := cloner.tx.Preload("RecipeIngredients").Preload("SubRecipes").Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).
+		Where("id = ? AND workspace_id = ?", recipeID, cloner.sourceWorkspaceID).
+		First(&recipe).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrCloneSourceNotFound
+		}
+		return 0, err
+	}
+
+	clone := models.Recipe{Name: recipe.Name, RecipeYield: recipe.RecipeYield, UserID: cloner.userID, WorkspaceID: &cloner.targetWorkspaceID}
+	if err := cloner.tx.Omit("User", "Workspace").Create(&clone).Error; err != nil {
+		return 0, err
+	}
+	cloner.recipes[recipeID] = clone.ID
+
+	for _, line := range recipe.RecipeIngredients {
+		if _, err := EnsureWorkspaceIngredient(cloner.tx, cloner.targetWorkspaceID, line.IngredientID); err != nil {
+			return 0, err
+		}
+		clonedLine := models.RecipeIngredient{
+			RecipeID:     clone.ID,
+			IngredientID: line.IngredientID,
+			Quantity:     line.Quantity,
+			Unit:         line.Unit,
+		}
+		if err := cloner.tx.Omit("Recipe", "Ingredient").Create(&clonedLine).Error; err != nil {
+			return 0, err
+		}
+		if cloner.includePrices {
+			if err := cloner.cloneLatestPrice(line.IngredientID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// Ingredients are global, so steps keep referencing the same ones
+	steps := make([]models.RecipeStepDetails, 0, len(recipe.Steps))
+	for _, step := range recipe.Steps {
+		steps = append(steps, step.RecipeStepDetails)
+	}
+	if err := ReplaceRecipeSteps(cloner.tx, clone.ID, steps); err != nil {
+		return 0, err
+	}
+
+	// Sub-recipes are cloned along, once per clone operation
+	for _, line := range recipe.SubRecipes {
+		subRecipeID, err := cloner.cloneRecipe(line.SubRecipeID)
+		if err != nil {
+			return 0, err
+		}
+		clonedLine := models.RecipeSubRecipe{
+			RecipeID:    clone.ID,
+			SubRecipeID: subRecipeID,
+			Quantity:    line.Quantity,
+			Unit:        line.Unit,
+		}
+		if err := cloner.tx.Omit("SubRecipe").Create(&clonedLine).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	return clone.ID, nil
+}
+
+// cloneLatestPrice copies the newest source price of an ingredient unless the target already has it.
+func (cloner *workspaceCloner) cloneLatestPrice(ingredientID uint) error {
+	var latest models.Price
+	result := cloner.tx.Where("ingredient_id = ? AND workspace_id = ?", ingredientID, cloner.sourceWorkspaceID).
+		Order("date DESC, created_at DESC, id DESC").
+		Limit(1).
+		Find(&latest)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	var existing int64
+	if err := cloner.tx.Model(&models.Price{}).
+		Where("ingredient_id = ? AND workspace_id = ? AND price = ? AND quantity = ? AND unit = ? AND date = ?",
+			ingredientID, cloner.targetWorkspaceID, latest.Price, latest.Quantity, latest.Unit, latest.Date).
+		Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	price := models.Price{
+		IngredientID: ingredientID,
+		Price:        latest.Price,
+		Quantity:     latest.Quantity,
+		Unit:         latest.Unit,
+		Date:         latest.Date,
+		UserID:       cloner.userID,
+		WorkspaceID:  &cloner.targetWorkspaceID,
+	}
+	return cloner.tx.Omit("User", "Workspace", "Ingredient").Create(&price).Error
+}
+
+func (cloner *workspaceCloner) clonePackage(pkg models.Package) (uint, error) {
+	var existing models.Package
+	result := cloner.tx.Where("workspace_id = ? AND name = ?", cloner.targetWorkspaceID, pkg.Name).
+		Order("id ASC").
+		Limit(1).
+		Find(&existing)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return existing.ID, nil
+	}
+
+	clone := models.Package{Name: pkg.Name, UserID: cloner.userID, WorkspaceID: &cloner.targetWorkspaceID}
+	if err := cloner.tx.Omit("User", "Workspace", "Products").Create(&clone).Error; err != nil {
+		return 0, err
+	}
+	return clone.ID, nil
+}
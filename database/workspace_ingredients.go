@@ -141,3 +141,17 @@ func RequireWorkspaceIngredient(db *gorm.DB, workspaceID uint, ingredientID uint
 	}
 	return nil
 }
+
+// NutritionOverrideUpdates returns the workspace ingredient columns that store override, for
+// Updates. Unset values clear the override, so the global ingredient value applies again.
+func NutritionOverrideUpdates(override models.Nutrition) map[string]interface{} {
+	return map[string]interface{}{
+		"nutrition_energy_kcal":   override.EnergyKcal,
+		"nutrition_protein":       override.Protein,
+		"nutrition_fat":           override.Fat,
+		"nutrition_saturated_fat": override.SaturatedFat,
+		"nutrition_carbohydrates": override.Carbohydrates,
+		"nutrition_sugars":        override.Sugars,
+		"nutrition_salt":          override.Salt,
+	}
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/models"
+)
+
+var (
+	ErrLastWorkspaceOwner          = errors.New("workspace must keep at least one owner")
+	ErrWorkspaceMemberNotFound     = errors.New("workspace member not found")
+	ErrWorkspaceMemberExists       = errors.New("user is already a workspace member")
+	ErrWorkspaceInvitationInactive = errors.New("workspace invitation is no longer pending")
+)
+
+// UpdateWorkspaceMemberRole changes a member role while keeping at least one owner.
+func UpdateWorkspaceMemberRole(db *gorm.DB, workspaceID uint, userID uint, role string) (models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		member, err = lockWorkspaceMember(tx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == constants.WorkspaceRoleOwner && role != constants.WorkspaceRoleOwner {
+			if err := requireAnotherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+
+		member.Role = role
+		return tx.Model(&models.WorkspaceMember{}).Where("id = ?", member.ID).Update("role", role).Error
+	})
+
+	return member, err
+}
+
+// RemoveWorkspaceMember soft-deletes a membership while keeping at least one owner.
+func RemoveWorkspaceMember(db *gorm.DB, workspaceID uint, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		member, err := lockWorkspaceMember(tx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == constants.WorkspaceRoleOwner {
+			if err := requireAnotherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&models.WorkspaceMember{}, member.ID).Error
+	})
+}
+
+// AcceptWorkspaceInvitation marks a pending invitation accepted and adds the user to the workspace.
+func AcceptWorkspaceInvitation(db *gorm.DB, invitationID uint, userID uint) (models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		invitation, err := claimPendingInvitation(tx, invitationID, constants.WorkspaceInvitationAccepted)
+		if err != nil {
+			return err
+		}
+
+		var workspace models.Workspace
+		if err := tx.First(&workspace, invitation.WorkspaceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWorkspaceInvitationInactive
+			}
+			return err
+		}
+
+		_, found, err := FindWorkspaceMember(tx, userID, invitation.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if found {
+			return ErrWorkspaceMemberExists
+		}
+
+		newMember := models.WorkspaceMember{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		}
+		if err := tx.Create(&newMember).Error; err != nil {
+			return err
+		}
+
+		member, found, err = FindWorkspaceMember(tx, userID, invitation.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrWorkspaceMemberNotFound
+		}
+		return nil
+	})
+
+	return member, err
+}
+
+// DeclineWorkspaceInvitation marks a pending invitation declined.
+func DeclineWorkspaceInvitation(db *gorm.DB, invitationID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		_, err := claimPendingInvitation(tx, invitationID, constants.WorkspaceInvitationDeclined)
+		return err
+	})
+}
+
+// claimPendingInvitation atomically moves a pending, unexpired invitation to its final status.
+func claimPendingInvitation(tx *gorm.DB, invitationID uint, status string) (models.WorkspaceInvitation, error) {
+	now := time.Now()
+	result := tx.Model(&models.WorkspaceInvitation{}).
+		Where("id = ? AND status = ? AND expires_at > ?", invitationID, constants.WorkspaceInvitationPending, now).
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if result.Error != nil {
+		return models.WorkspaceInvitation{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.WorkspaceInvitation{}, ErrWorkspaceInvitationInactive
+	}
+
+	var invitation models.WorkspaceInvitation
+	if err := tx.First(&invitation, invitationID).Error; err != nil {
+		return models.WorkspaceInvitation{}, err
+	}
+	return invitation, nil
+}
+
+func lockWorkspaceMember(tx *gorm.DB, workspaceID uint, userID uint) (models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := withRowLock(tx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return member, ErrWorkspaceMemberNotFound
+	}
+	return member, err
+}
+
+func requireAnotherOwner(tx *gorm.DB, workspaceID uint, userID uint) error {
+	var owners []models.WorkspaceMember
+	if err := withRowLock(tx).
+		Where("workspace_id = ? AND role = ? AND user_id <> ?", workspaceID, constants.WorkspaceRoleOwner, userID).
+		Find(&owners).Error; err != nil {
+		return err
+	}
+	if len(owners) == 0 {
+		return ErrLastWorkspaceOwner
+	}
+	return nil
+}
+
+// withRowLock adds SELECT ... FOR UPDATE on databases that support it.
+func withRowLock(tx *gorm.DB) *gorm.DB {
+	if tx.Dialector.Name() == "postgres" {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	return tx
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/models"
+)
+
+// Defaults applied to workspaces that have not stored settings yet.
+const (
+	DefaultWorkspaceCurrency                  = "EUR"
+	DefaultWorkspaceTimezone                  = "UTC"
+	DefaultWorkspaceLowMarginThresholdPercent = 20
+)
+
+// DefaultWorkspaceSettings returns the settings used when a workspace has no stored row.
+func DefaultWorkspaceSettings(workspaceID uint) models.WorkspaceSettings {
+	return models.WorkspaceSettings{
+		WorkspaceID:               workspaceID,
+		StrictIngredients:         false,
+		DefaultCurrency:           DefaultWorkspaceCurrency,
+		Timezone:                  DefaultWorkspaceTimezone,
+		DefaultOrderStatus:        constants.OrderStatusNew,
+		UnitSystem:                constants.UnitSystemMetric,
+		LowMarginThresholdPercent: DefaultWorkspaceLowMarginThresholdPercent,
+	}
+}
+
+// GetWorkspaceSettings returns stored workspace settings or the defaults.
+func GetWorkspaceSettings(db *gorm.DB, workspaceID uint) (models.WorkspaceSettings, error) {
+	var settings models.WorkspaceSettings
+	err := db.Where("workspace_id = ?", workspaceID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultWorkspaceSettings(workspaceID), nil
+	}
+	return settings, err
+}
+
+// GetWorkspaceSettingsByWorkspace returns the settings of every workspace in workspaceIDs, keyed by
+// workspace ID, with the defaults for workspaces without a stored row.
+func GetWorkspaceSettingsByWorkspace(db *gorm.DB, workspaceIDs []uint) (map[uint]models.WorkspaceSettings, error) {
+	settingsByWorkspace := make(map[uint]models.WorkspaceSettings, len(workspaceIDs))
+	if len(workspaceIDs) == 0 {
+		return settingsByWorkspace, nil
+	}
+
+	var stored []models.WorkspaceSettings
+	if err := db.Where("workspace_id IN ?", workspaceIDs).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	for _, workspaceID := range workspaceIDs {
+		settingsByWorkspace[workspaceID] = DefaultWorkspaceSettings(workspaceID)
+	}
+	for _, settings := range stored {
+		settingsByWorkspace[settings.WorkspaceID] = settings
+	}
+	return settingsByWorkspace, nil
+}
+
+// SaveWorkspaceSettings inserts or replaces the settings row of settings.WorkspaceID.
+func SaveWorkspaceSettings(db *gorm.DB, settings *models.WorkspaceSettings) error {
+	if err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "workspace_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at",
+			"strict_ingredients",
+			"default_currency",
+			"timezone",
+			"default_order_status",
+			"unit_system",
+			"low_margin_threshold_percent",
+			"require_two_factor",
+		}),
+	}).Create(settings).Error; err != nil {
+		return err
+	}
+
+	return db.Where("workspace_id = ?", settings.WorkspaceID).First(settings).Error
+}
+
+// BackfillLegacyStrictIngredientSettings stores strict ingredient mode for workspaces without settings
+// when the deprecated STRICT_WORKSPACE_INGREDIENTS=true flag is still configured, so existing
+// deployments keep their policy after the flag moved into workspace settings.
+func BackfillLegacyStrictIngredientSettings(db *gorm.DB) error {
+	if !strings.EqualFold(os.Getenv("STRICT_WORKSPACE_INGREDIENTS"), "true") {
+		return nil
+	}
+
+	var workspaceIDs []uint
+	if err := db.Model(&models.Workspace{}).
+		Where("NOT EXISTS (SELECT 1 FROM workspace_settings ws WHERE ws.workspace_id = workspaces.id)").
+		Pluck("id", &workspaceIDs).Error; err != nil {
+		return err
+	}
+
+	for _, workspaceID := range workspaceIDs {
+		settings := DefaultWorkspaceSettings(workspaceID)
+		settings.StrictIngredients = true
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&settings).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
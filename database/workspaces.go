@@ -1,7 +1,9 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"mobile-backend-go/constants"
@@ -11,6 +13,17 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+const (
+	personalWorkspaceSlugPrefix = "personal-"
+	defaultWorkspaceSlug        = "workspace"
+)
+
+var (
+	ErrWorkspaceSlugTaken    = errors.New("workspace slug is already taken")
+	ErrWorkspaceSlugInvalid  = errors.New("workspace slug is invalid")
+	ErrPersonalWorkspaceSlug = errors.New("personal workspace slug cannot be changed")
+)
+
 type workspaceMemberLookupRow struct {
 	MemberID           uint
 	MemberCreatedAt    time.Time
@@ -97,6 +110,140 @@ func EnsurePersonalWorkspaceForUser(db *gorm.DB, userID uint) (models.WorkspaceM
 	return member, err
 }
 
+// CreateBusinessWorkspace creates a shared workspace and makes the creator its owner.
+// An empty slug is derived from the name and made unique with a numeric suffix.
+func CreateBusinessWorkspace(db *gorm.DB, userID uint, name string, slug string) (models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		resolvedSlug, err := resolveWorkspaceSlug(tx, name, slug, 0)
+		if err != nil {
+			return err
+		}
+
+		workspace := models.Workspace{
+			Name: strings.TrimSpace(name),
+			Slug: resolvedSlug,
+		}
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+
+		owner := models.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      userID,
+			Role:        constants.WorkspaceRoleOwner,
+		}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+
+		var found bool
+		member, found, err = FindWorkspaceMember(tx, userID, workspace.ID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("failed to create owner membership for workspace %d", workspace.ID)
+		}
+		return nil
+	})
+
+	return member, err
+}
+
+// RenameWorkspace updates a workspace name and, for shared workspaces, its slug.
+func RenameWorkspace(db *gorm.DB, workspace *models.Workspace, name string, slug *string) error {
+	updates := map[string]interface{}{"name": strings.TrimSpace(name)}
+	if slug != nil && *slug != workspace.Slug {
+		if workspace.PersonalUserID != nil {
+			return ErrPersonalWorkspaceSlug
+		}
+		resolvedSlug, err := resolveWorkspaceSlug(db, name, *slug, workspace.ID)
+		if err != nil {
+			return err
+		}
+		updates["slug"] = resolvedSlug
+	}
+
+	if err := db.Model(&models.Workspace{}).Where("id = ?", workspace.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return db.First(workspace, workspace.ID).Error
+}
+
+func resolveWorkspaceSlug(db *gorm.DB, name string, requestedSlug string, excludeWorkspaceID uint) (string, error) {
+	requestedSlug = strings.TrimSpace(requestedSlug)
+	if requestedSlug != "" {
+		if !isValidWorkspaceSlug(requestedSlug) || strings.HasPrefix(requestedSlug, personalWorkspaceSlugPrefix) {
+			return "", ErrWorkspaceSlugInvalid
+		}
+		taken, err := workspaceSlugTaken(db, requestedSlug, excludeWorkspaceID)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", ErrWorkspaceSlugTaken
+		}
+		return requestedSlug, nil
+	}
+
+	base := slugifyWorkspaceName(name)
+	if base == "" || strings.HasPrefix(base, personalWorkspaceSlugPrefix) {
+		base = strings.Trim(defaultWorkspaceSlug+"-"+base, "-")
+	}
+	candidate := base
+	for suffix := 2; ; suffix++ {
+		taken, err := workspaceSlugTaken(db, candidate, excludeWorkspaceID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, suffix)
+	}
+}
+
+// slugifyWorkspaceName converts a display name into a lowercase, dash-separated slug.
+func slugifyWorkspaceName(value string) string {
+	var builder strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingDash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			pendingDash = false
+			continue
+		}
+		pendingDash = true
+	}
+
+	slug := builder.String()
+	if len(slug) > 64 {
+		slug = strings.TrimRight(slug[:64], "-")
+	}
+	return slug
+}
+
+func isValidWorkspaceSlug(value string) bool {
+	return value != "" && slugifyWorkspaceName(value) == value
+}
+
+func workspaceSlugTaken(db *gorm.DB, slug string, excludeWorkspaceID uint) (bool, error) {
+	var count int64
+	query := db.Model(&models.Workspace{}).Where("slug = ?", slug)
+	if excludeWorkspaceID != 0 {
+		query = query.Where("id <> ?", excludeWorkspaceID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // BackfillPersonalWorkspaces creates a default personal workspace for every user.
 func BackfillPersonalWorkspaces(db *gorm.DB) error {
 	var users []models.User
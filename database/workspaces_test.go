@@ -0,0 +1,40 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugifyWorkspaceName(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Jerky Kitchen", want: "jerky-kitchen"},
+		{value: "  Smoke & Salt  ", want: "smoke-salt"},
+		{value: "Kitchen #2", want: "kitchen-2"},
+		{value: "---", want: ""},
+		{value: "Кухня", want: ""},
+		{value: strings.Repeat("a", 70), want: strings.Repeat("a", 64)},
+	}
+
+	for _, tt := range tests {
+		if got := slugifyWorkspaceName(tt.value); got != tt.want {
+			t.Fatalf("slugifyWorkspaceName(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidWorkspaceSlug(t *testing.T) {
+	for _, slug := range []string{"kitchen", "jerky-kitchen-2"} {
+		if !isValidWorkspaceSlug(slug) {
+			t.Fatalf("expected %q to be valid", slug)
+		}
+	}
+
+	for _, slug := range []string{"", "Kitchen", "jerky kitchen", "-kitchen", "kitchen-"} {
+		if isValidWorkspaceSlug(slug) {
+			t.Fatalf("expected %q to be invalid", slug)
+		}
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"mobile-backend-go/database"
 	_ "mobile-backend-go/docs" // Import for Swagger documentation
 	"mobile-backend-go/middleware"
+	"mobile-backend-go/notifications"
 	"mobile-backend-go/routes"
 	"os"
+	_ "time/tzdata" // Embed time zone data for workspace timezone settings in minimal images
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -43,7 +45,7 @@ func loadEnvVar(key string) string {
 func main() {
 	// Define required environment variables
 	//requiredEnvVars := []string{"DB_HOST", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_PORT", "FRONT_URL"}
-	requiredEnvVars := []string{"DATABASE_URL", "FRONT_URL", "JWT_SECRET"}
+	requiredEnvVars := []string{"DATABASE_URL", "FRONT_URL"}
 
 	// Check for all required environment variables
 	for _, envVar := range requiredEnvVars {
@@ -53,10 +55,19 @@ func main() {
 		}
 	}
 
-	if err := middleware.ValidateJWTSecret(); err != nil {
+	// Access tokens are signed with keys from JWT_KEYS_DIR; JWT_SECRET keeps HS256 tokens working
+	loadEnvVar("JWT_KEYS_DIR")
+	loadEnvVar("JWT_SECRET")
+	if err := middleware.ConfigureTokenKeys(); err != nil {
 		log.Fatalf("JWT configuration error: %v", err)
 	}
 
+	notifier, err := notifications.FromEnv()
+	if err != nil {
+		log.Fatalf("Mail configuration error: %v", err)
+	}
+	notifications.Default = notifier
+
 	// Connect to database and run migrations
 	database.ConnectDatabase()
 
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// AuthMiddleware accepts either a workspace API key or a user JWT.
+// API keys are read from the X-API-Key header or from a Bearer token starting with
+// constants.APIKeyPrefix. They set userID to the member who created the key and resolve
+// the workspace context of the key's workspace; everything else is handled by JWTMiddleware.
+func AuthMiddleware() gin.HandlerFunc {
+	jwtMiddleware := JWTMiddleware()
+
+	return func(c *gin.Context) {
+		apiKey, ok := apiKeyFromRequest(c)
+		if !ok {
+			jwtMiddleware(c)
+			return
+		}
+
+		key, member, err := database.AuthenticateAPIKey(database.DB, utils.HashToken(apiKey))
+		if err != nil {
+			if errors.Is(err, database.ErrAPIKeyInvalid) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				c.Abort()
+				return
+			}
+			log.Printf("Failed to authenticate API key: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate API key"})
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", key.UserID)
+		c.Set("apiKeyID", key.ID)
+		c.Set("apiKeyScopes", []string(key.Scopes))
+		setWorkspaceContext(c, member)
+		c.Next()
+	}
+}
+
+// RequireUserAuth rejects requests authenticated with an API key.
+func RequireUserAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIKey := c.Get("apiKeyID"); isAPIKey {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot access this endpoint"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func apiKeyFromRequest(c *gin.Context) (string, bool) {
+	if key := strings.TrimSpace(c.GetHeader(apiKeyHeader)); key != "" {
+		return key, true
+	}
+
+	authorization := c.GetHeader("Authorization")
+	if len(authorization) > 7 && strings.ToUpper(authorization[:7]) == "BEARER " {
+		token := strings.TrimSpace(authorization[7:])
+		if strings.HasPrefix(token, constants.APIKeyPrefix) {
+			return token, true
+		}
+	}
+	return "", false
+}
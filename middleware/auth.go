@@ -6,16 +6,52 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"log"
+	"mobile-backend-go/database"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// AccessTokenTTL is how long an access token is accepted. Clients renew it with a refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
 type Claims struct {
 	UserID uint `json:"userID"`
+	// SessionID links the token to a server-side session so it can be revoked.
+	// Tokens issued before sessions existed have none.
+	SessionID uint `json:"sid,omitempty"`
+	// Generation must match the user's token generation, which changes with the password.
+	Generation uint `json:"gen,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// IssueAccessToken signs a short-lived access token for userID within sessionID
+// carrying the user's current token generation. See ConfigureTokenKeys for the signing key.
+func IssueAccessToken(userID uint, sessionID uint, generation uint) (string, time.Time, error) {
+	now := time.Now()
+	expirationTime := now.Add(AccessTokenTTL)
+	claims := &Claims{
+		UserID:     userID,
+		SessionID:  sessionID,
+		Generation: generation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   "user:" + strconv.FormatUint(uint64(userID), 10),
+		},
+	}
+
+	tokenString, err := activeTokenKeys().sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expirationTime, nil
+}
+
 // ValidateJWTSecret checks that JWT_SECRET is set and has sufficient length.
 func ValidateJWTSecret() error {
 	secret := os.Getenv("JWT_SECRET")
@@ -44,13 +80,7 @@ func JWTMiddleware() gin.HandlerFunc {
 		}
 
 		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Check signing method to protect against "none algorithm" attack
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		token, err := jwt.ParseWithClaims(tokenString, claims, activeTokenKeys().verificationKey)
 
 		if err != nil {
 			if errors.Is(err, jwt.ErrTokenExpired) {
@@ -70,8 +100,42 @@ func JWTMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Set userID in context for use in controllers
+		generation, err := database.UserTokenGeneration(database.DB, claims.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+			log.Printf("Failed to check token generation of user %d: %v", claims.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate token"})
+			c.Abort()
+			return
+		}
+		if claims.Generation != generation {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been invalidated"})
+			c.Abort()
+			return
+		}
+
+		if claims.SessionID != 0 {
+			active, err := database.TouchAuthSession(database.DB, claims.SessionID, c.ClientIP(), time.Now())
+			if err != nil {
+				log.Printf("Failed to check session %d: %v", claims.SessionID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate session"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
+		// Set userID and sessionID in context for use in controllers
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
 		c.Next()
 	}
 }
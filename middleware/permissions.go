@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"mobile-backend-go/constants"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Machine-readable reasons returned with 403 responses.
+const (
+	PermissionReasonWorkspaceRole = "workspace_role_forbidden"
+	PermissionReasonOwnerRole     = "owner_role_required"
+	PermissionReasonAPIKeyScope   = "api_key_scope_missing"
+	PermissionReasonTwoFactor     = "two_factor_required"
+)
+
+// RequireWorkspacePermission allows the request only when the workspace role resolved by
+// WorkspaceMiddleware may perform action on resource. Requests made with an API key
+// additionally need the matching scope.
+func RequireWorkspacePermission(resource string, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("workspaceRole")
+		if !constants.WorkspaceRoleAllows(role, resource, action) {
+			AbortWorkspacePermissionDenied(c, role, resource, action)
+			return
+		}
+		if scopes, isAPIKey := c.Get("apiKeyScopes"); isAPIKey && !constants.APIKeyScopesAllow(scopes.([]string), resource, action) {
+			AbortAPIKeyScopeMissing(c, resource, action)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AbortWorkspacePermissionDenied writes the standard 403 response for a missing workspace permission.
+func AbortWorkspacePermissionDenied(c *gin.Context, role string, resource string, action string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":    "Insufficient workspace permissions",
+		"reason":   PermissionReasonWorkspaceRole,
+		"required": constants.WorkspacePermission(resource, action),
+		"role":     role,
+	})
+	c.Abort()
+}
+
+// AbortAPIKeyScopeMissing writes the standard 403 response for an API key without the scope of the request.
+func AbortAPIKeyScopeMissing(c *gin.Context, resource string, action string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":    "API key scope does not allow this request",
+		"reason":   PermissionReasonAPIKeyScope,
+		"required": constants.APIKeyScope(resource, action),
+	})
+	c.Abort()
+}
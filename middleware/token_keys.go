@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Access tokens are signed with an Ed25519 (EdDSA) or RSA (RS256) private key from JWT_KEYS_DIR
+// and carry the key's file name as kid. Every key in the directory verifies tokens, so a new key
+// can be published before it signs and an old one kept, or reduced to its public key, until its
+// tokens have expired. While JWT_SECRET is set, HS256 tokens are still signed (without key files)
+// or accepted (with key files) for the migration.
+
+const minRSAKeyBits = 2048
+
+type tokenKey struct {
+	id     string
+	method jwt.SigningMethod
+	// private is nil for keys that only verify tokens.
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+type tokenKeySet struct {
+	keys       map[string]*tokenKey
+	signing    *tokenKey
+	hmacSecret []byte
+}
+
+// JWK is a public verification key as published in the JWKS document.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+}
+
+// configuredTokenKeys is set once at startup by ConfigureTokenKeys.
+var configuredTokenKeys *tokenKeySet
+
+// ConfigureTokenKeys loads the access token keys from JWT_KEYS_DIR and JWT_SECRET.
+// JWT_SIGNING_KEY_ID selects the signing key; by default the key whose id sorts last signs.
+func ConfigureTokenKeys() error {
+	secret := os.Getenv("JWT_SECRET")
+	if secret != "" {
+		if err := ValidateJWTSecret(); err != nil {
+			return err
+		}
+	}
+
+	keysDir := os.Getenv("JWT_KEYS_DIR")
+	if keysDir == "" {
+		if secret == "" {
+			return fmt.Errorf("either JWT_KEYS_DIR or JWT_SECRET must be set")
+		}
+		log.Println("JWT_KEYS_DIR is not set; signing access tokens with HS256")
+		configuredTokenKeys = &tokenKeySet{hmacSecret: []byte(secret)}
+		return nil
+	}
+
+	keySet, err := loadTokenKeySet(keysDir, os.Getenv("JWT_SIGNING_KEY_ID"), secret)
+	if err != nil {
+		return err
+	}
+	log.Printf("Signing access tokens with key %s (%s), %d verification keys loaded", keySet.signing.id, keySet.signing.method.Alg(), len(keySet.keys))
+	configuredTokenKeys = keySet
+	return nil
+}
+
+// PublicJWKS returns the public keys that verify access tokens, ordered by key id.
+func PublicJWKS() []JWK {
+	keySet := activeTokenKeys()
+	jwks := make([]JWK, 0, len(keySet.keys))
+	for _, key := range keySet.keys {
+		jwks = append(jwks, key.jwk())
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].KeyID < jwks[j].KeyID })
+	return jwks
+}
+
+// activeTokenKeys returns the configured keys. Without configuration, such as in tests,
+// tokens are signed with HS256 and the current JWT_SECRET.
+func activeTokenKeys() *tokenKeySet {
+	if configuredTokenKeys != nil {
+		return configuredTokenKeys
+	}
+	return &tokenKeySet{hmacSecret: []byte(os.Getenv("JWT_SECRET"))}
+}
+
+func (keySet *tokenKeySet) sign(claims jwt.Claims) (string, error) {
+	if keySet.signing == nil {
+		if len(keySet.hmacSecret) == 0 {
+			return "", fmt.Errorf("no token signing key configured")
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(keySet.hmacSecret)
+	}
+
+	token := jwt.NewWithClaims(keySet.signing.method, claims)
+	token.Header["kid"] = keySet.signing.id
+	return token.SignedString(keySet.signing.private)
+}
+
+// verificationKey is the jwt.Keyfunc for access tokens. Checking the method against the key
+// protects against "none" and algorithm confusion attacks.
+func (keySet *tokenKeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		if len(keySet.hmacSecret) == 0 {
+			return nil, fmt.Errorf("HS256 tokens are no longer accepted")
+		}
+		return keySet.hmacSecret, nil
+	}
+
+	keyID, _ := token.Header["kid"].(string)
+	key, found := keySet.keys[keyID]
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %q", token.Header["alg"], keyID)
+	}
+	return key.public, nil
+}
+
+// loadTokenKeySet reads every *.pem file in dir as a private or public key named after the file.
+func loadTokenKeySet(dir string, signingKeyID string, hmacSecret string) (*tokenKeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keySet := &tokenKeySet{keys: make(map[string]*tokenKey), hmacSecret: []byte(hmacSecret)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseTokenKey(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keySet.keys[key.id] = key
+		if key.private != nil && signingKeyID == "" && (keySet.signing == nil || key.id > keySet.signing.id) {
+			keySet.signing = key
+		}
+	}
+
+	if signingKeyID != "" {
+		keySet.signing = keySet.keys[signingKeyID]
+		if keySet.signing == nil || keySet.signing.private == nil {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_ID %q is not a private key in %s", signingKeyID, dir)
+		}
+	}
+	if keySet.signing == nil {
+		return nil, fmt.Errorf("no private key found in %s", dir)
+	}
+	return keySet, nil
+}
+
+func parseTokenKey(id string, data []byte) (*tokenKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &tokenKey{id: id}
+	switch typed := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, typed, typed.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, typed
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, typed, typed.Public()
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, typed
+	default:
+		return nil, fmt.Errorf("unsupported key type %T; use Ed25519 or RSA", parsed)
+	}
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
+
+func (key *tokenKey) jwk() JWK {
+	jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+	switch public := key.public.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.Modulus = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	}
+	return jwk
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeTestKey(t *testing.T, dir string, id string, key any, public bool) {
+	t.Helper()
+
+	var block *pem.Block
+	if public {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatalf("marshal public key: %v", err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("marshal private key: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+}
+
+func testClaims() *Claims {
+	return &Claims{UserID: 7, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+}
+
+func TestTokenKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPublic, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	writeTestKey(t, dir, "2026-01", oldPrivate, false)
+	writeTestKey(t, dir, "2026-02", rsaKey, false)
+
+	// The key sorting last signs by default; the other one keeps verifying.
+	keySet, err := loadTokenKeySet(dir, "", "legacy-secret-value")
+	if err != nil {
+		t.Fatalf("load keys: %v", err)
+	}
+	if keySet.signing.id != "2026-02" || keySet.signing.method != jwt.SigningMethodRS256 {
+		t.Fatalf("signing key = %s %s, want 2026-02 RS256", keySet.signing.id, keySet.signing.method.Alg())
+	}
+	rsaToken, err := keySet.sign(testClaims())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	explicit, err := loadTokenKeySet(dir, "2026-01", "")
+	if err != nil {
+		t.Fatalf("load keys with signing key id: %v", err)
+	}
+	edToken, err := explicit.sign(testClaims())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("legacy-secret-value"))
+	if err != nil {
+		t.Fatalf("sign legacy token: %v", err)
+	}
+
+	for name, token := range map[string]string{"rsa": rsaToken, "ed25519": edToken, "hs256": legacyToken} {
+		claims := &Claims{}
+		if _, err := jwt.ParseWithClaims(token, claims, keySet.verificationKey); err != nil || claims.UserID != 7 {
+			t.Fatalf("%s token: claims %+v err %v", name, claims, err)
+		}
+	}
+	if _, err := jwt.ParseWithClaims(legacyToken, &Claims{}, explicit.verificationKey); err == nil {
+		t.Fatal("HS256 token accepted without JWT_SECRET")
+	}
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodHS384, jwt.SigningMethodHS512} {
+		otherHMAC, err := jwt.NewWithClaims(method, testClaims()).SignedString([]byte("legacy-secret-value"))
+		if err != nil {
+			t.Fatalf("sign %s token: %v", method.Alg(), err)
+		}
+		if _, err := jwt.ParseWithClaims(otherHMAC, &Claims{}, keySet.verificationKey); err == nil {
+			t.Fatalf("%s token accepted with the HS256 secret", method.Alg())
+		}
+	}
+
+	// Retiring a key to its public part keeps its tokens valid but it can no longer sign.
+	writeTestKey(t, dir, "2026-01", oldPublic, true)
+	if _, err := loadTokenKeySet(dir, "2026-01", ""); err == nil {
+		t.Fatal("public key accepted as signing key")
+	}
+	retired, err := loadTokenKeySet(dir, "", "")
+	if err != nil {
+		t.Fatalf("load keys: %v", err)
+	}
+	if _, err := jwt.ParseWithClaims(edToken, &Claims{}, retired.verificationKey); err != nil {
+		t.Fatalf("token of retired key rejected: %v", err)
+	}
+
+	// A token must not be verified with a key of another algorithm.
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, testClaims())
+	forged.Header["kid"] = "2026-02"
+	forgedToken, _ := forged.SignedString(oldPrivate)
+	if _, err := jwt.ParseWithClaims(forgedToken, &Claims{}, retired.verificationKey); err == nil {
+		t.Fatal("token accepted with mismatched key algorithm")
+	}
+
+	configuredTokenKeys = retired
+	t.Cleanup(func() { configuredTokenKeys = nil })
+	jwks := PublicJWKS()
+	if len(jwks) != 2 || jwks[0].KeyID != "2026-01" || jwks[0].KeyType != "OKP" || jwks[0].Algorithm != "EdDSA" ||
+		jwks[1].KeyType != "RSA" || jwks[1].Exponent != "AQAB" || jwks[1].Modulus == "" {
+		t.Fatalf("jwks = %+v", jwks)
+	}
+}
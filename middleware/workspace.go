@@ -17,8 +17,28 @@ import (
 const workspaceHeader = "X-Workspace-ID"
 
 // WorkspaceMiddleware resolves the current workspace after JWT authentication.
+// Requests authenticated with an API key keep the key's workspace.
 func WorkspaceMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if _, isAPIKey := c.Get("apiKeyID"); isAPIKey {
+			workspaceID, hasWorkspaceHeader, err := parseWorkspaceHeader(c.GetHeader(workspaceHeader))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+				c.Abort()
+				return
+			}
+			if hasWorkspaceHeader && workspaceID != c.GetUint("workspaceID") {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Workspace access denied"})
+				c.Abort()
+				return
+			}
+			if !TwoFactorRequirementMet(c, c.GetUint("userID"), c.GetUint("workspaceID")) {
+				return
+			}
+			c.Next()
+			return
+		}
+
 		userIDValue, exists := c.Get("userID")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -49,8 +69,7 @@ func WorkspaceMiddleware() gin.HandlerFunc {
 				return
 			}
 			if found {
-				setWorkspaceContext(c, member)
-				c.Next()
+				enterWorkspace(c, userID, member)
 				return
 			}
 
@@ -66,8 +85,7 @@ func WorkspaceMiddleware() gin.HandlerFunc {
 				c.Abort()
 				return
 			}
-			setWorkspaceContext(c, member)
-			c.Next()
+			enterWorkspace(c, userID, member)
 			return
 		}
 
@@ -84,9 +102,50 @@ func WorkspaceMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		setWorkspaceContext(c, member)
-		c.Next()
+		enterWorkspace(c, userID, member)
+	}
+}
+
+// enterWorkspace continues the request in the workspace of member.
+func enterWorkspace(c *gin.Context, userID uint, member models.WorkspaceMember) {
+	if !TwoFactorRequirementMet(c, userID, member.WorkspaceID) {
+		return
+	}
+	setWorkspaceContext(c, member)
+	c.Next()
+}
+
+// TwoFactorRequirementMet aborts the request when the workspace requires two-factor
+// authentication and the user has not enabled it. Handlers that resolve a workspace themselves,
+// instead of through WorkspaceMiddleware, must call it as well.
+func TwoFactorRequirementMet(c *gin.Context, userID uint, workspaceID uint) bool {
+	required, err := database.WorkspaceRequiresTwoFactor(database.DB, workspaceID)
+	if err != nil {
+		log.Printf("Failed to check two-factor requirement of workspace %d: %v", workspaceID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve workspace"})
+		c.Abort()
+		return false
+	}
+	if !required {
+		return true
+	}
+
+	enabled, err := database.UserHasTwoFactor(database.DB, userID)
+	if err != nil {
+		log.Printf("Failed to check two-factor status of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve workspace"})
+		c.Abort()
+		return false
+	}
+	if !enabled {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":  "This workspace requires two-factor authentication",
+			"reason": PermissionReasonTwoFactor,
+		})
+		c.Abort()
+		return false
 	}
+	return true
 }
 
 func parseWorkspaceHeader(value string) (uint, bool, error) {
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Account groups several workspaces under one legal business.
+type Account struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"`
+	Name       string          `json:"name" gorm:"not null" binding:"required,min=1"`
+	Workspaces []Workspace     `json:"workspaces,omitempty" gorm:"foreignKey:AccountID"`
+	Members    []AccountMember `json:"members,omitempty" gorm:"foreignKey:AccountID"`
+}
+
+// AccountMember represents account-level access of a user.
+type AccountMember struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"`
+	AccountID uint           `json:"account_id" gorm:"not null"`
+	UserID    uint           `json:"user_id" gorm:"not null"`
+	Role      string         `json:"role" gorm:"not null"`
+	Account   Account        `json:"account" gorm:"foreignKey:AccountID"`
+	User      User           `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// AccountCreateDTO represents data for creating or renaming an account.
+type AccountCreateDTO struct {
+	Name string `json:"name" binding:"required,min=1"`
+}
+
+// AccountWorkspaceDTO references a workspace to attach to an account.
+type AccountWorkspaceDTO struct {
+	WorkspaceID uint `json:"workspace_id" binding:"required"`
+}
+
+// AccountAdminDTO references a user to grant account admin access to.
+type AccountAdminDTO struct {
+	Username string `json:"username" binding:"required,min=1"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// APIKey lets an integration act in one workspace on behalf of the member who created it,
+// limited to its scopes. Only the SHA-256 hash of the key is stored; Prefix identifies it.
+type APIKey struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	WorkspaceID uint         `json:"workspace_id" gorm:"not null"`
+	UserID      uint         `json:"user_id" gorm:"not null"`
+	Name        string       `json:"name" gorm:"not null"`
+	Prefix      string       `json:"prefix" gorm:"not null"`
+	KeyHash     string       `json:"-" gorm:"not null"`
+	Scopes      APIKeyScopes `json:"scopes" gorm:"type:text"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time   `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time   `json:"revoked_at,omitempty"`
+	User        User         `json:"-" gorm:"foreignKey:UserID"`
+	Workspace   Workspace    `json:"-" gorm:"foreignKey:WorkspaceID"`
+}
+
+// APIKeyScopes lists "resource:read" and "resource:write" scopes and is stored as JSON text.
+type APIKeyScopes []string
+
+// Value implements driver.Valuer.
+func (scopes APIKeyScopes) Value() (driver.Value, error) {
+	if scopes == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(scopes)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (scopes *APIKeyScopes) Scan(value interface{}) error {
+	var data []byte
+	switch typed := value.(type) {
+	case nil:
+		*scopes = APIKeyScopes{}
+		return nil
+	case string:
+		data = []byte(typed)
+	case []byte:
+		data = typed
+	default:
+		return fmt.Errorf("unsupported API key scopes type %T", value)
+	}
+	return json.Unmarshal(data, scopes)
+}
+
+// APIKeyCreateDTO is the request body for creating an API key.
+type APIKeyCreateDTO struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyCreatedResponse returns a new API key. Key is shown only once.
+type APIKeyCreatedResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
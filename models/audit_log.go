@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditLog records a change made by a user to a workspace entity.
+type AuditLog struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time    `json:"created_at"`
+	WorkspaceID uint         `json:"workspace_id" gorm:"not null"`
+	UserID      uint         `json:"user_id" gorm:"not null"`
+	EntityType  string       `json:"entity_type" gorm:"not null"`
+	EntityID    uint         `json:"entity_id" gorm:"not null"`
+	Action      string       `json:"action" gorm:"not null"`
+	Changes     AuditChanges `json:"changes" gorm:"type:text"`
+	User        User         `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// AuditChange holds the value of a field before and after a change.
+// Before is null for created entities and After is null for deleted ones.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges maps changed field names to their values and is stored as JSON text.
+type AuditChanges map[string]AuditChange
+
+// Value implements driver.Valuer.
+func (changes AuditChanges) Value() (driver.Value, error) {
+	if changes == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (changes *AuditChanges) Scan(value interface{}) error {
+	var data []byte
+	switch typed := value.(type) {
+	case nil:
+		*changes = AuditChanges{}
+		return nil
+	case string:
+		data = []byte(typed)
+	case []byte:
+		data = typed
+	default:
+		return fmt.Errorf("unsupported audit changes type %T", value)
+	}
+	return json.Unmarshal(data, changes)
+}
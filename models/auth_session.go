@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// AuthSession groups the access and refresh tokens issued by one login.
+// Revoking the session invalidates all of them. DeviceName is derived from the
+// User-Agent at login; IPAddress and LastSeenAt follow the latest request.
+type AuthSession struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	UserID        uint           `json:"user_id" gorm:"not null"`
+	DeviceName    string         `json:"device_name"`
+	UserAgent     string         `json:"user_agent"`
+	IPAddress     string         `json:"ip_address"`
+	LastSeenAt    *time.Time     `json:"last_seen_at"`
+	RevokedAt     *time.Time     `json:"revoked_at,omitempty"`
+	RevokedReason string         `json:"revoked_reason,omitempty"`
+	Current       bool           `json:"current" gorm:"-"`
+	User          User           `json:"-" gorm:"foreignKey:UserID"`
+	RefreshTokens []RefreshToken `json:"-" gorm:"foreignKey:SessionID"`
+}
+
+// RefreshToken is a single-use token that exchanges for a new access token.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	CreatedAt time.Time  `json:"-"`
+	SessionID uint       `json:"-" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"not null"`
+	ExpiresAt time.Time  `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"`
+}
+
+// RefreshTokenDTO carries a refresh token for the refresh and logout endpoints.
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
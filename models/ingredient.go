@@ -14,6 +14,7 @@ type Ingredient struct {
 	DeletedAt                 gorm.DeletedAt             `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"`
 	Type                      string                     `json:"type" gorm:"not null" binding:"required,min=1"`
 	Name                      string                     `json:"name" gorm:"not null;unique" binding:"required,min=1"`
+	Nutrition                 Nutrition                  `json:"nutrition" gorm:"embedded;embeddedPrefix:nutrition_"` // Per 100 g, shared by every workspace and seeded outside the API
 	RecipeIngredients         []RecipeIngredient         `json:"recipe_ingredients" gorm:"foreignKey:IngredientID"`
 	Prices                    []Price                    `json:"prices" gorm:"foreignKey:IngredientID"`
 	CookingSessionIngredients []CookingSessionIngredient `json:"cooking_session_ingredients" gorm:"foreignKey:IngredientID"`
@@ -0,0 +1,73 @@
+package models
+
+// Nutrition holds nutrition data per 100 g: energy in kcal, the nutrients in grams. A nil value is
+// unknown. On a workspace ingredient every set value overrides the value of the global ingredient.
+type Nutrition struct {
+	EnergyKcal    *float64 `json:"energy_kcal" binding:"omitempty,gte=0" example:"250"`
+	Protein       *float64 `json:"protein" binding:"omitempty,gte=0,lte=100" example:"26"`
+	Fat           *float64 `json:"fat" binding:"omitempty,gte=0,lte=100" example:"15"`
+	SaturatedFat  *float64 `json:"saturated_fat" binding:"omitempty,gte=0,lte=100" example:"6"`
+	Carbohydrates *float64 `json:"carbohydrates" binding:"omitempty,gte=0,lte=100" example:"0"`
+	Sugars        *float64 `json:"sugars" binding:"omitempty,gte=0,lte=100" example:"0"`
+	Salt          *float64 `json:"salt" binding:"omitempty,gte=0,lte=100" example:"0.15"`
+}
+
+// IsEmpty reports whether no nutrition value is set.
+func (n Nutrition) IsEmpty() bool {
+	return n.EnergyKcal == nil && n.Protein == nil && n.Fat == nil && n.SaturatedFat == nil &&
+		n.Carbohydrates == nil && n.Sugars == nil && n.Salt == nil
+}
+
+// Override returns n with every value set in override replacing the value of n.
+func (n Nutrition) Override(override Nutrition) Nutrition {
+	pick := func(base, value *float64) *float64 {
+		if value != nil {
+			return value
+		}
+		return base
+	}
+	return Nutrition{
+		EnergyKcal:    pick(n.EnergyKcal, override.EnergyKcal),
+		Protein:       pick(n.Protein, override.Protein),
+		Fat:           pick(n.Fat, override.Fat),
+		SaturatedFat:  pick(n.SaturatedFat, override.SaturatedFat),
+		Carbohydrates: pick(n.Carbohydrates, override.Carbohydrates),
+		Sugars:        pick(n.Sugars, override.Sugars),
+		Salt:          pick(n.Salt, override.Salt),
+	}
+}
+
+// NutritionValues are nutrition totals for an amount of product: energy in kcal and kJ, the
+// nutrients in grams.
+type NutritionValues struct {
+	EnergyKcal    float64 `json:"energy_kcal"`
+	EnergyKJ      float64 `json:"energy_kj"`
+	Protein       float64 `json:"protein"`
+	Fat           float64 `json:"fat"`
+	SaturatedFat  float64 `json:"saturated_fat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Sugars        float64 `json:"sugars"`
+	Salt          float64 `json:"salt"`
+}
+
+// RecipeNutrition is the nutrition of a recipe. Batch sums the ingredients of one batch; drying
+// and trimming lose water, not nutrients, so Per100g spreads the batch over FinishedWeightGrams, the
+// weight after process loss. PerPiece is set when the recipe has a piece size by weight. Complete
+// is false when some lines, listed in Warnings, are missing from the totals or miss some values.
+type RecipeNutrition struct {
+	BatchWeightGrams    float64            `json:"batch_weight_g"`
+	FinishedWeightGrams *float64           `json:"finished_weight_g"`
+	Batch               NutritionValues    `json:"batch"`
+	Per100g             *NutritionValues   `json:"per_100g"`
+	PerPiece            *NutritionValues   `json:"per_piece"`
+	Complete            bool               `json:"complete"`
+	Warnings            []NutritionWarning `json:"warnings,omitempty"`
+}
+
+// NutritionWarning explains why a recipe line is missing from, or incomplete in, recipe nutrition.
+type NutritionWarning struct {
+	IngredientID uint   `json:"ingredient_id,omitempty"`
+	SubRecipeID  uint   `json:"sub_recipe_id,omitempty"`
+	Name         string `json:"name"`
+	Reason       string `json:"reason"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// OneTimeCode is a short code sent to a user to confirm an action, such as a password reset.
+// Only the SHA-256 hash of the code is stored. Email is the address the code was sent to.
+type OneTimeCode struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	CreatedAt time.Time  `json:"-"`
+	UserID    uint       `json:"-" gorm:"not null"`
+	Purpose   string     `json:"-" gorm:"not null"`
+	Email     string     `json:"-" gorm:"not null"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	ExpiresAt time.Time  `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"`
+	Attempts  int        `json:"-" gorm:"not null;default:0"`
+}
+
+// EmailUpdateDTO sets the email address of the authenticated user.
+type EmailUpdateDTO struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// EmailVerifyDTO confirms an email address with the code sent to it.
+type EmailVerifyDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// PasswordResetRequestDTO asks for a password reset code.
+type PasswordResetRequestDTO struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// PasswordResetConfirmDTO sets a new password with a password reset code.
+type PasswordResetConfirmDTO struct {
+	Email       string `json:"email" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
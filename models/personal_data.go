@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// PersonalDataExportFormatVersion is the current version of the personal data export format.
+const PersonalDataExportFormatVersion = 1
+
+// PersonalDataExport is a copy of the data stored about a user, including the
+// data of their personal workspace.
+type PersonalDataExport struct {
+	FormatVersion        int                               `json:"format_version"`
+	ExportedAt           time.Time                         `json:"exported_at"`
+	Profile              PersonalDataProfile               `json:"profile"`
+	TwoFactorEnabled     bool                              `json:"two_factor_enabled"`
+	WorkspaceMemberships []PersonalDataWorkspaceMembership `json:"workspace_memberships"`
+	AccountMemberships   []PersonalDataAccountMembership   `json:"account_memberships"`
+	Sessions             []AuthSession                     `json:"sessions"`
+	SecurityEvents       []SecurityEvent                   `json:"security_events"`
+	PersonalWorkspace    *WorkspaceBundle                  `json:"personal_workspace,omitempty"`
+}
+
+// PersonalDataProfile holds the profile fields of a user.
+type PersonalDataProfile struct {
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	Email           *string    `json:"email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// PersonalDataWorkspaceMembership is a workspace the user belongs to.
+type PersonalDataWorkspaceMembership struct {
+	WorkspaceID   uint      `json:"workspace_id"`
+	WorkspaceName string    `json:"workspace_name"`
+	WorkspaceSlug string    `json:"workspace_slug"`
+	Personal      bool      `json:"personal"`
+	Role          string    `json:"role"`
+	JoinedAt      time.Time `json:"joined_at"`
+}
+
+// PersonalDataAccountMembership is an account the user administers.
+type PersonalDataAccountMembership struct {
+	AccountID   uint      `json:"account_id"`
+	AccountName string    `json:"account_name"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// AccountDeleteDTO confirms deletion of the authenticated user's account.
+// Code is required when two-factor authentication is enabled.
+type AccountDeleteDTO struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"`
+}
+
+// AccountDeletionReport describes what happened to the workspaces and accounts of a deleted user.
+type AccountDeletionReport struct {
+	TransferredWorkspaces []AccountDeletionWorkspace `json:"transferred_workspaces"`
+	ClosedWorkspaces      []AccountDeletionWorkspace `json:"closed_workspaces"`
+	LeftWorkspaces        []AccountDeletionWorkspace `json:"left_workspaces"`
+	ClosedAccountIDs      []uint                     `json:"closed_account_ids"`
+}
+
+// AccountDeletionWorkspace names a workspace affected by an account deletion.
+// NewOwnerUserID is set for workspaces whose ownership was transferred.
+type AccountDeletionWorkspace struct {
+	WorkspaceID    uint   `json:"workspace_id"`
+	Name           string `json:"name"`
+	NewOwnerUserID *uint  `json:"new_owner_user_id,omitempty"`
+}
@@ -18,4 +18,6 @@ type ProductOption struct {
 	Product   Product        `json:"product" gorm:"foreignKey:ProductID"`
 	Recipe    Recipe         `json:"recipe" gorm:"foreignKey:RecipeID"`
 	User      User           `json:"user" gorm:"foreignKey:UserID"`
+	// Nutrition of the recipe of the option; set on product responses
+	Nutrition *RecipeNutrition `json:"nutrition,omitempty" gorm:"-"`
 }
@@ -9,6 +9,18 @@ import (
 // RecipeCreateDTO represents data for creating a new recipe (without nested User)
 type RecipeCreateDTO struct {
 	Name string `json:"name" binding:"required,min=1"`
+	RecipeYield
+}
+
+// RecipeYield describes the output of one batch of a recipe: YieldQuantity of YieldUnit before
+// ProcessLoss, the fraction lost while drying or trimming (0.6 for raw meat drying to 40% of its
+// weight). PieceSize and PieceUnit give the size of one finished piece or pack.
+type RecipeYield struct {
+	YieldQuantity string  `json:"yield_quantity"`
+	YieldUnit     string  `json:"yield_unit"`
+	ProcessLoss   float64 `json:"process_loss" binding:"gte=0,lt=1"`
+	PieceSize     string  `json:"piece_size"`
+	PieceUnit     string  `json:"piece_unit"`
 }
 
 // Recipe represents recipe model
@@ -23,7 +35,18 @@ type Recipe struct {
 	User              User               `json:"user" gorm:"foreignKey:UserID"`
 	Workspace         Workspace          `json:"workspace" gorm:"foreignKey:WorkspaceID"`
 	RecipeIngredients []RecipeIngredient `json:"recipe_ingredients" gorm:"foreignKey:RecipeID"`
+	SubRecipes        []RecipeSubRecipe  `json:"sub_recipes" gorm:"foreignKey:RecipeID"`
+	Steps             []RecipeStep       `json:"steps" gorm:"foreignKey:RecipeID"`
 	CookingSessions   []CookingSession   `json:"cooking_sessions" gorm:"foreignKey:RecipeID"`
 	ProductOptions    []ProductOption    `json:"product_options" gorm:"foreignKey:RecipeID"`
-	TotalCost         float64            `json:"total_cost" gorm:"-"` // Field not persisted to database
+	TotalCost         float64            `json:"total_cost" gorm:"-"`    // Field not persisted to database
+	CostComplete      bool               `json:"cost_complete" gorm:"-"` // Every line was costed with status ok
+
+	RecipeYield `gorm:"embedded"`
+	// Finished product computed from TotalCost and the yield; nil when the yield does not define them
+	FinishedQuantity *float64 `json:"finished_quantity" gorm:"-"` // In YieldUnit, after process loss
+	CostPerKg        *float64 `json:"cost_per_kg" gorm:"-"`
+	CostPerPiece     *float64 `json:"cost_per_piece" gorm:"-"`
+	// Nutrition of the batch and of the finished product; set on recipe responses
+	Nutrition *RecipeNutrition `json:"nutrition,omitempty" gorm:"-"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// RecipeCostHistory is the batch cost of a recipe at every date its costing could change: each date
+// a price of one of its ingredients, or of an ingredient of its sub-recipes, took effect. Truncated is
+// true when older points were left out to keep the series within its limit.
+type RecipeCostHistory struct {
+	RecipeID   uint              `json:"recipe_id"`
+	RecipeName string            `json:"recipe_name"`
+	Points     []RecipeCostPoint `json:"points"`
+	Truncated  bool              `json:"truncated"`
+}
+
+// RecipeCostPoint is the cost of one batch at the prices effective at Date.
+type RecipeCostPoint struct {
+	Date         time.Time        `json:"date"`
+	TotalCost    float64          `json:"total_cost"`
+	CostComplete bool             `json:"cost_complete"`
+	CostPerKg    *float64         `json:"cost_per_kg"`
+	CostPerPiece *float64         `json:"cost_per_piece"`
+	Lines        []RecipeCostLine `json:"lines"`
+}
+
+// RecipeCostLine is the cost of one ingredient, or one sub-recipe, summed over the lines of the
+// recipe that use it. PriceID and PriceDate identify the ingredient price used, when there is one.
+type RecipeCostLine struct {
+	IngredientID uint       `json:"ingredient_id,omitempty"`
+	SubRecipeID  uint       `json:"sub_recipe_id,omitempty"`
+	Name         string     `json:"name"`
+	Cost         float64    `json:"cost"`
+	PriceID      *uint      `json:"price_id,omitempty"`
+	PriceDate    *time.Time `json:"price_date,omitempty"`
+}
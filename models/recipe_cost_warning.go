@@ -0,0 +1,21 @@
+package models
+
+// RecipeCostWarning is a recipe whose cost leaves out, or only partly includes, some of its lines.
+type RecipeCostWarning struct {
+	RecipeID   uint                    `json:"recipe_id"`
+	RecipeName string                  `json:"recipe_name"`
+	TotalCost  float64                 `json:"total_cost"`
+	Lines      []RecipeCostWarningLine `json:"lines"`
+}
+
+// RecipeCostWarningLine is an ingredient or sub-recipe line of a recipe that was not costed with
+// status ok.
+type RecipeCostWarningLine struct {
+	IngredientID uint   `json:"ingredient_id,omitempty"`
+	SubRecipeID  uint   `json:"sub_recipe_id,omitempty"`
+	Name         string `json:"name"`
+	Quantity     string `json:"quantity"`
+	Unit         string `json:"unit"`
+	CostStatus   string `json:"cost_status"`
+	CostMessage  string `json:"cost_message"`
+}
@@ -19,4 +19,7 @@ type RecipeIngredient struct {
 	Recipe         Recipe         `json:"recipe" gorm:"foreignKey:RecipeID"`
 	Ingredient     Ingredient     `json:"ingredient" gorm:"foreignKey:IngredientID"`
 	CalculatedCost float64        `json:"calculated_cost" gorm:"-"` // Field not persisted to database
+	// Whether CalculatedCost could be worked out (ok, no_price, incompatible_units, invalid_quantity, invalid_price); set when costed
+	CostStatus  string `json:"cost_status,omitempty" gorm:"-"`
+	CostMessage string `json:"cost_message,omitempty" gorm:"-"`
 }
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RecipeUpdateDTO represents data for updating a recipe. Omitted yield fields are cleared.
+type RecipeUpdateDTO struct {
+	Name string `json:"name" binding:"required,min=1"`
+	RecipeYield
+}
+
+// RecipeIngredientUpdateDTO represents a partial update of a recipe ingredient line.
+// Omitted fields keep their value.
+type RecipeIngredientUpdateDTO struct {
+	Quantity *string `json:"quantity"`
+	Unit     *string `json:"unit"`
+}
+
+// RecipeRevision is an immutable snapshot of a recipe taken after every change.
+// Number counts the revisions of one recipe from 1.
+type RecipeRevision struct {
+	ID           uint                      `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	RecipeID     uint                      `json:"recipe_id" gorm:"not null"`
+	WorkspaceID  *uint                     `json:"workspace_id,omitempty"`
+	UserID       uint                      `json:"user_id" gorm:"not null"`
+	Number       uint                      `json:"number" gorm:"not null"`
+	Action       string                    `json:"action" gorm:"not null"`
+	RestoredFrom *uint                     `json:"restored_from,omitempty"`
+	Name         string                    `json:"name" gorm:"not null"`
+	Ingredients  RecipeRevisionIngredients `json:"ingredients" gorm:"type:text"`
+	Steps        RecipeRevisionSteps       `json:"steps" gorm:"type:text"`
+	User         User                      `json:"-" gorm:"foreignKey:UserID"`
+
+	RecipeYield `gorm:"embedded"`
+}
+
+// RecipeRevisionIngredient is an ingredient line as it was in a revision. Sub-recipe lines have a
+// SubRecipeID instead of an IngredientID and keep the sub-recipe name in IngredientName.
+type RecipeRevisionIngredient struct {
+	IngredientID   uint   `json:"ingredient_id"`
+	SubRecipeID    uint   `json:"sub_recipe_id,omitempty"`
+	IngredientName string `json:"ingredient_name"`
+	Quantity       string `json:"quantity"`
+	Unit           string `json:"unit"`
+}
+
+// RecipeRevisionIngredients holds the ingredient lines of a revision and is stored as JSON text.
+type RecipeRevisionIngredients []RecipeRevisionIngredient
+
+// Value implements driver.Valuer.
+func (ingredients RecipeRevisionIngredients) Value() (driver.Value, error) {
+	if ingredients == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(ingredients)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (ingredients *RecipeRevisionIngredients) Scan(value interface{}) error {
+	var data []byte
+	switch typed := value.(type) {
+	case nil:
+		*ingredients = RecipeRevisionIngredients{}
+		return nil
+	case string:
+		data = []byte(typed)
+	case []byte:
+		data = typed
+	default:
+		return fmt.Errorf("unsupported recipe revision ingredients type %T", value)
+	}
+	return json.Unmarshal(data, ingredients)
+}
+
+// RecipeRevisionSteps holds the method steps of a revision in order and is stored as JSON text.
+type RecipeRevisionSteps []RecipeStepDetails
+
+// Value implements driver.Valuer.
+func (steps RecipeRevisionSteps) Value() (driver.Value, error) {
+	if steps == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (steps *RecipeRevisionSteps) Scan(value interface{}) error {
+	var data []byte
+	switch typed := value.(type) {
+	case nil:
+		*steps = RecipeRevisionSteps{}
+		return nil
+	case string:
+		data = []byte(typed)
+	case []byte:
+		data = typed
+	default:
+		return fmt.Errorf("unsupported recipe revision steps type %T", value)
+	}
+	return json.Unmarshal(data, steps)
+}
+
+// RecipeRevisionDiff describes how a recipe changed between two revisions.
+// From is 0 when comparing against an empty recipe.
+type RecipeRevisionDiff struct {
+	RecipeID           uint                             `json:"recipe_id"`
+	From               uint                             `json:"from"`
+	To                 uint                             `json:"to"`
+	Name               *AuditChange                     `json:"name,omitempty"`
+	YieldChanges       AuditChanges                     `json:"yield_changes,omitempty"`
+	AddedIngredients   []RecipeRevisionIngredient       `json:"added_ingredients"`
+	RemovedIngredients []RecipeRevisionIngredient       `json:"removed_ingredients"`
+	ChangedIngredients []RecipeRevisionIngredientChange `json:"changed_ingredients"`
+	StepChanges        []RecipeRevisionStepChange       `json:"step_changes"`
+}
+
+// RecipeRevisionIngredientChange lists the changed fields of an ingredient line present in both revisions.
+type RecipeRevisionIngredientChange struct {
+	IngredientID   uint         `json:"ingredient_id"`
+	SubRecipeID    uint         `json:"sub_recipe_id,omitempty"`
+	IngredientName string       `json:"ingredient_name"`
+	Changes        AuditChanges `json:"changes"`
+}
+
+// RecipeRevisionStepChange lists the changed fields of the step at Position. Fields of an added
+// step change from null and fields of a removed step change to null.
+type RecipeRevisionStepChange struct {
+	Position int          `json:"position"`
+	Changes  AuditChanges `json:"changes"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// RecipeScaleDTO scales a recipe either by Factor or so that AnchorIngredientID adds up to
+// TargetQuantity of TargetUnit. With Save the scaled recipe is stored as a new recipe named Name.
+type RecipeScaleDTO struct {
+	Factor             *float64 `json:"factor" example:"3.5"`
+	AnchorIngredientID uint     `json:"anchor_ingredient_id" example:"3"`
+	TargetQuantity     string   `json:"target_quantity" example:"12"`
+	TargetUnit         string   `json:"target_unit" example:"kg"`
+	Save               bool     `json:"save"`
+	Name               string   `json:"name" example:"Beef jerky 12 kg"`
+}
+
+// RecipeScaleResult is a recipe scaled by Factor, costed at the latest workspace prices.
+// Recipe has an ID only when the scaled recipe was saved.
+type RecipeScaleResult struct {
+	SourceRecipeID uint    `json:"source_recipe_id"`
+	Factor         float64 `json:"factor"`
+	Saved          bool    `json:"saved"`
+	Recipe         Recipe  `json:"recipe"`
+}
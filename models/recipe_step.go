@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecipeStepDetails is the content of a method step: free text instructions, how long the step
+// takes and the process parameters to keep, such as drying temperature and humidity.
+// IngredientIDs lists the recipe ingredients used in the step.
+type RecipeStepDetails struct {
+	Instruction     string                  `json:"instruction" gorm:"type:text;not null" binding:"required,min=1"`
+	DurationMinutes *int                    `json:"duration_minutes" binding:"omitempty,gte=0" example:"720"`
+	TemperatureC    *float64                `json:"temperature_c" example:"60"`
+	HumidityPercent *float64                `json:"humidity_percent" binding:"omitempty,gte=0,lte=100" example:"20"`
+	IngredientIDs   RecipeStepIngredientIDs `json:"ingredient_ids" gorm:"type:text"`
+}
+
+// RecipeStep is a method step of a recipe. Position orders the steps of a recipe from 1.
+type RecipeStep struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"`
+	RecipeID  uint           `json:"recipe_id" gorm:"not null"`
+	Position  int            `json:"position" gorm:"not null"`
+
+	RecipeStepDetails `gorm:"embedded"`
+}
+
+// RecipeStepsUpdateDTO replaces all steps of a recipe; steps are numbered in the given order.
+type RecipeStepsUpdateDTO struct {
+	Steps []RecipeStepDetails `json:"steps" binding:"dive"`
+}
+
+// RecipeStepIngredientIDs holds the ingredients referenced by a step and is stored as JSON text.
+type RecipeStepIngredientIDs []uint
+
+// Value implements driver.Valuer.
+func (ids RecipeStepIngredientIDs) Value() (driver.Value, error) {
+	if ids == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (ids *RecipeStepIngredientIDs) Scan(value interface{}) error {
+	var data []byte
+	switch typed := value.(type) {
+	case nil:
+		*ids = RecipeStepIngredientIDs{}
+		return nil
+	case string:
+		data = []byte(typed)
+	case []byte:
+		data = typed
+	default:
+		return fmt.Errorf("unsupported recipe step ingredient ids type %T", value)
+	}
+	return json.Unmarshal(data, ids)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecipeSubRecipe is a recipe line that uses another recipe of the same workspace, such as a
+// marinade or spice blend. Quantity and Unit measure the finished output of the sub-recipe, so
+// its unit must match the dimension of the sub-recipe yield.
+type RecipeSubRecipe struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"`
+	RecipeID       uint           `json:"recipe_id" gorm:"not null"`
+	SubRecipeID    uint           `json:"sub_recipe_id" gorm:"not null"`
+	Quantity       string         `json:"quantity" gorm:"not null"`
+	Unit           string         `json:"unit"`
+	SubRecipe      *Recipe        `json:"sub_recipe,omitempty" gorm:"foreignKey:SubRecipeID"`
+	CalculatedCost float64        `json:"calculated_cost" gorm:"-"` // Field not persisted to database
+	// Whether CalculatedCost is complete (ok, no_yield, incomplete_sub_recipe, unavailable, incompatible_units, invalid_quantity); set when costed
+	CostStatus  string `json:"cost_status,omitempty" gorm:"-"`
+	CostMessage string `json:"cost_message,omitempty" gorm:"-"`
+}
+
+// RecipeSubRecipeCreateDTO represents data for adding a sub-recipe line to a recipe
+type RecipeSubRecipeCreateDTO struct {
+	SubRecipeID uint   `json:"sub_recipe_id" binding:"required"`
+	Quantity    string `json:"quantity" binding:"required"`
+	Unit        string `json:"unit"`
+}
+
+// RecipeUsage is a sub-recipe line of RecipeID that uses SubRecipeID. Depth is 1 for recipes
+// using the queried recipe directly and grows by one for every recipe in between.
+type RecipeUsage struct {
+	RecipeID      uint   `json:"recipe_id"`
+	RecipeName    string `json:"recipe_name"`
+	SubRecipeID   uint   `json:"sub_recipe_id"`
+	SubRecipeName string `json:"sub_recipe_name"`
+	Quantity      string `json:"quantity"`
+	Unit          string `json:"unit"`
+	Depth         int    `json:"depth"`
+}
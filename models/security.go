@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// LoginThrottle tracks consecutive failed logins for a username, whether or not the user exists.
+type LoginThrottle struct {
+	ID           uint       `json:"-" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"-"`
+	UpdatedAt    time.Time  `json:"-"`
+	Username     string     `json:"-" gorm:"not null;uniqueIndex"`
+	FailedCount  int        `json:"-" gorm:"not null;default:0"`
+	LastFailedAt time.Time  `json:"-" gorm:"index"`
+	LockedUntil  *time.Time `json:"-"`
+}
+
+// SecurityEvent records a security-relevant action on a user account, such as a sign-in.
+type SecurityEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `json:"-" gorm:"not null"`
+	Type      string    `json:"type" gorm:"not null"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// TwoFactor holds the TOTP secret of a user. It is pending until EnabledAt is set by a confirmed code.
+// LastUsedStep is the time step of the last accepted code, so codes cannot be replayed.
+type TwoFactor struct {
+	ID           uint       `json:"-" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"-"`
+	UpdatedAt    time.Time  `json:"-"`
+	UserID       uint       `json:"-" gorm:"not null;uniqueIndex"`
+	Secret       string     `json:"-" gorm:"not null"`
+	EnabledAt    *time.Time `json:"-"`
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the authenticator is lost.
+// Only a bcrypt hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	CreatedAt time.Time  `json:"-"`
+	UserID    uint       `json:"-" gorm:"not null"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"-"`
+}
+
+// TwoFactorCodeDTO carries a TOTP or recovery code.
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableDTO turns two-factor authentication off.
+type TwoFactorDisableDTO struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorLoginDTO completes a login that requires a second factor.
+type TwoFactorLoginDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
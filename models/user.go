@@ -5,19 +5,24 @@ import (
 	"time"
 )
 
-// User represents user model
+// User represents user model.
+// Email is optional and is used for password reset once verified.
+// TokenGeneration is embedded in access tokens; bumping it invalidates every token issued before.
 type User struct {
-	ID         uint              `json:"id" gorm:"primaryKey"`
-	CreatedAt  time.Time         `json:"created_at"`
-	UpdatedAt  time.Time         `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt    `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"` // Added swaggerignore
-	Username   string            `json:"username" gorm:"unique;not null"`
-	Password   string            `json:"-" gorm:"not null"`
-	Recipes    []Recipe          `json:"recipes" gorm:"foreignKey:UserID"`
-	Prices     []Price           `json:"prices" gorm:"foreignKey:UserID"`
-	Clients    []Client          `json:"clients" gorm:"foreignKey:UserID"`
-	Products   []Product         `json:"products" gorm:"foreignKey:UserID"`
-	Packages   []Package         `json:"packages" gorm:"foreignKey:UserID"`
-	Orders     []Order           `json:"orders" gorm:"foreignKey:UserID"`
-	Workspaces []WorkspaceMember `json:"workspaces,omitempty" gorm:"foreignKey:UserID"`
+	ID              uint              `json:"id" gorm:"primaryKey"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt    `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"` // Added swaggerignore
+	Username        string            `json:"username" gorm:"unique;not null"`
+	Password        string            `json:"-" gorm:"not null"`
+	Email           *string           `json:"email,omitempty" gorm:"uniqueIndex"`
+	EmailVerifiedAt *time.Time        `json:"email_verified_at,omitempty"`
+	TokenGeneration uint              `json:"-" gorm:"not null;default:0"`
+	Recipes         []Recipe          `json:"recipes" gorm:"foreignKey:UserID"`
+	Prices          []Price           `json:"prices" gorm:"foreignKey:UserID"`
+	Clients         []Client          `json:"clients" gorm:"foreignKey:UserID"`
+	Products        []Product         `json:"products" gorm:"foreignKey:UserID"`
+	Packages        []Package         `json:"packages" gorm:"foreignKey:UserID"`
+	Orders          []Order           `json:"orders" gorm:"foreignKey:UserID"`
+	Workspaces      []WorkspaceMember `json:"workspaces,omitempty" gorm:"foreignKey:UserID"`
 }
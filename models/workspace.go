@@ -19,3 +19,15 @@ type Workspace struct {
 	Members        []WorkspaceMember     `json:"members,omitempty" gorm:"foreignKey:WorkspaceID"`
 	Ingredients    []WorkspaceIngredient `json:"ingredients,omitempty" gorm:"foreignKey:WorkspaceID"`
 }
+
+// WorkspaceCreateDTO represents data for creating a shared workspace.
+type WorkspaceCreateDTO struct {
+	Name string `json:"name" binding:"required,min=1"`
+	Slug string `json:"slug"`
+}
+
+// WorkspaceUpdateDTO represents editable workspace fields.
+type WorkspaceUpdateDTO struct {
+	Name string  `json:"name" binding:"required,min=1"`
+	Slug *string `json:"slug"`
+}
@@ -0,0 +1,157 @@
+package models
+
+import "time"
+
+// WorkspaceBundleFormatVersion is the current version of the workspace export format.
+const WorkspaceBundleFormatVersion = 1
+
+// WorkspaceBundle is a portable JSON archive of workspace-scoped data.
+// IDs inside the bundle are only used to link rows to each other and are remapped on import.
+// Skipped lists the rows an export left out because they reference rows outside the workspace;
+// imports ignore it.
+type WorkspaceBundle struct {
+	FormatVersion int                         `json:"format_version"`
+	ExportedAt    time.Time                   `json:"exported_at"`
+	Workspace     WorkspaceBundleWorkspace    `json:"workspace"`
+	Settings      *WorkspaceBundleSettings    `json:"settings,omitempty"`
+	Ingredients   []WorkspaceBundleIngredient `json:"ingredients"`
+	Recipes       []WorkspaceBundleRecipe     `json:"recipes"`
+	Prices        []WorkspaceBundlePrice      `json:"prices"`
+	Packages      []WorkspaceBundlePackage    `json:"packages"`
+	Products      []WorkspaceBundleProduct    `json:"products"`
+	Clients       []WorkspaceBundleClient     `json:"clients"`
+	Orders        []WorkspaceBundleOrder      `json:"orders"`
+	Skipped       []WorkspaceBundleSkippedRow `json:"skipped,omitempty"`
+}
+
+// WorkspaceBundleSkippedRow is a workspace row missing from a bundle, identified by its table and ID.
+type WorkspaceBundleSkippedRow struct {
+	Table  string `json:"table"`
+	ID     uint   `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// WorkspaceBundleWorkspace describes the exported workspace.
+type WorkspaceBundleWorkspace struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// WorkspaceBundleSettings carries workspace settings.
+type WorkspaceBundleSettings struct {
+	StrictIngredients         bool    `json:"strict_ingredients"`
+	DefaultCurrency           string  `json:"default_currency"`
+	Timezone                  string  `json:"timezone"`
+	DefaultOrderStatus        string  `json:"default_order_status"`
+	UnitSystem                string  `json:"unit_system"`
+	LowMarginThresholdPercent float64 `json:"low_margin_threshold_percent"`
+}
+
+// WorkspaceBundleIngredient references a global ingredient by name together with workspace metadata.
+// InWorkspace is false for ingredients that are only referenced by recipes or prices. Nutrition is
+// only used when the import creates the global ingredient.
+type WorkspaceBundleIngredient struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	InWorkspace bool   `json:"in_workspace"`
+	Active      bool   `json:"active"`
+	Alias       string `json:"alias,omitempty"`
+	Category    string `json:"category,omitempty"`
+
+	Nutrition         *Nutrition `json:"nutrition,omitempty"`
+	NutritionOverride *Nutrition `json:"nutrition_override,omitempty"`
+}
+
+// WorkspaceBundleRecipe is an exported recipe with its ingredient and sub-recipe lines.
+type WorkspaceBundleRecipe struct {
+	ID          uint                              `json:"id"`
+	Name        string                            `json:"name"`
+	Ingredients []WorkspaceBundleRecipeIngredient `json:"ingredients"`
+	SubRecipes  []WorkspaceBundleRecipeSubRecipe  `json:"sub_recipes,omitempty"`
+	Steps       []RecipeStepDetails               `json:"steps,omitempty"`
+
+	RecipeYield
+}
+
+// WorkspaceBundleRecipeSubRecipe is an exported sub-recipe line referencing another bundle recipe.
+type WorkspaceBundleRecipeSubRecipe struct {
+	RecipeID uint   `json:"recipe_id"`
+	Quantity string `json:"quantity"`
+	Unit     string `json:"unit"`
+}
+
+// WorkspaceBundleRecipeIngredient is an exported recipe ingredient line.
+type WorkspaceBundleRecipeIngredient struct {
+	IngredientID uint   `json:"ingredient_id"`
+	Quantity     string `json:"quantity"`
+	Unit         string `json:"unit"`
+}
+
+// WorkspaceBundlePrice is an exported ingredient price.
+type WorkspaceBundlePrice struct {
+	IngredientID uint      `json:"ingredient_id"`
+	Price        float64   `json:"price"`
+	Quantity     int       `json:"quantity"`
+	Unit         string    `json:"unit"`
+	Date         time.Time `json:"date"`
+}
+
+// WorkspaceBundlePackage is an exported package.
+type WorkspaceBundlePackage struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// WorkspaceBundleProduct is an exported product with the recipes of its options.
+type WorkspaceBundleProduct struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Cost        float64 `json:"cost"`
+	Image       string  `json:"image"`
+	PackageID   uint    `json:"package_id"`
+	RecipeIDs   []uint  `json:"recipe_ids"`
+}
+
+// WorkspaceBundleClient is an exported client.
+type WorkspaceBundleClient struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Surname   string `json:"surname"`
+	Telegram  string `json:"telegram"`
+	Instagram string `json:"instagram"`
+	Phone     string `json:"phone"`
+	Address   string `json:"address"`
+	Source    string `json:"source"`
+}
+
+// WorkspaceBundleOrder is an exported order with its items.
+type WorkspaceBundleOrder struct {
+	ClientID uint                       `json:"client_id"`
+	Date     time.Time                  `json:"date"`
+	Status   string                     `json:"status"`
+	Comment  string                     `json:"comment"`
+	Items    []WorkspaceBundleOrderItem `json:"items"`
+}
+
+// WorkspaceBundleOrderItem is an exported order item.
+type WorkspaceBundleOrderItem struct {
+	ProductID uint    `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+	CostPrice float64 `json:"cost_price"`
+}
+
+// WorkspaceImportReport summarizes what an import created or would create.
+type WorkspaceImportReport struct {
+	DryRun             bool           `json:"dry_run"`
+	FormatVersion      int            `json:"format_version"`
+	Created            map[string]int `json:"created"`
+	IngredientsMatched int            `json:"ingredients_matched"`
+	IngredientsCreated int            `json:"ingredients_created"`
+	CreatedIngredients []string       `json:"created_ingredients,omitempty"`
+	SettingsApplied    bool           `json:"settings_applied"`
+	Warnings           []string       `json:"warnings,omitempty"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// WorkspaceCloneDTO represents a request to copy a recipe or product into another workspace.
+type WorkspaceCloneDTO struct {
+	TargetWorkspaceID uint `json:"target_workspace_id" binding:"required"`
+	IncludePrices     bool `json:"include_prices"`
+}
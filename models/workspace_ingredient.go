@@ -20,6 +20,9 @@ type WorkspaceIngredient struct {
 	Workspace    Workspace      `json:"workspace" gorm:"foreignKey:WorkspaceID"`
 	Ingredient   Ingredient     `json:"ingredient" gorm:"foreignKey:IngredientID"`
 	LatestPrice  *Price         `json:"latest_price,omitempty" gorm:"-"`
+
+	// Per 100 g values replacing those of the global ingredient in this workspace
+	NutritionOverride Nutrition `json:"nutrition_override" gorm:"embedded;embeddedPrefix:nutrition_"`
 }
 
 // WorkspaceIngredientCreateDTO represents data for linking an ingredient to a workspace.
@@ -32,4 +35,6 @@ type WorkspaceIngredientUpdateDTO struct {
 	Active   *bool   `json:"active"`
 	Alias    *string `json:"alias"`
 	Category *string `json:"category"`
+	// Replaces all nutrition overrides; values left out or null fall back to the global ingredient
+	NutritionOverride *Nutrition `json:"nutrition_override"`
 }
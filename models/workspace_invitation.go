@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkspaceInvitation represents a pending offer to join a workspace with a role.
+// Username invitations target an existing user; link invitations are redeemed with a one-time token.
+type WorkspaceInvitation struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggerignore:"true"`
+	WorkspaceID     uint           `json:"workspace_id" gorm:"not null"`
+	InvitedByUserID uint           `json:"invited_by_user_id" gorm:"not null"`
+	InviteeUserID   *uint          `json:"invitee_user_id,omitempty"`
+	TokenHash       *string        `json:"-"`
+	Role            string         `json:"role" gorm:"not null"`
+	Status          string         `json:"status" gorm:"not null"`
+	ExpiresAt       time.Time      `json:"expires_at" gorm:"not null"`
+	RespondedAt     *time.Time     `json:"responded_at,omitempty"`
+	Workspace       Workspace      `json:"-" gorm:"foreignKey:WorkspaceID"`
+	InvitedBy       User           `json:"-" gorm:"foreignKey:InvitedByUserID"`
+	Invitee         *User          `json:"-" gorm:"foreignKey:InviteeUserID"`
+}
+
+// WorkspaceInvitationCreateDTO represents data for inviting someone to a workspace.
+// When Username is empty a one-time link token is issued instead.
+type WorkspaceInvitationCreateDTO struct {
+	Username       string `json:"username"`
+	Role           string `json:"role" binding:"required"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+// WorkspaceInvitationTokenDTO represents a link invitation token.
+type WorkspaceInvitationTokenDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// WorkspaceMemberRoleDTO represents a workspace member role change.
+type WorkspaceMemberRoleDTO struct {
+	Role string `json:"role" binding:"required"`
+}
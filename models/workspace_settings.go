@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// WorkspaceSettings holds per-workspace business preferences.
+// Workspaces without a stored row use database.DefaultWorkspaceSettings.
+type WorkspaceSettings struct {
+	ID                        uint      `json:"-" gorm:"primaryKey"`
+	CreatedAt                 time.Time `json:"created_at"`
+	UpdatedAt                 time.Time `json:"updated_at"`
+	WorkspaceID               uint      `json:"workspace_id" gorm:"not null;uniqueIndex"`
+	StrictIngredients         bool      `json:"strict_ingredients" gorm:"not null;default:false"`
+	DefaultCurrency           string    `json:"default_currency" gorm:"not null"`
+	Timezone                  string    `json:"timezone" gorm:"not null"`
+	DefaultOrderStatus        string    `json:"default_order_status" gorm:"not null"`
+	UnitSystem                string    `json:"unit_system" gorm:"not null"`
+	LowMarginThresholdPercent float64   `json:"low_margin_threshold_percent" gorm:"not null"`
+	RequireTwoFactor          bool      `json:"require_two_factor" gorm:"not null;default:false"`
+}
+
+// WorkspaceSettingsUpdateDTO represents a partial workspace settings update.
+type WorkspaceSettingsUpdateDTO struct {
+	StrictIngredients         *bool    `json:"strict_ingredients"`
+	DefaultCurrency           *string  `json:"default_currency"`
+	Timezone                  *string  `json:"timezone"`
+	DefaultOrderStatus        *string  `json:"default_order_status"`
+	UnitSystem                *string  `json:"unit_system"`
+	LowMarginThresholdPercent *float64 `json:"low_margin_threshold_percent" binding:"omitempty,min=0,max=100"`
+	RequireTwoFactor          *bool    `json:"require_two_factor"`
+}
@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Message is a notification addressed to one recipient.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier delivers messages to users.
+type Notifier interface {
+	Send(message Message) error
+}
+
+// Default is the notifier used by the API. It logs messages until main configures it.
+var Default Notifier = NewOutboxNotifier("")
+
+// Supported MAIL_DRIVER values.
+const (
+	DriverSMTP   = "smtp"
+	DriverOutbox = "outbox"
+)
+
+// FromEnv builds a notifier from MAIL_DRIVER and its settings.
+// MAIL_DRIVER=smtp uses SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD and MAIL_FROM.
+// MAIL_DRIVER=outbox, the default, appends messages to MAIL_OUTBOX_PATH or logs them when it is empty.
+func FromEnv() (Notifier, error) {
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "", DriverOutbox:
+		return NewOutboxNotifier(os.Getenv("MAIL_OUTBOX_PATH")), nil
+	case DriverSMTP:
+		host := os.Getenv("SMTP_HOST")
+		from := os.Getenv("MAIL_FROM")
+		if host == "" || from == "" {
+			return nil, fmt.Errorf("SMTP_HOST and MAIL_FROM must be set for MAIL_DRIVER=smtp")
+		}
+		port := 587
+		if value := os.Getenv("SMTP_PORT"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("SMTP_PORT must be a positive number")
+			}
+			port = parsed
+		}
+		return &SMTPNotifier{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", driver)
+	}
+}
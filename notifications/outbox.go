@@ -0,0 +1,53 @@
+package notifications
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// OutboxNotifier keeps messages for local development instead of delivering them.
+// With a path, each message is appended to the file as a JSON line; without one it is logged.
+type OutboxNotifier struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// outboxEntry is one line of the outbox file.
+type outboxEntry struct {
+	SentAt time.Time `json:"sent_at"`
+	Message
+}
+
+// NewOutboxNotifier returns an outbox writing to path, or logging when path is empty.
+func NewOutboxNotifier(path string) *OutboxNotifier {
+	return &OutboxNotifier{path: path}
+}
+
+// Send implements Notifier.
+func (notifier *OutboxNotifier) Send(message Message) error {
+	if notifier.path == "" {
+		log.Printf("Outbox message to %s: %s\n%s", message.To, message.Subject, message.Body)
+		return nil
+	}
+
+	data, err := json.Marshal(outboxEntry{SentAt: time.Now(), Message: message})
+	if err != nil {
+		return err
+	}
+
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	file, err := os.OpenFile(notifier.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
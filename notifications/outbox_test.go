@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutboxNotifierAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	notifier := NewOutboxNotifier(path)
+
+	for _, to := range []string{"first@example.com", "second@example.com"} {
+		if err := notifier.Send(Message{To: to, Subject: "Hello", Body: "Code: 12345678"}); err != nil {
+			t.Fatalf("send to %s: %v", to, err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open outbox: %v", err)
+	}
+	defer file.Close()
+
+	var recipients []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry outboxEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		if entry.Body != "Code: 12345678" || entry.SentAt.IsZero() {
+			t.Fatalf("entry = %+v", entry)
+		}
+		recipients = append(recipients, entry.To)
+	}
+	if len(recipients) != 2 || recipients[0] != "first@example.com" || recipients[1] != "second@example.com" {
+		t.Fatalf("recipients = %v", recipients)
+	}
+}
+
+func TestFromEnvSelectsDriver(t *testing.T) {
+	t.Setenv("MAIL_DRIVER", "")
+	if notifier, err := FromEnv(); err != nil {
+		t.Fatalf("default driver: %v", err)
+	} else if _, ok := notifier.(*OutboxNotifier); !ok {
+		t.Fatalf("default notifier = %T, want outbox", notifier)
+	}
+
+	t.Setenv("MAIL_DRIVER", DriverSMTP)
+	t.Setenv("SMTP_HOST", "")
+	if _, err := FromEnv(); err == nil {
+		t.Fatal("expected error without SMTP_HOST")
+	}
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "2525")
+	t.Setenv("MAIL_FROM", "noreply@example.com")
+	notifier, err := FromEnv()
+	if err != nil {
+		t.Fatalf("smtp driver: %v", err)
+	}
+	if smtpNotifier, ok := notifier.(*SMTPNotifier); !ok || smtpNotifier.Port != 2525 {
+		t.Fatalf("notifier = %#v, want SMTP on port 2525", notifier)
+	}
+
+	t.Setenv("MAIL_DRIVER", "pigeon")
+	if _, err := FromEnv(); err == nil {
+		t.Fatal("expected error for unsupported driver")
+	}
+}
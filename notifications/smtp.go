@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SMTPNotifier sends messages as plain-text email through an SMTP server.
+type SMTPNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Send implements Notifier.
+func (notifier *SMTPNotifier) Send(message Message) error {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return fmt.Errorf("message headers must not contain line breaks")
+	}
+
+	var auth smtp.Auth
+	if notifier.Username != "" {
+		auth = smtp.PlainAuth("", notifier.Username, notifier.Password, notifier.Host)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("From: " + notifier.From + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + message.Subject + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	address := net.JoinHostPort(notifier.Host, strconv.Itoa(notifier.Port))
+	return smtp.SendMail(address, auth, notifier.From, []string{message.To}, []byte(builder.String()))
+}
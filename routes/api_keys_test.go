@@ -0,0 +1,184 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+)
+
+func TestWorkspaceAPIKeysAreScopedToWorkspaceAndScopes(t *testing.T) {
+	workspaceID, userIDs := setupPermissionTest(t)
+	if err := database.DB.AutoMigrate(&models.APIKey{}); err != nil {
+		t.Fatalf("migrate api keys: %v", err)
+	}
+	router := gin.New()
+	SetupRoutes(router)
+
+	requestNumber := 0
+	send := func(method string, path string, headers map[string]string, body string) *httptest.ResponseRecorder {
+		requestNumber++
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.RemoteAddr = fmt.Sprintf("203.0.113.%d:1234", requestNumber%250+1)
+		request.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	workspaceHeader := fmt.Sprint(workspaceID)
+	userHeaders := func(role string) map[string]string {
+		return map[string]string{
+			"Authorization":  "Bearer " + permissionTestToken(t, userIDs[role]),
+			"X-Workspace-ID": workspaceHeader,
+		}
+	}
+
+	response := send(http.MethodPost, "/api/workspaces/current/api-keys", userHeaders(constants.WorkspaceRoleOperator), `{"name":"Bot","scopes":["orders:read"]}`)
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("operator create key status = %d, want 403", response.Code)
+	}
+	response = send(http.MethodPost, "/api/workspaces/current/api-keys", userHeaders(constants.WorkspaceRoleOwner), `{"name":"Bot","scopes":["orders:delete"]}`)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("invalid scope status = %d, want 400", response.Code)
+	}
+
+	response = send(http.MethodPost, "/api/workspaces/current/api-keys", userHeaders(constants.WorkspaceRoleOwner), `{"name":"Spreadsheet","scopes":["orders:read","prices:write"]}`)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create key status = %d body = %s", response.Code, response.Body.String())
+	}
+	var created models.APIKeyCreatedResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode key: %v", err)
+	}
+	if !strings.HasPrefix(created.Key, created.Prefix) || !strings.HasPrefix(created.Prefix, constants.APIKeyPrefix) {
+		t.Fatalf("key %q does not start with prefix %q", created.Key, created.Prefix)
+	}
+	if strings.Contains(response.Body.String(), "hash") {
+		t.Fatalf("key hash leaked in response: %s", response.Body.String())
+	}
+
+	bearer := map[string]string{"Authorization": "Bearer " + created.Key}
+	if response := send(http.MethodGet, "/api/orders", bearer, ""); response.Code != http.StatusOK {
+		t.Fatalf("key read orders status = %d body = %s", response.Code, response.Body.String())
+	}
+	if response := send(http.MethodGet, "/api/orders", map[string]string{"X-API-Key": created.Key}, ""); response.Code != http.StatusOK {
+		t.Fatalf("X-API-Key read orders status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	response = send(http.MethodPost, "/api/orders", bearer, "{}")
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("key create order status = %d, want 403", response.Code)
+	}
+	var denied map[string]string
+	if err := json.Unmarshal(response.Body.Bytes(), &denied); err != nil {
+		t.Fatalf("decode denial: %v", err)
+	}
+	if denied["reason"] != middleware.PermissionReasonAPIKeyScope || denied["required"] != "orders:write" {
+		t.Fatalf("denial = %v, want missing orders:write scope", denied)
+	}
+
+	if response := send(http.MethodGet, "/api/recipes", bearer, ""); response.Code != http.StatusForbidden {
+		t.Fatalf("key read recipes status = %d, want 403", response.Code)
+	}
+	if response := send(http.MethodGet, "/api/orders", map[string]string{"Authorization": "Bearer " + created.Key, "X-Workspace-ID": "999"}, ""); response.Code != http.StatusForbidden {
+		t.Fatalf("key in other workspace status = %d, want 403", response.Code)
+	}
+	if response := send(http.MethodGet, "/api/workspaces/current/api-keys", bearer, ""); response.Code != http.StatusForbidden {
+		t.Fatalf("key listing keys status = %d, want 403", response.Code)
+	}
+	if response := send(http.MethodGet, "/api/workspaces", bearer, ""); response.Code != http.StatusUnauthorized {
+		t.Fatalf("key on user route status = %d, want 401", response.Code)
+	}
+
+	response = send(http.MethodGet, "/api/workspaces/current/api-keys", userHeaders(constants.WorkspaceRoleViewer), "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("viewer list keys status = %d body = %s", response.Code, response.Body.String())
+	}
+	var keys []models.APIKey
+	if err := json.Unmarshal(response.Body.Bytes(), &keys); err != nil || len(keys) != 1 || keys[0].LastUsedAt == nil {
+		t.Fatalf("listed keys = %s, want one used key", response.Body.String())
+	}
+
+	response = send(http.MethodDelete, fmt.Sprintf("/api/workspaces/current/api-keys/%d", created.ID), userHeaders(constants.WorkspaceRoleManager), "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("revoke key status = %d body = %s", response.Code, response.Body.String())
+	}
+	if response := send(http.MethodGet, "/api/orders", bearer, ""); response.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked key status = %d, want 401", response.Code)
+	}
+}
+
+func TestWorkspaceAPIKeysCannotCloneIntoOtherWorkspaces(t *testing.T) {
+	workspaceID, userIDs := setupPermissionTest(t)
+	if err := database.DB.AutoMigrate(&models.APIKey{}); err != nil {
+		t.Fatalf("migrate api keys: %v", err)
+	}
+	router := gin.New()
+	SetupRoutes(router)
+
+	ownerID := userIDs[constants.WorkspaceRoleOwner]
+	otherMember, err := database.CreateBusinessWorkspace(database.DB, ownerID, "Other kitchen", "")
+	if err != nil {
+		t.Fatalf("create other workspace: %v", err)
+	}
+	recipe := models.Recipe{Name: "Cloned jerky", UserID: ownerID, WorkspaceID: &workspaceID}
+	if err := database.DB.Create(&recipe).Error; err != nil {
+		t.Fatalf("create recipe: %v", err)
+	}
+
+	send := func(headers map[string]string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/recipes/%d/clone", recipe.ID), strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	ownerHeaders := map[string]string{
+		"Authorization":  "Bearer " + permissionTestToken(t, ownerID),
+		"X-Workspace-ID": fmt.Sprint(workspaceID),
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/workspaces/current/api-keys", strings.NewReader(`{"name":"Bot","scopes":["recipes:write"]}`))
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range ownerHeaders {
+		request.Header.Set(name, value)
+	}
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("create key status = %d body = %s", response.Code, response.Body.String())
+	}
+	var created models.APIKeyCreatedResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode key: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"target_workspace_id":%d}`, otherMember.WorkspaceID)
+	if response := send(map[string]string{"Authorization": "Bearer " + created.Key}, body); response.Code != http.StatusForbidden {
+		t.Fatalf("key clone status = %d body = %s, want 403", response.Code, response.Body.String())
+	}
+	var count int64
+	database.DB.Model(&models.Recipe{}).Where("workspace_id = ?", otherMember.WorkspaceID).Count(&count)
+	if count != 0 {
+		t.Fatalf("key cloned %d recipes into the other workspace", count)
+	}
+
+	if response := send(ownerHeaders, body); response.Code != http.StatusCreated {
+		t.Fatalf("owner clone status = %d body = %s", response.Code, response.Body.String())
+	}
+}
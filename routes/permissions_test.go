@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+)
+
+const permissionTestSecret = "permission-test-secret-value"
+
+type permissionRoute struct {
+	method   string
+	path     string
+	resource string
+	action   string
+}
+
+var permissionRoutes = []permissionRoute{
+	{http.MethodGet, "/api/recipes", constants.WorkspaceResourceRecipes, constants.WorkspaceActionRead},
+	{http.MethodPost, "/api/recipes", constants.WorkspaceResourceRecipes, constants.WorkspaceActionCreate},
+	{http.MethodPost, "/api/recipes/999/ingredients", constants.WorkspaceResourceRecipes, constants.WorkspaceActionUpdate},
+	{http.MethodDelete, "/api/recipes/999", constants.WorkspaceResourceRecipes, constants.WorkspaceActionDelete},
+	{http.MethodGet, "/api/prices", constants.WorkspaceResourcePrices, constants.WorkspaceActionRead},
+	{http.MethodPost, "/api/prices", constants.WorkspaceResourcePrices, constants.WorkspaceActionCreate},
+	{http.MethodGet, "/api/orders", constants.WorkspaceResourceOrders, constants.WorkspaceActionRead},
+	{http.MethodPost, "/api/orders", constants.WorkspaceResourceOrders, constants.WorkspaceActionCreate},
+	{http.MethodPut, "/api/orders/999/status", constants.WorkspaceResourceOrders, constants.WorkspaceActionUpdate},
+	{http.MethodDelete, "/api/orders/999", constants.WorkspaceResourceOrders, constants.WorkspaceActionDelete},
+	{http.MethodGet, "/api/clients", constants.WorkspaceResourceClients, constants.WorkspaceActionRead},
+	{http.MethodPost, "/api/clients", constants.WorkspaceResourceClients, constants.WorkspaceActionCreate},
+	{http.MethodPut, "/api/clients/999", constants.WorkspaceResourceClients, constants.WorkspaceActionUpdate},
+	{http.MethodDelete, "/api/clients/999", constants.WorkspaceResourceClients, constants.WorkspaceActionDelete},
+	{http.MethodGet, "/api/products", constants.WorkspaceResourceProducts, constants.WorkspaceActionRead},
+	{http.MethodPost, "/api/products", constants.WorkspaceResourceProducts, constants.WorkspaceActionCreate},
+	{http.MethodPut, "/api/products/999", constants.WorkspaceResourceProducts, constants.WorkspaceActionUpdate},
+	{http.MethodDelete, "/api/products/999", constants.WorkspaceResourceProducts, constants.WorkspaceActionDelete},
+}
+
+// expectedRoleAccess documents the intended matrix independently of constants.WorkspaceRoleAllows.
+var expectedRoleAccess = map[string]map[string][]string{
+	constants.WorkspaceRoleOwner: {
+		constants.WorkspaceResourceRecipes:  {"read", "create", "update", "delete"},
+		constants.WorkspaceResourcePrices:   {"read", "create"},
+		constants.WorkspaceResourceOrders:   {"read", "create", "update", "delete"},
+		constants.WorkspaceResourceClients:  {"read", "create", "update", "delete"},
+		constants.WorkspaceResourceProducts: {"read", "create", "update", "delete"},
+	},
+	constants.WorkspaceRoleManager: {
+		constants.WorkspaceResourceRecipes:  {"read", "create", "update", "delete"},
+		constants.WorkspaceResourcePrices:   {"read", "create"},
+		constants.WorkspaceResourceOrders:   {"read", "create", "update", "delete"},
+		constants.WorkspaceResourceClients:  {"read", "create", "update", "delete"},
+		constants.WorkspaceResourceProducts: {"read", "create", "update", "delete"},
+	},
+	constants.WorkspaceRoleOperator: {
+		constants.WorkspaceResourceRecipes:  {"read"},
+		constants.WorkspaceResourcePrices:   {"read", "create"},
+		constants.WorkspaceResourceOrders:   {"read", "create", "update"},
+		constants.WorkspaceResourceClients:  {"read", "create", "update"},
+		constants.WorkspaceResourceProducts: {"read"},
+	},
+	constants.WorkspaceRoleViewer: {
+		constants.WorkspaceResourceRecipes:  {"read"},
+		constants.WorkspaceResourcePrices:   {"read"},
+		constants.WorkspaceResourceOrders:   {"read"},
+		constants.WorkspaceResourceClients:  {"read"},
+		constants.WorkspaceResourceProducts: {"read"},
+	},
+}
+
+func setupPermissionTest(t *testing.T) (uint, map[string]uint) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", permissionTestSecret)
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.WorkspaceSettings{},
+		&models.Recipe{},
+		&models.Ingredient{},
+		&models.WorkspaceIngredient{},
+		&models.RecipeIngredient{},
+		&models.RecipeSubRecipe{},
+		&models.RecipeStep{},
+		&models.RecipeRevision{},
+		&models.Price{},
+		&models.Client{},
+		&models.Product{},
+		&models.Package{},
+		&models.ProductOption{},
+		&models.Order{},
+		&models.OrderItem{},
+	); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	database.DB = db
+
+	userIDs := make(map[string]uint)
+	var workspaceID uint
+	for _, role := range []string{
+		constants.WorkspaceRoleOwner,
+		constants.WorkspaceRoleManager,
+		constants.WorkspaceRoleOperator,
+		constants.WorkspaceRoleViewer,
+	} {
+		user := models.User{Username: "permission-" + role, Password: "hashed"}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create %s: %v", role, err)
+		}
+		userIDs[role] = user.ID
+
+		if role == constants.WorkspaceRoleOwner {
+			member, err := database.CreateBusinessWorkspace(db, user.ID, "Permission kitchen", "")
+			if err != nil {
+				t.Fatalf("create workspace: %v", err)
+			}
+			workspaceID = member.WorkspaceID
+			continue
+		}
+		member := models.WorkspaceMember{WorkspaceID: workspaceID, UserID: user.ID, Role: role}
+		if err := db.Create(&member).Error; err != nil {
+			t.Fatalf("create %s membership: %v", role, err)
+		}
+	}
+
+	return workspaceID, userIDs
+}
+
+func permissionTestToken(t *testing.T, userID uint) string {
+	t.Helper()
+
+	claims := middleware.Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(permissionTestSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestWorkspaceRolesAreEnforcedOnBusinessRoutes(t *testing.T) {
+	workspaceID, userIDs := setupPermissionTest(t)
+	router := gin.New()
+	SetupRoutes(router)
+
+	requestNumber := 0
+	for role, userID := range userIDs {
+		token := permissionTestToken(t, userID)
+		for _, route := range permissionRoutes {
+			name := fmt.Sprintf("%s %s %s", role, route.method, route.path)
+			t.Run(name, func(t *testing.T) {
+				requestNumber++
+				request := httptest.NewRequest(route.method, route.path, strings.NewReader("{}"))
+				// Spread requests over client addresses so the per-IP rate limiter does not interfere.
+				request.RemoteAddr = fmt.Sprintf("198.51.100.%d:1234", requestNumber%250+1)
+				request.Header.Set("Authorization", "Bearer "+token)
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Set("X-Workspace-ID", fmt.Sprint(workspaceID))
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, request)
+
+				wantAllowed := containsAction(expectedRoleAccess[role][route.resource], route.action)
+				if wantAllowed {
+					if recorder.Code == http.StatusForbidden {
+						t.Fatalf("status = 403 body = %s, want access", recorder.Body.String())
+					}
+					return
+				}
+
+				if recorder.Code != http.StatusForbidden {
+					t.Fatalf("status = %d body = %s, want 403", recorder.Code, recorder.Body.String())
+				}
+				var body map[string]string
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode body: %v", err)
+				}
+				wantRequired := constants.WorkspacePermission(route.resource, route.action)
+				if body["reason"] != middleware.PermissionReasonWorkspaceRole || body["required"] != wantRequired || body["role"] != role {
+					t.Fatalf("body = %v, want reason %q required %q role %q", body, middleware.PermissionReasonWorkspaceRole, wantRequired, role)
+				}
+			})
+		}
+	}
+}
+
+func containsAction(actions []string, action string) bool {
+	for _, candidate := range actions {
+		if candidate == action {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"mobile-backend-go/constants"
 	"mobile-backend-go/controllers"
 	"mobile-backend-go/middleware"
 
@@ -12,80 +13,170 @@ func SetupRoutes(router *gin.Engine) {
 	// Global rate limiting: 60 requests per minute
 	router.Use(middleware.RateLimitMiddleware(60))
 
+	// Public keys for verifying access tokens
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
 	// Authentication routes (with stricter limit)
 	authRoutes := router.Group("/api/auth")
 	authRoutes.Use(middleware.RateLimitMiddleware(10)) // 10 requests per minute for auth
 	{
 		authRoutes.POST("/register", controllers.Register)
 		authRoutes.POST("/login", controllers.Login)
+		authRoutes.POST("/refresh", controllers.RefreshToken)
+		authRoutes.POST("/logout", controllers.Logout)
+		authRoutes.POST("/password-reset/request", controllers.RequestPasswordReset)
+		authRoutes.POST("/password-reset/confirm", controllers.ConfirmPasswordReset)
+		authRoutes.POST("/2fa/verify", controllers.VerifyTwoFactorLogin)
 	}
 
-	// Protected routes group
+	// Workspace permission shorthands used by the routes below
+	allow := middleware.RequireWorkspacePermission
+	read := constants.WorkspaceActionRead
+	create := constants.WorkspaceActionCreate
+	update := constants.WorkspaceActionUpdate
+	remove := constants.WorkspaceActionDelete
+
+	// Protected routes group for user tokens only
 	protectedRoutes := router.Group("/api")
 	protectedRoutes.Use(middleware.JWTMiddleware())
 	{
 		// Workspace routes
 		protectedRoutes.GET("/workspaces", controllers.GetWorkspaces)
+		protectedRoutes.POST("/workspaces", controllers.CreateWorkspace)
+		protectedRoutes.PUT("/workspaces/:id", controllers.UpdateWorkspace)
+		protectedRoutes.DELETE("/workspaces/:id", controllers.DeleteWorkspace)
+
+		// Workspace member and invitation routes
+		protectedRoutes.GET("/workspaces/:id/members", controllers.GetWorkspaceMembers)
+		protectedRoutes.PUT("/workspaces/:id/members/:user_id", controllers.UpdateWorkspaceMember)
+		protectedRoutes.DELETE("/workspaces/:id/members/:user_id", controllers.DeleteWorkspaceMember)
+		protectedRoutes.GET("/workspaces/:id/invitations", controllers.GetWorkspaceInvitations)
+		protectedRoutes.POST("/workspaces/:id/invitations", controllers.CreateWorkspaceInvitation)
+		protectedRoutes.DELETE("/workspaces/:id/invitations/:invitation_id", controllers.RevokeWorkspaceInvitation)
+		protectedRoutes.GET("/invitations", controllers.GetMyInvitations)
+		protectedRoutes.POST("/invitations/accept", controllers.AcceptInvitationToken)
+		protectedRoutes.POST("/invitations/decline", controllers.DeclineInvitationToken)
+		protectedRoutes.POST("/invitations/:id/accept", controllers.AcceptInvitation)
+		protectedRoutes.POST("/invitations/:id/decline", controllers.DeclineInvitation)
+
+		// Account routes
+		protectedRoutes.GET("/accounts", controllers.GetAccounts)
+		protectedRoutes.POST("/accounts", controllers.CreateAccount)
+		protectedRoutes.GET("/accounts/:id", controllers.GetAccount)
+		protectedRoutes.GET("/accounts/:id/workspaces", controllers.GetAccountWorkspaces)
+		protectedRoutes.POST("/accounts/:id/workspaces", controllers.AddAccountWorkspace)
+		protectedRoutes.DELETE("/accounts/:id/workspaces/:workspace_id", controllers.DeleteAccountWorkspace)
+		protectedRoutes.GET("/accounts/:id/members", controllers.GetAccountMembers)
+		protectedRoutes.POST("/accounts/:id/admins", controllers.AddAccountAdmin)
+		protectedRoutes.DELETE("/accounts/:id/admins/:user_id", controllers.DeleteAccountAdmin)
+		protectedRoutes.GET("/accounts/:id/dashboard", controllers.GetAccountDashboard)
+
+		// Profile routes
+		protectedRoutes.POST("/profile/change-password", controllers.ChangePassword)
+		protectedRoutes.GET("/profile/security-events", controllers.GetSecurityEvents)
+		protectedRoutes.GET("/profile/export", controllers.ExportPersonalData)
+		protectedRoutes.DELETE("/profile", controllers.DeleteAccount)
+		protectedRoutes.GET("/profile/sessions", controllers.GetSessions)
+		protectedRoutes.DELETE("/profile/sessions", controllers.RevokeOtherSessions)
+		protectedRoutes.DELETE("/profile/sessions/:id", controllers.RevokeSession)
+		protectedRoutes.PUT("/profile/email", controllers.UpdateEmail)
+		protectedRoutes.POST("/profile/email/verify", controllers.VerifyEmail)
+		protectedRoutes.GET("/profile/2fa", controllers.GetTwoFactorStatus)
+		protectedRoutes.POST("/profile/2fa/enroll", controllers.EnrollTwoFactor)
+		protectedRoutes.POST("/profile/2fa/confirm", controllers.ConfirmTwoFactor)
+		protectedRoutes.POST("/profile/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+		protectedRoutes.POST("/profile/2fa/disable", controllers.DisableTwoFactor)
+	}
 
-		protectedRoutes.Use(middleware.WorkspaceMiddleware())
-		protectedRoutes.GET("/workspaces/current", controllers.GetCurrentWorkspace)
+	// Workspace-scoped routes accept user tokens and workspace API keys
+	workspaceRoutes := router.Group("/api")
+	workspaceRoutes.Use(middleware.AuthMiddleware(), middleware.WorkspaceMiddleware())
+	{
+		workspaceRoutes.GET("/workspaces/current", allow(constants.WorkspaceResourceWorkspace, read), controllers.GetCurrentWorkspace)
+		workspaceRoutes.GET("/workspaces/current/settings", allow(constants.WorkspaceResourceSettings, read), controllers.GetWorkspaceSettings)
+		workspaceRoutes.PATCH("/workspaces/current/settings", allow(constants.WorkspaceResourceSettings, update), controllers.UpdateWorkspaceSettings)
+		workspaceRoutes.GET("/workspaces/current/export", allow(constants.WorkspaceResourceData, read), controllers.ExportWorkspace)
+		workspaceRoutes.POST("/workspaces/current/import", allow(constants.WorkspaceResourceData, create), controllers.ImportWorkspace)
+		workspaceRoutes.GET("/workspaces/current/activity", allow(constants.WorkspaceResourceActivity, read), controllers.GetWorkspaceActivity)
+
+		// API key routes; keys cannot manage keys. Keys are bound to one workspace, so they cannot
+		// clone into another one either.
+		userOnly := middleware.RequireUserAuth()
+		workspaceRoutes.GET("/workspaces/current/api-keys", userOnly, allow(constants.WorkspaceResourceAPIKeys, read), controllers.GetAPIKeys)
+		workspaceRoutes.POST("/workspaces/current/api-keys", userOnly, allow(constants.WorkspaceResourceAPIKeys, create), controllers.CreateAPIKey)
+		workspaceRoutes.DELETE("/workspaces/current/api-keys/:id", userOnly, allow(constants.WorkspaceResourceAPIKeys, remove), controllers.RevokeAPIKey)
 
 		// Recipe routes
-		protectedRoutes.GET("/recipes", controllers.GetRecipes)
-		protectedRoutes.GET("/recipes/:id", controllers.GetRecipe)
-		protectedRoutes.POST("/recipes", controllers.CreateRecipe)
-		protectedRoutes.DELETE("/recipes/:id", controllers.DeleteRecipe)
+		workspaceRoutes.GET("/recipes", allow(constants.WorkspaceResourceRecipes, read), controllers.GetRecipes)
+		workspaceRoutes.GET("/recipes/cost-warnings", allow(constants.WorkspaceResourceRecipes, read), controllers.GetRecipeCostWarnings)
+		workspaceRoutes.GET("/recipes/:id", allow(constants.WorkspaceResourceRecipes, read), controllers.GetRecipe)
+		workspaceRoutes.GET("/recipes/:id/cost-history", allow(constants.WorkspaceResourceRecipes, read), controllers.GetRecipeCostHistory)
+		workspaceRoutes.POST("/recipes", allow(constants.WorkspaceResourceRecipes, create), controllers.CreateRecipe)
+		workspaceRoutes.POST("/recipes/:id/clone", userOnly, allow(constants.WorkspaceResourceRecipes, create), controllers.CloneRecipe)
+		workspaceRoutes.POST("/recipes/:id/scale", allow(constants.WorkspaceResourceRecipes, read), controllers.ScaleRecipe)
+		workspaceRoutes.PUT("/recipes/:id", allow(constants.WorkspaceResourceRecipes, update), controllers.UpdateRecipe)
+		workspaceRoutes.DELETE("/recipes/:id", allow(constants.WorkspaceResourceRecipes, remove), controllers.DeleteRecipe)
+		workspaceRoutes.GET("/recipes/:id/revisions", allow(constants.WorkspaceResourceRecipes, read), controllers.ListRecipeRevisions)
+		workspaceRoutes.GET("/recipes/:id/revisions/:revision", allow(constants.WorkspaceResourceRecipes, read), controllers.GetRecipeRevision)
+		workspaceRoutes.GET("/recipes/:id/revisions/:revision/diff", allow(constants.WorkspaceResourceRecipes, read), controllers.DiffRecipeRevisions)
+		workspaceRoutes.POST("/recipes/:id/revisions/:revision/restore", allow(constants.WorkspaceResourceRecipes, update), controllers.RestoreRecipeRevision)
 
 		// Ingredient routes
-		protectedRoutes.POST("/ingredients", controllers.CreateIngredient)
-		protectedRoutes.GET("/ingredients", controllers.GetIngredients)
-		protectedRoutes.GET("/ingredients/search", controllers.SearchIngredients)
-		protectedRoutes.GET("/ingredients/check", controllers.CheckIngredientExists)
-		protectedRoutes.GET("/workspace-ingredients", controllers.GetWorkspaceIngredients)
-		protectedRoutes.POST("/workspace-ingredients", controllers.AddWorkspaceIngredient)
-		protectedRoutes.PATCH("/workspace-ingredients/:id", controllers.UpdateWorkspaceIngredient)
-		protectedRoutes.DELETE("/workspace-ingredients/:id", controllers.DeleteWorkspaceIngredient)
+		workspaceRoutes.POST("/ingredients", allow(constants.WorkspaceResourceIngredients, create), controllers.CreateIngredient)
+		workspaceRoutes.GET("/ingredients", allow(constants.WorkspaceResourceIngredients, read), controllers.GetIngredients)
+		workspaceRoutes.GET("/ingredients/search", allow(constants.WorkspaceResourceIngredients, read), controllers.SearchIngredients)
+		workspaceRoutes.GET("/ingredients/check", allow(constants.WorkspaceResourceIngredients, read), controllers.CheckIngredientExists)
+		workspaceRoutes.GET("/workspace-ingredients", allow(constants.WorkspaceResourceWorkspaceIngredients, read), controllers.GetWorkspaceIngredients)
+		workspaceRoutes.POST("/workspace-ingredients", allow(constants.WorkspaceResourceWorkspaceIngredients, create), controllers.AddWorkspaceIngredient)
+		workspaceRoutes.PATCH("/workspace-ingredients/:id", allow(constants.WorkspaceResourceWorkspaceIngredients, update), controllers.UpdateWorkspaceIngredient)
+		workspaceRoutes.DELETE("/workspace-ingredients/:id", allow(constants.WorkspaceResourceWorkspaceIngredients, remove), controllers.DeleteWorkspaceIngredient)
 
 		// Recipe ingredient routes
-		protectedRoutes.POST("/recipes/:id/ingredients", controllers.AddIngredientToRecipe)
-		protectedRoutes.DELETE("/recipes/:id/ingredients/:ingredient_id", controllers.DeleteIngredientFromRecipe)
+		workspaceRoutes.POST("/recipes/:id/ingredients", allow(constants.WorkspaceResourceRecipes, update), controllers.AddIngredientToRecipe)
+		workspaceRoutes.PATCH("/recipes/:id/ingredients/:ingredient_id", allow(constants.WorkspaceResourceRecipes, update), controllers.UpdateRecipeIngredient)
+		workspaceRoutes.DELETE("/recipes/:id/ingredients/:ingredient_id", allow(constants.WorkspaceResourceRecipes, update), controllers.DeleteIngredientFromRecipe)
+
+		// Recipe step routes
+		workspaceRoutes.PUT("/recipes/:id/steps", allow(constants.WorkspaceResourceRecipes, update), controllers.UpdateRecipeSteps)
+
+		// Recipe sub-recipe routes
+		workspaceRoutes.POST("/recipes/:id/sub-recipes", allow(constants.WorkspaceResourceRecipes, update), controllers.AddSubRecipeToRecipe)
+		workspaceRoutes.DELETE("/recipes/:id/sub-recipes/:sub_recipe_id", allow(constants.WorkspaceResourceRecipes, update), controllers.DeleteSubRecipeFromRecipe)
+		workspaceRoutes.GET("/recipes/:id/where-used", allow(constants.WorkspaceResourceRecipes, read), controllers.GetRecipeUsages)
 
 		// Product routes
-		protectedRoutes.GET("/products", controllers.GetProducts)
-		protectedRoutes.GET("/products/:id", controllers.GetProductByID)
-		protectedRoutes.POST("/products", controllers.CreateProduct)
-		protectedRoutes.PUT("/products/:id", controllers.UpdateProduct)
-		protectedRoutes.DELETE("/products/:id", controllers.DeleteProduct)
+		workspaceRoutes.GET("/products", allow(constants.WorkspaceResourceProducts, read), controllers.GetProducts)
+		workspaceRoutes.GET("/products/:id", allow(constants.WorkspaceResourceProducts, read), controllers.GetProductByID)
+		workspaceRoutes.POST("/products", allow(constants.WorkspaceResourceProducts, create), controllers.CreateProduct)
+		workspaceRoutes.POST("/products/:id/clone", userOnly, allow(constants.WorkspaceResourceProducts, create), controllers.CloneProduct)
+		workspaceRoutes.PUT("/products/:id", allow(constants.WorkspaceResourceProducts, update), controllers.UpdateProduct)
+		workspaceRoutes.DELETE("/products/:id", allow(constants.WorkspaceResourceProducts, remove), controllers.DeleteProduct)
 
 		// Price routes
-		protectedRoutes.POST("/prices", controllers.AddPrice)
-		protectedRoutes.GET("/prices", controllers.GetPrices)
+		workspaceRoutes.POST("/prices", allow(constants.WorkspaceResourcePrices, create), controllers.AddPrice)
+		workspaceRoutes.GET("/prices", allow(constants.WorkspaceResourcePrices, read), controllers.GetPrices)
 
 		// Dashboard routes
-		protectedRoutes.GET("/dashboard", controllers.GetDashboardData)
-		protectedRoutes.GET("/dashboard/profit", controllers.GetProfitData)
+		workspaceRoutes.GET("/dashboard", allow(constants.WorkspaceResourceDashboard, read), controllers.GetDashboardData)
+		workspaceRoutes.GET("/dashboard/profit", allow(constants.WorkspaceResourceDashboard, read), controllers.GetProfitData)
 
 		// Client routes
-		protectedRoutes.GET("/clients", controllers.GetClients)
-		protectedRoutes.GET("/clients/:id", controllers.GetClient)
-		protectedRoutes.POST("/clients", controllers.AddClient)
-		protectedRoutes.PUT("/clients/:id", controllers.UpdateClient)
-		protectedRoutes.DELETE("/clients/:id", controllers.DeleteClient)
+		workspaceRoutes.GET("/clients", allow(constants.WorkspaceResourceClients, read), controllers.GetClients)
+		workspaceRoutes.GET("/clients/:id", allow(constants.WorkspaceResourceClients, read), controllers.GetClient)
+		workspaceRoutes.POST("/clients", allow(constants.WorkspaceResourceClients, create), controllers.AddClient)
+		workspaceRoutes.PUT("/clients/:id", allow(constants.WorkspaceResourceClients, update), controllers.UpdateClient)
+		workspaceRoutes.DELETE("/clients/:id", allow(constants.WorkspaceResourceClients, remove), controllers.DeleteClient)
 
 		// Order routes
-		protectedRoutes.GET("/orders", controllers.GetOrders)
-		protectedRoutes.GET("/orders/:id", controllers.GetOrder)
-		protectedRoutes.POST("/orders", controllers.AddOrder)
-		protectedRoutes.PUT("/orders/:id", controllers.UpdateOrder)
-		protectedRoutes.PUT("/orders/:id/status", controllers.UpdateOrderStatus)
-		protectedRoutes.DELETE("/orders/:id", controllers.DeleteOrder)
+		workspaceRoutes.GET("/orders", allow(constants.WorkspaceResourceOrders, read), controllers.GetOrders)
+		workspaceRoutes.GET("/orders/:id", allow(constants.WorkspaceResourceOrders, read), controllers.GetOrder)
+		workspaceRoutes.POST("/orders", allow(constants.WorkspaceResourceOrders, create), controllers.AddOrder)
+		workspaceRoutes.PUT("/orders/:id", allow(constants.WorkspaceResourceOrders, update), controllers.UpdateOrder)
+		workspaceRoutes.PUT("/orders/:id/status", allow(constants.WorkspaceResourceOrders, update), controllers.UpdateOrderStatus)
+		workspaceRoutes.DELETE("/orders/:id", allow(constants.WorkspaceResourceOrders, remove), controllers.DeleteOrder)
 
 		// Package routes
-		protectedRoutes.GET("/packages", controllers.GetPackages)
-		protectedRoutes.POST("/packages", controllers.AddPackage)
-
-		// Change password route
-		protectedRoutes.POST("/profile/change-password", controllers.ChangePassword)
-
+		workspaceRoutes.GET("/packages", allow(constants.WorkspaceResourcePackages, read), controllers.GetPackages)
+		workspaceRoutes.POST("/packages", allow(constants.WorkspaceResourcePackages, create), controllers.AddPackage)
 	}
 }
@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mobile-backend-go/constants"
+	"mobile-backend-go/database"
+	"mobile-backend-go/middleware"
+	"mobile-backend-go/models"
+)
+
+func TestWorkspaceCanRequireTwoFactor(t *testing.T) {
+	workspaceID, userIDs := setupPermissionTest(t)
+	if err := database.DB.AutoMigrate(&models.TwoFactor{}); err != nil {
+		t.Fatalf("migrate two-factor: %v", err)
+	}
+	router := gin.New()
+	SetupRoutes(router)
+
+	requestNumber := 0
+	send := func(method string, path string, role string, body string) *httptest.ResponseRecorder {
+		requestNumber++
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.RemoteAddr = fmt.Sprintf("192.0.2.%d:1234", requestNumber%250+1)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+permissionTestToken(t, userIDs[role]))
+		request.Header.Set("X-Workspace-ID", fmt.Sprint(workspaceID))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	requireTwoFactor := `{"require_two_factor":true}`
+
+	if response := send(http.MethodPatch, "/api/workspaces/current/settings", constants.WorkspaceRoleManager, requireTwoFactor); response.Code != http.StatusForbidden {
+		t.Fatalf("manager require two-factor status = %d, want 403", response.Code)
+	}
+	if response := send(http.MethodPatch, "/api/workspaces/current/settings", constants.WorkspaceRoleOwner, requireTwoFactor); response.Code != http.StatusConflict {
+		t.Fatalf("owner without two-factor status = %d, want 409", response.Code)
+	}
+
+	enabledAt := time.Now()
+	if err := database.DB.Create(&models.TwoFactor{UserID: userIDs[constants.WorkspaceRoleOwner], Secret: "JBSWY3DPEHPK3PXP", EnabledAt: &enabledAt}).Error; err != nil {
+		t.Fatalf("enable owner two-factor: %v", err)
+	}
+	if response := send(http.MethodPatch, "/api/workspaces/current/settings", constants.WorkspaceRoleOwner, requireTwoFactor); response.Code != http.StatusOK {
+		t.Fatalf("owner require two-factor status = %d body = %s", response.Code, response.Body.String())
+	}
+
+	if response := send(http.MethodGet, "/api/recipes", constants.WorkspaceRoleOwner, ""); response.Code != http.StatusOK {
+		t.Fatalf("owner with two-factor status = %d body = %s", response.Code, response.Body.String())
+	}
+	response := send(http.MethodGet, "/api/recipes", constants.WorkspaceRoleViewer, "")
+	var body map[string]string
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || response.Code != http.StatusForbidden || body["reason"] != middleware.PermissionReasonTwoFactor {
+		t.Fatalf("viewer without two-factor status = %d body = %s, want 403 %s", response.Code, response.Body.String(), middleware.PermissionReasonTwoFactor)
+	}
+}
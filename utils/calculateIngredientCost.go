@@ -7,23 +7,32 @@ import (
 	"strings"
 )
 
+var (
+	// ErrInvalidRecipeQuantity is returned for a recipe quantity that is not a non-negative number.
+	ErrInvalidRecipeQuantity = errors.New("invalid recipe quantity")
+	// ErrIncompatibleUnits is returned when the price and recipe units measure different dimensions.
+	ErrIncompatibleUnits = errors.New("incompatible units")
+	// ErrInvalidPriceQuantity is returned for a price quoted for a quantity that is not positive.
+	ErrInvalidPriceQuantity = errors.New("price quantity must be greater than zero")
+)
+
 // CalculateIngredientCost recalculates the ingredient price taking into account units of measurement
 func CalculateIngredientCost(price float64, priceQuantity int, priceUnit string, recipeQuantityStr string, recipeUnit string) (float64, error) {
 	recipeQuantity, err := strconv.ParseFloat(strings.Replace(recipeQuantityStr, ",", ".", 1), 64)
 	if err != nil {
-		return 0, errors.New("invalid recipe quantity")
+		return 0, ErrInvalidRecipeQuantity
 	}
 	if priceQuantity <= 0 {
-		return 0, errors.New("price quantity must be greater than zero")
+		return 0, ErrInvalidPriceQuantity
 	}
 	if recipeQuantity < 0 {
-		return 0, errors.New("recipe quantity cannot be negative")
+		return 0, fmt.Errorf("%w: recipe quantity cannot be negative", ErrInvalidRecipeQuantity)
 	}
 
 	priceDimension, priceFactor := normalizeIngredientUnit(priceUnit)
 	recipeDimension, recipeFactor := normalizeIngredientUnit(recipeUnit)
 	if priceDimension != recipeDimension {
-		return 0, fmt.Errorf("incompatible units: %s and %s", priceUnit, recipeUnit)
+		return 0, fmt.Errorf("%w: %s and %s", ErrIncompatibleUnits, priceUnit, recipeUnit)
 	}
 
 	basePriceQuantity := float64(priceQuantity) * priceFactor
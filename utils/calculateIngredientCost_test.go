@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"math"
 	"testing"
 )
@@ -198,3 +199,19 @@ func TestCalculateIngredientCostRejectsIncompatibleDimensions(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateIngredientCostErrorKinds(t *testing.T) {
+	if _, err := CalculateIngredientCost(10, 1, "kg", "100", "ml"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Fatalf("kg and ml error = %v, want ErrIncompatibleUnits", err)
+	}
+	for _, quantity := range []string{"abc", "-1"} {
+		if _, err := CalculateIngredientCost(10, 1, "kg", quantity, "g"); !errors.Is(err, ErrInvalidRecipeQuantity) {
+			t.Fatalf("quantity %q error = %v, want ErrInvalidRecipeQuantity", quantity, err)
+		}
+	}
+	for _, priceQuantity := range []int{0, -1} {
+		if _, err := CalculateIngredientCost(10, priceQuantity, "kg", "100", "g"); !errors.Is(err, ErrInvalidPriceQuantity) {
+			t.Fatalf("price quantity %d error = %v, want ErrInvalidPriceQuantity", priceQuantity, err)
+		}
+	}
+}
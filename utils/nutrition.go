@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// FinishedWeightGrams returns the weight in grams of the finished output of one batch. With a yield
+// it is yieldQuantity of yieldUnit less the processLoss fraction, which needs a mass yield or a yield
+// in pieces with a mass pieceSize. Without a yield it is the weight of the raw ingredients less the
+// process loss.
+func FinishedWeightGrams(rawWeightGrams float64, yieldQuantityStr string, yieldUnit string, processLoss float64, pieceSizeStr string, pieceUnit string) (float64, error) {
+	if processLoss < 0 || processLoss >= 1 {
+		return 0, errors.New("process loss must be at least 0 and less than 1")
+	}
+	if strings.TrimSpace(yieldQuantityStr) == "" {
+		if rawWeightGrams <= 0 {
+			return 0, errors.New("recipe has no ingredients by weight")
+		}
+		return rawWeightGrams * (1 - processLoss), nil
+	}
+
+	yieldQuantity, err := parseQuantity(yieldQuantityStr)
+	if err != nil || yieldQuantity <= 0 {
+		return 0, errors.New("yield quantity must be a positive number")
+	}
+	finished := yieldQuantity * (1 - processLoss)
+
+	switch yieldDimension, _ := normalizeIngredientUnit(yieldUnit); yieldDimension {
+	case "mass":
+		// finished yieldUnit in grams
+		return CalculateIngredientCost(finished, 1, "g", "1", yieldUnit)
+	case "count":
+		pieceWeight, err := PieceWeightGrams(pieceSizeStr, pieceUnit)
+		if err != nil {
+			return 0, err
+		}
+		return finished * pieceWeight, nil
+	default:
+		return 0, errors.New("finished weight needs a yield by weight or pieces of a known weight")
+	}
+}
+
+// PieceWeightGrams returns the weight in grams of one finished piece of pieceSize pieceUnit.
+func PieceWeightGrams(pieceSizeStr string, pieceUnit string) (float64, error) {
+	pieceSize, err := parseQuantity(pieceSizeStr)
+	if err != nil || pieceSize <= 0 {
+		return 0, errors.New("piece size must be a positive number")
+	}
+	if pieceDimension, _ := normalizeIngredientUnit(pieceUnit); pieceDimension != "mass" {
+		return 0, errors.New("piece size must be a mass")
+	}
+	return ConvertQuantity(pieceSizeStr, pieceUnit, "g")
+}
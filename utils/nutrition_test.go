@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFinishedWeightGrams(t *testing.T) {
+	tests := []struct {
+		name          string
+		rawWeight     float64
+		yieldQuantity string
+		yieldUnit     string
+		processLoss   float64
+		pieceSize     string
+		pieceUnit     string
+		want          float64
+		wantErr       bool
+	}{
+		{name: "mass yield", yieldQuantity: "2", yieldUnit: "kg", processLoss: 0.6, want: 800},
+		{name: "pieces of known weight", yieldQuantity: "20", yieldUnit: "pcs", processLoss: 0.5, pieceSize: "50", pieceUnit: "g", want: 500},
+		{name: "no yield uses raw weight", rawWeight: 1500, processLoss: 0.6, want: 600},
+		{name: "no yield and no raw weight", wantErr: true},
+		{name: "volume yield", yieldQuantity: "1", yieldUnit: "l", wantErr: true},
+		{name: "pieces without weight", yieldQuantity: "20", yieldUnit: "pcs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FinishedWeightGrams(tt.rawWeight, tt.yieldQuantity, tt.yieldUnit, tt.processLoss, tt.pieceSize, tt.pieceUnit)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FinishedWeightGrams() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil || math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("FinishedWeightGrams() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
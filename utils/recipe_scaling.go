@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// ScaleQuantity multiplies quantityStr of unit by factor. Masses and volumes are expressed in the
+// unit that reads best: kg and l from 1000 g or ml up, g and ml below 1 kg or 1 l. Other units
+// keep their unit.
+func ScaleQuantity(quantityStr string, unit string, factor float64) (string, string, error) {
+	quantity, err := parseQuantity(quantityStr)
+	if err != nil || quantity < 0 {
+		return "", "", errors.New("invalid recipe quantity")
+	}
+	if factor <= 0 {
+		return "", "", errors.New("scale factor must be greater than zero")
+	}
+
+	scaled := quantity * factor
+	dimension, unitFactor := normalizeIngredientUnit(unit)
+	base := scaled * unitFactor
+	switch dimension {
+	case "mass":
+		if base >= 1000 {
+			return formatScaledQuantity(base / 1000), "kg", nil
+		}
+		return formatScaledQuantity(base), "g", nil
+	case "volume":
+		if base >= 1000 {
+			return formatScaledQuantity(base / 1000), "l", nil
+		}
+		return formatScaledQuantity(base), "ml", nil
+	}
+	return formatScaledQuantity(scaled), unit, nil
+}
+
+// ConvertQuantity expresses quantityStr of unit in targetUnit. Both units must measure the same dimension.
+func ConvertQuantity(quantityStr string, unit string, targetUnit string) (float64, error) {
+	return CalculateIngredientCost(1, 1, targetUnit, quantityStr, unit)
+}
+
+// formatScaledQuantity rounds to thousandths, which is a gram in kg and a milligram in g.
+func formatScaledQuantity(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
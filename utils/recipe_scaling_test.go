@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScaleQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity string
+		unit     string
+		factor   float64
+		wantQty  string
+		wantUnit string
+		wantErr  bool
+	}{
+		{name: "grams promoted to kilograms", quantity: "400", unit: "g", factor: 3.5, wantQty: "1.4", wantUnit: "kg"},
+		{name: "millilitres promoted to litres", quantity: "250", unit: "ml", factor: 4, wantQty: "1", wantUnit: "l"},
+		{name: "kilograms demoted to grams", quantity: "1,2", unit: "kg", factor: 0.5, wantQty: "600", wantUnit: "g"},
+		{name: "grams stay grams", quantity: "15", unit: "g", factor: 2, wantQty: "30", wantUnit: "g"},
+		{name: "pieces keep their unit", quantity: "3", unit: "pcs", factor: 2.5, wantQty: "7.5", wantUnit: "pcs"},
+		{name: "custom units keep their unit", quantity: "2", unit: "pinch", factor: 3, wantQty: "6", wantUnit: "pinch"},
+		{name: "rounded to thousandths", quantity: "1", unit: "kg", factor: 1.0 / 3, wantQty: "333.333", wantUnit: "g"},
+		{name: "invalid quantity", quantity: "a lot", unit: "g", factor: 2, wantErr: true},
+		{name: "non-positive factor", quantity: "10", unit: "g", factor: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qty, unit, err := ScaleQuantity(tt.quantity, tt.unit, tt.factor)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s %s", qty, unit)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if qty != tt.wantQty || unit != tt.wantUnit {
+				t.Fatalf("expected %s %s, got %s %s", tt.wantQty, tt.wantUnit, qty, unit)
+			}
+		})
+	}
+}
+
+func TestConvertQuantity(t *testing.T) {
+	got, err := ConvertQuantity("1500", "g", "kg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(got-1.5) > 1e-9 {
+		t.Fatalf("expected 1.5 kg, got %v", got)
+	}
+
+	if _, err := ConvertQuantity("1", "l", "kg"); err == nil {
+		t.Fatal("expected error for incompatible units")
+	}
+}
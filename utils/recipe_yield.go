@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// YieldCosts is the ingredient cost of a batch spread over its finished output.
+// CostPerKg and CostPerPiece are nil when the yield does not define them.
+type YieldCosts struct {
+	FinishedQuantity float64
+	CostPerKg        *float64
+	CostPerPiece     *float64
+}
+
+// CalculateYieldCosts spreads batchCost over the finished output of a batch: yieldQuantity of
+// yieldUnit less the processLoss fraction (moisture lost while drying, trimming). Cost per kg needs
+// a mass yield or pieces with a mass pieceSize; cost per piece needs a yield in pieces or a
+// pieceSize in the dimension of the yield.
+func CalculateYieldCosts(batchCost float64, yieldQuantityStr string, yieldUnit string, processLoss float64, pieceSizeStr string, pieceUnit string) (YieldCosts, error) {
+	yieldQuantity, err := parseQuantity(yieldQuantityStr)
+	if err != nil || yieldQuantity <= 0 {
+		return YieldCosts{}, errors.New("yield quantity must be a positive number")
+	}
+	if processLoss < 0 || processLoss >= 1 {
+		return YieldCosts{}, errors.New("process loss must be at least 0 and less than 1")
+	}
+
+	costs := YieldCosts{FinishedQuantity: yieldQuantity * (1 - processLoss)}
+	// Cost of one yieldUnit of finished product
+	unitCost := batchCost / costs.FinishedQuantity
+	yieldDimension, _ := normalizeIngredientUnit(yieldUnit)
+
+	switch yieldDimension {
+	case "mass":
+		perKg, err := CalculateIngredientCost(unitCost, 1, yieldUnit, "1", "kg")
+		if err != nil {
+			return YieldCosts{}, err
+		}
+		costs.CostPerKg = &perKg
+	case "count":
+		perPiece, err := CalculateIngredientCost(unitCost, 1, yieldUnit, "1", "pcs")
+		if err != nil {
+			return YieldCosts{}, err
+		}
+		costs.CostPerPiece = &perPiece
+	}
+
+	if strings.TrimSpace(pieceSizeStr) == "" {
+		return costs, nil
+	}
+	pieceSize, err := parseQuantity(pieceSizeStr)
+	if err != nil || pieceSize <= 0 {
+		return YieldCosts{}, errors.New("piece size must be a positive number")
+	}
+	pieceDimension, _ := normalizeIngredientUnit(pieceUnit)
+
+	if yieldDimension == "count" {
+		// Pieces of a known weight also give the cost per kg
+		if pieceDimension != "mass" {
+			return YieldCosts{}, errors.New("piece size of a yield in pieces must be a mass")
+		}
+		perKg, err := CalculateIngredientCost(*costs.CostPerPiece/pieceSize, 1, pieceUnit, "1", "kg")
+		if err != nil {
+			return YieldCosts{}, err
+		}
+		costs.CostPerKg = &perKg
+		return costs, nil
+	}
+
+	perPiece, err := CalculateIngredientCost(unitCost, 1, yieldUnit, pieceSizeStr, pieceUnit)
+	if err != nil {
+		return YieldCosts{}, err
+	}
+	costs.CostPerPiece = &perPiece
+	return costs, nil
+}
+
+func parseQuantity(value string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", 1), 64)
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateYieldCosts(t *testing.T) {
+	tests := []struct {
+		name         string
+		batchCost    float64
+		yield        string
+		yieldUnit    string
+		processLoss  float64
+		pieceSize    string
+		pieceUnit    string
+		wantFinished float64
+		wantPerKg    float64
+		wantPerPiece float64
+		wantErr      bool
+	}{
+		{
+			name:         "jerky dried from raw beef",
+			batchCost:    24,
+			yield:        "3",
+			yieldUnit:    "kg",
+			processLoss:  0.6,
+			pieceSize:    "50",
+			pieceUnit:    "g",
+			wantFinished: 1.2,
+			wantPerKg:    20,
+			wantPerPiece: 1,
+		},
+		{
+			name:         "yield in grams without pieces",
+			batchCost:    10,
+			yield:        "2000",
+			yieldUnit:    "g",
+			processLoss:  0.5,
+			wantFinished: 1000,
+			wantPerKg:    10,
+			wantPerPiece: -1,
+		},
+		{
+			name:         "pieces with a mass piece size",
+			batchCost:    30,
+			yield:        "20",
+			yieldUnit:    "pcs",
+			processLoss:  0,
+			pieceSize:    "0,1",
+			pieceUnit:    "kg",
+			wantFinished: 20,
+			wantPerKg:    15,
+			wantPerPiece: 1.5,
+		},
+		{
+			name:         "volume yield has no cost per kg",
+			batchCost:    8,
+			yield:        "2",
+			yieldUnit:    "l",
+			processLoss:  0,
+			pieceSize:    "250",
+			pieceUnit:    "ml",
+			wantFinished: 2,
+			wantPerKg:    -1,
+			wantPerPiece: 1,
+		},
+		{name: "zero yield", batchCost: 10, yield: "0", yieldUnit: "kg", wantErr: true},
+		{name: "total loss", batchCost: 10, yield: "1", yieldUnit: "kg", processLoss: 1, wantErr: true},
+		{name: "incompatible piece unit", batchCost: 10, yield: "1", yieldUnit: "kg", pieceSize: "1", pieceUnit: "l", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalculateYieldCosts(tt.batchCost, tt.yield, tt.yieldUnit, tt.processLoss, tt.pieceSize, tt.pieceUnit)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got.FinishedQuantity-tt.wantFinished) > 1e-9 {
+				t.Fatalf("finished quantity = %v, want %v", got.FinishedQuantity, tt.wantFinished)
+			}
+			assertOptionalCost(t, "cost per kg", got.CostPerKg, tt.wantPerKg)
+			assertOptionalCost(t, "cost per piece", got.CostPerPiece, tt.wantPerPiece)
+		})
+	}
+}
+
+// assertOptionalCost checks an optional cost; want -1 expects none.
+func assertOptionalCost(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+	if want < 0 {
+		if got != nil {
+			t.Fatalf("%s = %v, want none", name, *got)
+		}
+		return
+	}
+	if got == nil || math.Abs(*got-want) > 1e-9 {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+)
+
+// GenerateSecureToken returns a URL-safe random token built from byteLength random bytes.
+func GenerateSecureToken(byteLength int) (string, error) {
+	buffer := make([]byte, byteLength)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 digest used to store opaque tokens at rest.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateNumericCode returns a random code of the given number of decimal digits,
+// for one-time codes users type in by hand.
+func GenerateNumericCode(digits int) (string, error) {
+	code := make([]byte, digits)
+	ten := big.NewInt(10)
+	for i := range code {
+		digit, err := rand.Int(rand.Reader, ten)
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + digit.Int64())
+	}
+	return string(code), nil
+}
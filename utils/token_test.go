@@ -0,0 +1,52 @@
+package utils
+
+import "testing"
+
+func TestGenerateSecureTokenIsRandomAndURLSafe(t *testing.T) {
+	first, err := GenerateSecureToken(32)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	second, err := GenerateSecureToken(32)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	if first == second {
+		t.Fatal("expected different tokens")
+	}
+	if len(first) != 43 {
+		t.Fatalf("token length = %d, want 43", len(first))
+	}
+	for _, r := range first {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			t.Fatalf("token contains non URL-safe rune %q", r)
+		}
+	}
+}
+
+func TestHashTokenIsDeterministic(t *testing.T) {
+	if HashToken("abc") != HashToken("abc") {
+		t.Fatal("expected equal hashes")
+	}
+	if HashToken("abc") == HashToken("abd") {
+		t.Fatal("expected different hashes")
+	}
+	if got := HashToken("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("HashToken(abc) = %s", got)
+	}
+}
+
+func TestGenerateNumericCodeHasRequestedDigits(t *testing.T) {
+	code, err := GenerateNumericCode(8)
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	if len(code) != 8 {
+		t.Fatalf("code length = %d, want 8", len(code))
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			t.Fatalf("code contains non-digit rune %q", r)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 appendix B ("12345678901234567890") in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Fatalf("TOTPCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTPAcceptsAdjacentStepsOnly(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	previous, _ := TOTPCode(secret, now.Add(-TOTPPeriod))
+	if step, ok := ValidateTOTP(secret, previous, now); !ok || step != TOTPStep(now)-1 {
+		t.Fatalf("previous code step = %d ok = %t", step, ok)
+	}
+	stale, _ := TOTPCode(secret, now.Add(-3*TOTPPeriod))
+	if _, ok := ValidateTOTP(secret, stale, now); ok {
+		t.Fatal("expected stale code to be rejected")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now); ok {
+		t.Fatal("expected short code to be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("BatchVault", "baker", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/BatchVault:baker?") || !strings.Contains(uri, "secret=ABC") || !strings.Contains(uri, "issuer=BatchVault") {
+		t.Fatalf("uri = %s", uri)
+	}
+}
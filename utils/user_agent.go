@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// userAgentBrowsers is checked in order, because most browsers also name the engines they imitate.
+var userAgentBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+var userAgentSystems = []struct{ token, name string }{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"iOS", "iOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// userAgentProduct matches the leading product of a user agent such as "BatchVault/2.3".
+var userAgentProduct = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9._-]*)/`)
+
+// DeviceNameFromUserAgent returns a short human-readable device name such as
+// "Chrome on Windows" or "BatchVault on iOS" for a User-Agent header.
+func DeviceNameFromUserAgent(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	client := ""
+	if strings.HasPrefix(userAgent, "Mozilla/") {
+		for _, browser := range userAgentBrowsers {
+			if strings.Contains(userAgent, browser.token) {
+				client = browser.name
+				break
+			}
+		}
+	} else if match := userAgentProduct.FindStringSubmatch(userAgent); match != nil {
+		client = match[1]
+	}
+
+	system := ""
+	for _, candidate := range userAgentSystems {
+		if strings.Contains(userAgent, candidate.token) {
+			system = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case client != "" && system != "":
+		return client + " on " + system
+	case client != "":
+		return client
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}
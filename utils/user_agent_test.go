@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestDeviceNameFromUserAgent(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36":               "Chrome on Windows",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36 Edg/126.0":     "Edge on Windows",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15":        "Safari on macOS",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/126.0 Mobile/15E148": "Chrome on iPhone",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0":                                                    "Firefox on Linux",
+		"JerkyVault/2.3 (iOS 19.1)":       "JerkyVault on iOS",
+		"okhttp/4.12.0":                   "okhttp",
+		"":                                "Unknown device",
+		"Mozilla/5.0 (compatible; Robot)": "Unknown device",
+	}
+
+	for userAgent, want := range tests {
+		if got := DeviceNameFromUserAgent(userAgent); got != want {
+			t.Errorf("DeviceNameFromUserAgent(%q) = %q, want %q", userAgent, got, want)
+		}
+	}
+}